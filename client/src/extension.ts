@@ -49,10 +49,19 @@ export async function activate(ctx: ExtensionContext) {
         language: "verilog",
         scheme: "file",
       },
+      {
+        language: "systemverilog",
+        scheme: "file",
+      },
     ],
     synchronize: {
       // Notify the server about file changes to '.clientrc files contained in the workspace
       fileEvents: workspace.createFileSystemWatcher("**/.clientrc"),
+      // and about changes to the settings under vls
+      configurationSection: "vls",
+    },
+    initializationOptions: {
+      warnings: workspace.getConfiguration("vls").get("warnings"),
     },
   };
 
@@ -0,0 +1,139 @@
+package lang
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"go.lsp.dev/protocol"
+	"go.uber.org/zap"
+)
+
+// diagnose interprets the file like the server does, knowing only
+// about the modules, interfaces, and packages that the file declares
+func diagnose(fname string, ast FileNode) []protocol.Diagnostic {
+	modules := map[string][]ModuleNode{}
+	interfaces := map[string][]InterfaceNode{}
+	packages := map[string][]PackageNode{}
+	defines := map[string][]DefineNode{}
+	for _, statement := range ast.Statements {
+		if statement.Module != nil {
+			modules[fname] = append(modules[fname], *statement.Module)
+		} else if statement.Interface != nil {
+			interfaces[fname] = append(interfaces[fname], *statement.Interface)
+		} else if statement.Package != nil {
+			packages[fname] = append(packages[fname], *statement.Package)
+		} else if statement.Directive != nil && statement.Directive.DefineNode != nil {
+			defines[fname] = append(defines[fname], *statement.Directive.DefineNode)
+		}
+	}
+	// like the server, the interpreter knows about the file's own defines
+	interpreter := NewInterpreter(zap.NewNop(), modules, interfaces, packages, defines)
+	interpreter.File = fname
+	interpreter.FileURI = func(fname string) protocol.DocumentURI {
+		return protocol.DocumentURI(filepath.ToSlash(fname))
+	}
+	return interpreter.Interpret(ast)
+}
+
+// formatDiagnostics writes a diagnostic per line, ie 3:8-3:9 Warning: Unknown variable: x,
+// followed by an indented line for each of its related locations
+func formatDiagnostics(diagnostics []protocol.Diagnostic) []byte {
+	buf := &bytes.Buffer{}
+	for _, diagnostic := range diagnostics {
+		start, end := diagnostic.Range.Start, diagnostic.Range.End
+		fmt.Fprintf(buf, "%d:%d-%d:%d %v: %s\n", start.Line, start.Character, end.Line, end.Character, diagnostic.Severity, diagnostic.Message)
+		for _, related := range diagnostic.RelatedInformation {
+			start, end := related.Location.Range.Start, related.Location.Range.End
+			fmt.Fprintf(buf, "    %s %d:%d-%d:%d: %s\n", related.Location.URI, start.Line, start.Character, end.Line, end.Character, related.Message)
+		}
+	}
+	return buf.Bytes()
+}
+
+// fields returns every field of the ast, as Type.Field, that a file could set
+func fields(t reflect.Type, result map[string]bool) {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice:
+		fields(t.Elem(), result)
+	case reflect.Struct:
+		if t == tokenType || t == spanType {
+			return
+		}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name := t.Name() + "." + field.Name
+			if result[name] {
+				// the type was already seen
+				return
+			}
+			result[name] = true
+			fields(field.Type, result)
+		}
+	}
+}
+
+// cover records every field that's set in the value, as Type.Field
+func cover(v reflect.Value, covered map[string]bool) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			cover(v.Elem(), covered)
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			cover(v.Index(i), covered)
+		}
+	case reflect.Struct:
+		if v.Type() == tokenType || v.Type() == spanType {
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if !omitted(v.Field(i)) {
+				covered[v.Type().Name()+"."+v.Type().Field(i).Name] = true
+				cover(v.Field(i), covered)
+			}
+		}
+	}
+}
+
+// TestConformance checks the ast and diagnostics of every file in testdata/conformance
+// against the golden files next to it, which -update rewrites
+func TestConformance(t *testing.T) {
+	inputs, err := filepath.Glob(filepath.Join("testdata", "conformance", "*.*v"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	covered := map[string]bool{}
+	for _, input := range inputs {
+		t.Run(filepath.Base(input), func(t *testing.T) {
+			_, ast := lexAndParse(t, input)
+			cover(reflect.ValueOf(ast), covered)
+
+			encoded, err := MarshalAST(ast)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, input+".ast.json", indent(t, encoded))
+			checkGolden(t, input+".diagnostics", formatDiagnostics(diagnose(input, ast)))
+		})
+	}
+
+	// the corpus covers the grammar when every part of the ast shows up in it
+	all := map[string]bool{}
+	fields(reflect.TypeOf(FileNode{}), all)
+	missing := []string{}
+	for field := range all {
+		if !covered[field] {
+			missing = append(missing, field)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		t.Errorf("no file in testdata/conformance sets %s", strings.Join(missing, ", "))
+	}
+}
@@ -0,0 +1,75 @@
+//go:build ignore
+
+// gen_reserved generates reserved.go from reserved_words.txt
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/format"
+	"log"
+	"os"
+	"strings"
+)
+
+func main() {
+	f, err := os.Open("reserved_words.txt")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+
+	// the section says which language reserves the words under it
+	languages := map[string]string{
+		"[IEEE 1364-2005]": "Verilog",
+		"[IEEE 1800-2017]": "SystemVerilog",
+	}
+	language := ""
+	words := []string{}
+	reservedIn := map[string]string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			var ok bool
+			language, ok = languages[line]
+			if !ok {
+				log.Fatalf("unknown section %s", line)
+			}
+			continue
+		}
+		if language == "" {
+			log.Fatalf("%s isn't in a section", line)
+		}
+		if _, ok := reservedIn[line]; ok {
+			log.Fatalf("%s is reserved twice", line)
+		}
+		words = append(words, line)
+		reservedIn[line] = language
+	}
+	if err := scanner.Err(); err != nil {
+		log.Fatal(err)
+	}
+
+	out := bytes.Buffer{}
+	out.WriteString("// Code generated by gen_reserved.go from reserved_words.txt; DO NOT EDIT.\n\n")
+	out.WriteString("package lang\n\n")
+	out.WriteString("// reservedWords maps each reserved word to the first language that reserves it\n")
+	out.WriteString("var reservedWords = map[string]Language{\n")
+	for _, word := range words {
+		fmt.Fprintf(&out, "\t%q: %s,\n", word, reservedIn[word])
+	}
+	out.WriteString("}\n")
+
+	source, err := format.Source(out.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile("reserved.go", source, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,224 @@
+package lang
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Edit is a change to some code, where the bytes from Start up to OldEnd
+// of the old code were replaced by the bytes from Start up to NewEnd of the new code
+type Edit struct {
+	Start  int
+	OldEnd int
+	NewEnd int
+}
+
+// Diff returns the edit that turns old into new,
+// which is everything between their common prefix and suffix
+func Diff(old string, new string) Edit {
+	start := 0
+	for start < len(old) && start < len(new) && old[start] == new[start] {
+		start++
+	}
+	oldEnd, newEnd := len(old), len(new)
+	for oldEnd > start && newEnd > start && old[oldEnd-1] == new[newEnd-1] {
+		oldEnd--
+		newEnd--
+	}
+	return Edit{Start: start, OldEnd: oldEnd, NewEnd: newEnd}
+}
+
+// Reparse lexes and parses code, which is the code that tokens and ast came from
+// with the edit applied. Only the top level statements that the edit touches are
+// lexed and parsed again, and the rest are reused.
+//
+// Reparse consumes ast: reused statements are moved to their new positions in place rather
+// than copied, so that they keep their Module, Interface, and Package pointers and callers
+// can tell which statements are new. So ast can't be used afterwards, unless there's an
+// error, in which case it's left as it was. Errors are the same as ParseFile's, and the
+// parser's FarthestErrorPosition is an index into the returned tokens
+func Reparse(lexer *VLexer, parser *Parser, code string, edit Edit, tokens []Token, ast FileNode) ([]Token, FileNode, error) {
+	if edit.Start == edit.OldEnd && edit.OldEnd == edit.NewEnd {
+		// nothing changed
+		return tokens, ast, nil
+	}
+	statements := ast.Statements
+	delta := edit.NewEnd - edit.OldEnd
+	oldLength := len(code) - delta
+
+	// each statement owns the code from the end of the statement before it to the start
+	// of the statement after it, so an edit to the whitespace or comments between two
+	// statements touches both of them, since either could have those comments
+	ownedStart := func(k int) int {
+		if k == 0 {
+			return 0
+		}
+		return statements[k-1].Span.EndOffset
+	}
+	ownedEnd := func(k int) int {
+		if k == len(statements)-1 {
+			return oldLength
+		}
+		return statements[k+1].Span.StartOffset
+	}
+	first := sort.Search(len(statements), func(k int) bool { return ownedEnd(k) >= edit.Start })
+	last := sort.Search(len(statements), func(k int) bool { return ownedStart(k) > edit.OldEnd }) - 1
+	if first > last {
+		return parseAll(lexer, parser, code)
+	}
+
+	// lex the code of the touched statements
+	start, end := ownedStart(first), ownedEnd(last)
+	line := strings.Count(code[:start], "\n")
+	character := UTF16Len(code[strings.LastIndexByte(code[:start], '\n')+1 : start])
+	region, endLine, endCharacter, ok := lexer.lexRange(code, start, end+delta, line, character)
+	if !ok {
+		// a token runs into the untouched statements, ie the edit started a block comment
+		return parseAll(lexer, parser, code)
+	}
+
+	// the tokens after the touched statements move by however much the edit changed their position
+	startIndex := sort.Search(len(tokens), func(i int) bool { return tokens[i].Offset() >= start })
+	endIndex := sort.Search(len(tokens), func(i int) bool { return tokens[i].Offset() >= end })
+	s := shift{bytes: delta}
+	if endIndex < len(tokens) {
+		s.line = tokens[endIndex].Line()
+		s.lines = endLine - tokens[endIndex].Line()
+		s.characters = endCharacter - tokens[endIndex].StartCharacter()
+	}
+	newTokens := make([]Token, 0, startIndex+len(region)+len(tokens)-endIndex)
+	newTokens = append(newTokens, tokens[:startIndex]...)
+	newTokens = append(newTokens, region...)
+	for _, token := range tokens[endIndex:] {
+		s.token(&token)
+		newTokens = append(newTokens, token)
+	}
+
+	// the touched statements have to parse up until the untouched ones
+	reparsed, err := parser.parseStatements(newTokens[:startIndex+len(region)], startIndex)
+	if err != nil {
+		return newTokens, FileNode{}, err
+	}
+	seen := map[visit]bool{}
+	for k := last + 1; k < len(statements); k++ {
+		s.node(reflect.ValueOf(&statements[k]).Elem(), seen)
+	}
+
+	// the comments above the first untouched statement can be in the code that was edited,
+	// ie when the edit comments out the statement before it, so they're found again
+	if last+1 < len(statements) {
+		next := &statements[last+1]
+		index := sort.Search(len(newTokens), func(i int) bool { return newTokens[i].Offset() >= next.Span.StartOffset })
+		setLeading(reflect.ValueOf(next).Elem(), next.Span.StartOffset, parser.leadingComments(newTokens, index))
+	}
+
+	result := FileNode{Statements: make([]TopLevelStatement, 0, first+len(reparsed)+len(statements)-last-1)}
+	result.Statements = append(result.Statements, statements[:first]...)
+	result.Statements = append(result.Statements, reparsed...)
+	result.Statements = append(result.Statements, statements[last+1:]...)
+	result.NettypeRegions = nettypeRegions(result.Statements)
+	result.Span = parser.span(newTokens, 0, len(newTokens))
+	return newTokens, result, nil
+}
+
+// parseAll lexes and parses all of the code
+func parseAll(lexer *VLexer, parser *Parser, code string) ([]Token, FileNode, error) {
+	tokens, err := lexer.Lex(code)
+	if err != nil {
+		return nil, FileNode{}, err
+	}
+	ast, err := parser.ParseFile(tokens)
+	return tokens, ast, err
+}
+
+// shift is how the positions after an edit move
+type shift struct {
+	line       int // the line after the edit, which is the only one whose characters move
+	lines      int
+	characters int
+	bytes      int
+}
+
+func (s shift) token(token *Token) {
+	if token.Line() == s.line {
+		token.startCharacter += int32(s.characters)
+		token.endCharacter += int32(s.characters)
+	}
+	token.line += int32(s.lines)
+	token.offset += int32(s.bytes)
+}
+
+var spanType = reflect.TypeOf(Span{})
+var triviaType = reflect.TypeOf(Trivia{})
+
+// setLeading sets the leading comments of the nodes inside of the node that start at the offset,
+// which are the ones whose comments are above the statement, ie the module of a statement
+func setLeading(v reflect.Value, offset int, leading []Token) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			setLeading(v.Elem(), offset, leading)
+		}
+	case reflect.Struct:
+		span := v.FieldByName("Span")
+		if !span.IsValid() || span.Type() != spanType || span.Interface().(Span).StartOffset != offset {
+			return
+		}
+		if comments := v.FieldByName("Comments"); comments.IsValid() && comments.Type() == triviaType {
+			comments.FieldByName("Leading").Set(reflect.ValueOf(leading))
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if v.Field(i).Kind() == reflect.Ptr {
+				setLeading(v.Field(i), offset, leading)
+			}
+		}
+	}
+}
+
+// visit is something that has already been moved,
+// since nodes can share pointers and have to be moved once
+type visit struct {
+	address uintptr
+	t       reflect.Type
+}
+
+// node moves every token and span inside of the node
+func (s shift) node(v reflect.Value, seen map[visit]bool) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			s.node(v.Elem(), seen)
+		}
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			s.node(v.Index(i), seen)
+		}
+	case reflect.Struct:
+		key := visit{address: v.Addr().Pointer(), t: v.Type()}
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		// tokens and spans that were never set stay unset
+		switch v.Type() {
+		case tokenType:
+			if token := v.Addr().Interface().(*Token); *token != (Token{}) {
+				s.token(token)
+			}
+		case spanType:
+			span := v.Addr().Interface().(*Span)
+			if *span == (Span{}) {
+				return
+			}
+			s.token(&span.Start)
+			s.token(&span.End)
+			span.StartOffset += s.bytes
+			span.EndOffset += s.bytes
+		default:
+			for i := 0; i < v.NumField(); i++ {
+				s.node(v.Field(i), seen)
+			}
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package lang
+
+import (
+	"bytes"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		old      string
+		new      string
+		expected Edit
+	}{
+		{"module a;", "module ab;", Edit{Start: 8, OldEnd: 8, NewEnd: 9}},
+		{"module ab;", "module a;", Edit{Start: 8, OldEnd: 9, NewEnd: 8}},
+		{"wire a;", "wire b;", Edit{Start: 5, OldEnd: 6, NewEnd: 6}},
+		{"same", "same", Edit{Start: 4, OldEnd: 4, NewEnd: 4}},
+		{"aa", "aaa", Edit{Start: 2, OldEnd: 2, NewEnd: 3}},
+	}
+	for _, test := range tests {
+		if edit := Diff(test.old, test.new); edit != test.expected {
+			t.Errorf("diff of %q and %q is %+v, expected %+v", test.old, test.new, edit, test.expected)
+		}
+	}
+}
+
+// reparse applies the edit to the code and reparses it from a full parse of the code
+func reparse(t *testing.T, code string, language Language, edit Edit, replacement string) (string, []Token, FileNode, error) {
+	t.Helper()
+	lexer, parser := newVLexer(zap.NewNop(), language), newParser(language)
+	tokens, err := lexer.Lex(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ast, err := parser.ParseFile(tokens)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edited := code[:edit.Start] + replacement + code[edit.OldEnd:]
+	tokens, ast, err = Reparse(lexer, newParser(language), edited, edit, tokens, ast)
+	return edited, tokens, ast, err
+}
+
+func TestReparse(t *testing.T) {
+	replacements := []string{"\n", " ", "x", "/* c */", "\nmodule m; endmodule\n", ""}
+	for _, input := range corpus(t) {
+		t.Run(filepath.Base(input), func(t *testing.T) {
+			language := Verilog
+			if strings.HasSuffix(input, ".sv") {
+				language = SystemVerilog
+			}
+			code, err := os.ReadFile(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for offset := 0; offset < len(code); offset += 41 {
+				for _, replacement := range replacements {
+					edit := Edit{Start: offset, OldEnd: offset, NewEnd: offset + len(replacement)}
+					if replacement == "" {
+						// delete a character instead
+						edit.OldEnd++
+					}
+					edited, tokens, ast, err := reparse(t, string(code), language, edit, replacement)
+					checkReparse(t, edited, language, tokens, ast, err)
+				}
+			}
+		})
+	}
+}
+
+// checkReparse checks that a reparse gave exactly what parsing all of the edited code gives
+func checkReparse(t *testing.T, edited string, language Language, tokens []Token, ast FileNode, err error) {
+	t.Helper()
+	expectedTokens, expectedAST, expectedErr := parseAll(newVLexer(zap.NewNop(), language), newParser(language), edited)
+	if (err == nil) != (expectedErr == nil) {
+		t.Fatalf("reparse gave error %v, expected %v, for:\n%s", err, expectedErr, edited)
+	}
+	if len(tokens) != len(expectedTokens) {
+		t.Fatalf("reparse gave %d tokens, expected %d, for:\n%s", len(tokens), len(expectedTokens), edited)
+	}
+	for i := range tokens {
+		if tokens[i] != expectedTokens[i] {
+			t.Fatalf("token %d is %+v, expected %+v, for:\n%s", i, tokens[i], expectedTokens[i], edited)
+		}
+	}
+	if err != nil {
+		return
+	}
+	encoded, err := MarshalAST(ast)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := MarshalAST(expectedAST)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(encoded, expected) {
+		t.Fatalf("reparse gave a different ast for:\n%s", edited)
+	}
+}
+
+func TestReparseRanges(t *testing.T) {
+	// replacements that change comments, statements, or both, along with pieces of the code itself
+	replacements := []string{"", "// ", "/* ", " */", "\n// note\n", "endmodule\n", "\nmodule m; endmodule\n", "`define X 1\n"}
+	random := rand.New(rand.NewSource(1))
+	for _, input := range corpus(t) {
+		t.Run(filepath.Base(input), func(t *testing.T) {
+			language := Verilog
+			if strings.HasSuffix(input, ".sv") {
+				language = SystemVerilog
+			}
+			code, err := os.ReadFile(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < 200; i++ {
+				start := random.Intn(len(code))
+				end := start + random.Intn(40)
+				if end > len(code) {
+					end = len(code)
+				}
+				replacement := replacements[random.Intn(len(replacements))]
+				if random.Intn(4) == 0 {
+					from := random.Intn(len(code))
+					to := from + random.Intn(40)
+					if to > len(code) {
+						to = len(code)
+					}
+					replacement = string(code[from:to])
+				}
+				edit := Edit{Start: start, OldEnd: end, NewEnd: start + len(replacement)}
+				edited, tokens, ast, err := reparse(t, string(code), language, edit, replacement)
+				checkReparse(t, edited, language, tokens, ast, err)
+			}
+		})
+	}
+}
+
+func TestReparseReusesStatements(t *testing.T) {
+	code := "module a;\n  wire x;\nendmodule\n\nmodule b;\n  wire y;\nendmodule\n\nmodule c;\n  wire z;\nendmodule\n"
+	lexer, parser := NewVLexer(zap.NewNop()), NewParser()
+	tokens, err := lexer.Lex(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ast, err := parser.ParseFile(tokens)
+	if err != nil {
+		t.Fatal(err)
+	}
+	modules := []*ModuleNode{ast.Statements[0].Module, ast.Statements[1].Module, ast.Statements[2].Module}
+
+	// rename y to yy, which only touches b
+	offset := strings.Index(code, "y;")
+	edited := code[:offset] + "y" + code[offset:]
+	_, ast, err = Reparse(lexer, NewParser(), edited, Edit{Start: offset, OldEnd: offset, NewEnd: offset + 1}, tokens, ast)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ast.Statements[0].Module != modules[0] || ast.Statements[2].Module != modules[2] {
+		t.Errorf("untouched modules weren't reused")
+	}
+	if ast.Statements[1].Module == modules[1] {
+		t.Errorf("touched module was reused")
+	}
+	if z := ast.Statements[2].Module.Interior[0].DeclarationNode.Variables[0].Identifier; z.Offset() != strings.Index(edited, "z;") || z.Line() != 9 {
+		t.Errorf("untouched module wasn't moved, z is at offset %d on line %d", z.Offset(), z.Line())
+	}
+}
+
+func TestReparseComments(t *testing.T) {
+	// commenting out a moves it into the comments above b
+	code := "module a; endmodule\nmodule b; endmodule\n"
+	edited, tokens, ast, err := reparse(t, code, Verilog, Edit{Start: 0, OldEnd: 0, NewEnd: 3}, "// ")
+	checkReparse(t, edited, Verilog, tokens, ast, err)
+	if doc := ast.Statements[0].Module.Comments.Doc(); doc != "module a; endmodule" {
+		t.Errorf("comments of b are %q", doc)
+	}
+}
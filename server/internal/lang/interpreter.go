@@ -1,28 +1,93 @@
 package lang
 
 import (
+	"fmt"
+	"strings"
+
 	"go.lsp.dev/protocol"
 	"go.uber.org/zap"
 )
 
+// Warnings turns the warnings that are a matter of taste on and off
+type Warnings struct {
+	UnconnectedInputs  bool `json:"unconnectedInputs"`  // inputs and inouts that named connections leave out
+	EmptyConnections   bool `json:"emptyConnections"`   // inputs and inouts that are connected to nothing, ie .p()
+	UnconnectedOutputs bool `json:"unconnectedOutputs"` // outputs that are left out or connected to nothing
+}
+
+// DefaultWarnings warns about floating inputs, but not about outputs, which are often left unconnected on purpose
+var DefaultWarnings = Warnings{UnconnectedInputs: true, EmptyConnections: true}
+
 type Interpreter struct {
-	builtins    map[string]bool
-	defines     []DefineNode
-	Diagnostics []protocol.Diagnostic
-	moduleMap   map[string]ModuleNode
-	log         *zap.Logger
+	builtins         map[string]bool
+	macros           map[string]bool         // macros that are currently defined, with their backticks
+	defines          map[string][]DefineNode // map of file name : the defines in it
+	nettype          string                  // default nettype of the module being diagnosed
+	imported         map[string]bool         // names that the module being diagnosed imports from packages
+	typedefs         map[string]TypedefNode  // typedefs that the module being diagnosed imports from packages
+	fileImported     map[string]bool         // names that the file-level imports so far import, which every module after them starts with
+	fileTypedefs     map[string]TypedefNode  // typedefs that the file-level imports so far import
+	Diagnostics      []protocol.Diagnostic
+	moduleMap        map[string]ModuleNode
+	moduleFiles      map[string]string          // map of module name : name of the file it's in
+	moduleScopes     map[string]*Scope          // map of module name : its scope, made when it's first needed
+	overridden       map[string]map[string]bool // map of instance name : (parameter name : true) for the defparams of the module being diagnosed
+	resolving        map[*Symbol]bool           // parameters whose values are being evaluated, which can't refer to themselves
+	interfaceMap     map[string]InterfaceNode
+	interfaceSignals map[string]map[string]bool // map of interface name : (signal name : true)
+	packageMap       map[string]PackageNode
+	packageSymbols   map[string]map[string]bool // map of package name : (symbol name : true)
+	log              *zap.Logger
+
+	// Warnings are the configurable warnings that are on
+	Warnings Warnings
+
+	// FileURI returns the uri of a file from the name that NewInterpreter's modules are grouped by,
+	// so that diagnostics can point at module definitions. Without it, they don't
+	FileURI func(fname string) protocol.DocumentURI
+
+	// File is the name that the file being interpreted is grouped by in NewInterpreter's defines.
+	// Its own defines are only known after their `define, so they're left out of the workspace's
+	File string
 }
 
-func NewInterpreter(logger *zap.Logger, modules map[string][]ModuleNode, defines map[string][]DefineNode) *Interpreter {
+func NewInterpreter(logger *zap.Logger, modules map[string][]ModuleNode, interfaces map[string][]InterfaceNode, packages map[string][]PackageNode, defines map[string][]DefineNode) *Interpreter {
 	moduleMap := map[string]ModuleNode{}
-	flattenedDefines := []DefineNode{}
-	for _, mods := range modules {
+	moduleFiles := map[string]string{}
+	interfaceMap := map[string]InterfaceNode{}
+	interfaceSignals := map[string]map[string]bool{}
+	packageMap := map[string]PackageNode{}
+	packageSymbols := map[string]map[string]bool{}
+	for fname, mods := range modules {
 		for _, module := range mods {
-			moduleMap[module.Identifier.Value] = module
+			moduleMap[module.Identifier.Name()] = module
+			moduleFiles[module.Identifier.Name()] = fname
+		}
+	}
+	for _, ifaces := range interfaces {
+		for _, iface := range ifaces {
+			interfaceMap[iface.Identifier.Name()] = iface
+
+			// signals are the ports and anything declared inside the interface
+			signals := map[string]bool{}
+			for _, port := range iface.PortList.Ports {
+				signals[port.Name()] = true
+			}
+			for _, statement := range iface.Interior {
+				if statement.DeclarationNode != nil {
+					for _, variable := range statement.DeclarationNode.Variables {
+						signals[variable.Identifier.Name()] = true
+					}
+				}
+			}
+			interfaceSignals[iface.Identifier.Name()] = signals
 		}
 	}
-	for _, defs := range defines {
-		flattenedDefines = append(flattenedDefines, defs...)
+	for _, pkgs := range packages {
+		for _, pkg := range pkgs {
+			packageMap[pkg.Identifier.Name()] = pkg
+			packageSymbols[pkg.Identifier.Name()] = pkg.Symbols()
+		}
 	}
 	builtins := map[string]bool{
 		"and":    true,
@@ -40,194 +105,653 @@ func NewInterpreter(logger *zap.Logger, modules map[string][]ModuleNode, defines
 	}
 
 	return &Interpreter{
-		defines:     flattenedDefines,
-		Diagnostics: []protocol.Diagnostic{},
-		moduleMap:   moduleMap,
-		log:         logger,
-		builtins:    builtins,
+		macros:           map[string]bool{},
+		defines:          defines,
+		imported:         map[string]bool{},
+		typedefs:         map[string]TypedefNode{},
+		Diagnostics:      []protocol.Diagnostic{},
+		moduleMap:        moduleMap,
+		moduleFiles:      moduleFiles,
+		moduleScopes:     map[string]*Scope{},
+		overridden:       map[string]map[string]bool{},
+		resolving:        map[*Symbol]bool{},
+		Warnings:         DefaultWarnings,
+		interfaceMap:     interfaceMap,
+		interfaceSignals: interfaceSignals,
+		packageMap:       packageMap,
+		packageSymbols:   packageSymbols,
+		log:              logger,
+		builtins:         builtins,
 	}
 }
 func (i *Interpreter) addUnknownDiagnostic(identifier Token, description string) {
+	i.addDiagnostic(identifier, protocol.DiagnosticSeverityWarning, "Unknown "+description+": "+identifier.Value)
+}
+
+// addModuleDiagnostic adds a diagnostic that's about the module, which it relates to the module's definition
+func (i *Interpreter) addModuleDiagnostic(identifier Token, severity protocol.DiagnosticSeverity, message string, module ModuleNode) {
+	i.addDiagnostic(identifier, severity, message)
+	i.relateTo(module)
+}
+
+// relateTo points the last diagnostic at the definition of the module, if the file it's in is known
+func (i *Interpreter) relateTo(module ModuleNode) {
+	fname, ok := i.moduleFiles[module.Identifier.Name()]
+	if !ok || i.FileURI == nil {
+		return
+	}
+	identifier := module.Identifier
+	diagnostic := &i.Diagnostics[len(i.Diagnostics)-1]
+	diagnostic.RelatedInformation = append(diagnostic.RelatedInformation, protocol.DiagnosticRelatedInformation{
+		Location: protocol.Location{
+			URI: i.FileURI(fname),
+			Range: protocol.Range{
+				Start: protocol.Position{Line: uint32(identifier.line), Character: uint32(identifier.startCharacter)},
+				End:   protocol.Position{Line: uint32(identifier.line), Character: uint32(identifier.endCharacter)},
+			},
+		},
+		Message: "module " + identifier.Value + " is defined here",
+	})
+}
+func (i *Interpreter) addDiagnostic(identifier Token, severity protocol.DiagnosticSeverity, message string) {
+	i.addSpanDiagnostic(Span{Start: identifier, End: identifier}, severity, message)
+}
+
+// addSpanDiagnostic adds a diagnostic that covers a whole node, from the start of its first token to the end of its last
+func (i *Interpreter) addSpanDiagnostic(span Span, severity protocol.DiagnosticSeverity, message string) {
 	i.Diagnostics = append(i.Diagnostics, protocol.Diagnostic{
 		Range: protocol.Range{
 			Start: protocol.Position{
-				Line:      uint32(identifier.line),
-				Character: uint32(identifier.startCharacter),
+				Line:      uint32(span.Start.line),
+				Character: uint32(span.Start.startCharacter),
 			},
 			End: protocol.Position{
-				Line:      uint32(identifier.line),
-				Character: uint32(identifier.endCharacter),
+				Line:      uint32(span.End.line),
+				Character: uint32(span.End.endCharacter),
 			},
 		},
-		Severity: protocol.DiagnosticSeverityWarning,
-		Message:  "Unknown " + description + ": " + identifier.Value,
+		Severity: severity,
+		Message:  message,
 	})
 }
-func (i *Interpreter) diagnoseSelector(node SelectorNode, curSymbols map[string]bool) {
-	if node.IndexNode != nil {
-		i.diagnoseExpression(node.IndexNode.Index, curSymbols)
-	} else if node.RangeNode != nil {
-		i.diagnoseExpression(node.RangeNode.From, curSymbols)
-		i.diagnoseExpression(node.RangeNode.To, curSymbols)
-	}
-}
-func (i *Interpreter) diagnoseExpression(node ExprNode, curSymbols map[string]bool) {
-	// expressions don't add new variables, so just look at existing
-	for _, val := range node.Value.Values {
-		for _, tok := range val.Value {
-			if tok.Type == "identifier" {
-				_, ok := curSymbols[tok.Value]
-				if !ok {
-					i.addUnknownDiagnostic(tok, "variable")
-				}
+
+// known returns whether the name refers to something in the scope,
+// whether it's declared, imported, or a defined macro
+func (i *Interpreter) known(scope *Scope, name string) bool {
+	return scope.Lookup(name) != nil || i.imported[name] || i.macros[name]
+}
+
+// checks whether an undeclared identifier is allowed to become an implicit net,
+// returning true if a diagnostic was added
+func (i *Interpreter) diagnoseImplicitNet(identifier Token, scope *Scope) bool {
+	if i.known(scope, identifier.Name()) || i.nettype != "none" {
+		return false
+	}
+	i.addDiagnostic(identifier, protocol.DiagnosticSeverityError, "Implicit net not allowed with `default_nettype none: "+identifier.Value)
+	return true
+}
+
+// returns the identifier that an expression consists of, if it's just a single identifier
+func implicitNetCandidate(node ExprNode) (Token, bool) {
+	if node.Combinator != nil || node.ExprTrue != nil || node.Value.Size != nil || len(node.Value.Values) != 1 {
+		return Token{}, false
+	}
+	value := node.Value.Values[0]
+	if len(value.Value) != 1 || value.Value[0].Type != IDENTIFIER {
+		return Token{}, false
+	}
+	return value.Value[0], true
+}
+
+// returns the interface that a symbol is, if it's an interface port or instance
+func (i *Interpreter) interfacePort(symbol *Symbol) (InterfacePortNode, bool) {
+	if symbol == nil {
+		return InterfacePortNode{}, false
+	}
+	if symbol.Interface != nil {
+		return *symbol.Interface, true
+	}
+	if symbol.Type == nil || symbol.Type.Type.Type != IDENTIFIER || symbol.Type.Scope != nil {
+		return InterfacePortNode{}, false
+	}
+	// an instance of an interface, or a port whose user-defined type is an interface without a modport
+	if _, ok := i.interfaceMap[symbol.Type.Type.Name()]; ok && (symbol.Kind == InstanceSymbol || symbol.Kind == PortSymbol) {
+		return InterfacePortNode{Interface: symbol.Type.Type}, true
+	}
+	return InterfacePortNode{}, false
+}
+
+// checks accesses to the members of interface ports and instances, ie m.awvalid,
+// returning false if the identifier isn't an interface
+func (i *Interpreter) diagnoseInterfaceAccess(scope *Scope, identifier Token, members []Token, assigned bool) bool {
+	port, ok := i.interfacePort(scope.Lookup(identifier.Name()))
+	if !ok {
+		return false
+	}
+	iface, ok := i.interfaceMap[port.Interface.Name()]
+	if !ok || len(members) == 0 {
+		// either the interface is unknown, which was already reported,
+		// or the whole interface is being used
+		return true
+	}
+	member := members[0]
+
+	if port.Modport == nil {
+		// everything in the interface is accessible
+		if !i.interfaceSignals[iface.Identifier.Name()][member.Name()] {
+			i.addUnknownDiagnostic(member, "member of interface "+iface.Identifier.Value)
+		}
+		return true
+	}
+
+	// only what's in the modport is accessible, and only in the right direction
+	modport := iface.Modport(port.Modport.Name())
+	if modport == nil {
+		return true // unknown modport was already reported
+	}
+	for _, modportPort := range modport.Ports {
+		if modportPort.Identifier.Name() == member.Name() {
+			if assigned && modportPort.Direction.Value == "input" {
+				i.addDiagnostic(member, protocol.DiagnosticSeverityError, "Cannot assign to input "+member.Value+" of modport "+modport.Identifier.Value)
 			}
+			return true
 		}
-		for _, selector := range val.Selectors {
-			i.diagnoseSelector(selector, curSymbols)
+	}
+	i.addUnknownDiagnostic(member, "member of modport "+modport.Identifier.Value)
+	return true
+}
+
+// looks up the typedef that a user-defined type refers to
+func (i *Interpreter) resolveType(scope *Scope, typeNode TypeNode) (TypedefNode, bool) {
+	if typeNode.Scope != nil {
+		pkg, ok := i.packageMap[typeNode.Scope.Name()]
+		if !ok {
+			return TypedefNode{}, false
 		}
+		return pkg.Typedef(typeNode.Type.Name())
 	}
-	if node.Right != nil {
-		i.diagnoseExpression(*node.Right, curSymbols)
+	if symbol := scope.Lookup(typeNode.Type.Name()); symbol != nil && symbol.Typedef != nil {
+		return *symbol.Typedef, true
 	}
-	if node.ExprTrue != nil {
-		i.diagnoseExpression(*node.ExprTrue, curSymbols)
+	typedef, ok := i.typedefs[typeNode.Type.Name()]
+	return typedef, ok
+}
+
+// checks that a user-defined type exists
+func (i *Interpreter) diagnoseType(scope *Scope, typeNode TypeNode) {
+	if typeNode.Type.Type != IDENTIFIER {
+		return
 	}
-	if node.ExprFalse != nil {
-		i.diagnoseExpression(*node.ExprFalse, curSymbols)
+	if typeNode.Scope != nil {
+		if _, ok := i.packageMap[typeNode.Scope.Name()]; !ok {
+			i.addUnknownDiagnostic(*typeNode.Scope, "package")
+			return
+		}
+	}
+	if _, ok := i.resolveType(scope, typeNode); !ok {
+		i.addUnknownDiagnostic(typeNode.Type, "type")
 	}
 }
-func (i *Interpreter) diagnoseAlwaysNode(node AlwaysStatement, curSymbols map[string]bool) map[string]bool {
-	knownSymbols := curSymbols
-	if node.BeginBlock != nil {
-		for _, statement := range node.BeginBlock.Statements {
-			knownSymbols = i.diagnoseAlwaysNode(statement, knownSymbols)
+
+// checks accesses to the members of struct variables, ie req.valid,
+// returning false if the identifier isn't a struct
+func (i *Interpreter) diagnoseStructAccess(scope *Scope, identifier Token, members []Token) bool {
+	symbol := scope.Lookup(identifier.Name())
+	if symbol == nil || symbol.Kind == TypeSymbol || symbol.Type == nil || symbol.Type.Type.Type != IDENTIFIER {
+		return false
+	}
+	typedef, ok := i.resolveType(scope, *symbol.Type)
+	if !ok || typedef.Struct == nil {
+		return false
+	}
+	structNode := *typedef.Struct
+	for _, member := range members {
+		declaration := structNode.Member(member.Name())
+		if declaration == nil {
+			i.addUnknownDiagnostic(member, "struct member")
+			return true
 		}
-	} else if node.CaseNode != nil {
-		i.diagnoseExpression(node.CaseNode.Expr, knownSymbols)
-		for _, case_ := range node.CaseNode.Cases {
-			for _, cond := range case_.Conditions {
-				i.diagnoseExpression(cond, knownSymbols)
+
+		// nested structs keep going, anything else can't have members
+		typedef, ok := i.resolveType(scope, declaration.Type)
+		if declaration.Type.Type.Type != IDENTIFIER || !ok || typedef.Struct == nil {
+			return true
+		}
+		structNode = *typedef.Struct
+	}
+	return true
+}
+
+// checks the types and values that a typedef uses
+func (i *Interpreter) diagnoseTypedef(typedef TypedefNode, scope *Scope) {
+	if typedef.Enum != nil {
+		for _, member := range typedef.Enum.Members {
+			if member.Value != nil {
+				i.diagnoseExpression(*member.Value, scope)
 			}
-			knownSymbols = i.diagnoseAlwaysNode(case_.Statement, knownSymbols)
 		}
-	} else if node.ForBlock != nil {
-		if node.ForBlock.Initializer != nil {
-			i.diagnoseAssignmentNode(*node.ForBlock.Initializer, knownSymbols)
+	} else if typedef.Struct != nil {
+		for _, member := range typedef.Struct.Members {
+			i.diagnoseType(scope, member.Type)
 		}
-		if node.ForBlock.Condition != nil {
-			i.diagnoseExpression(*node.ForBlock.Condition, knownSymbols)
+	} else if typedef.Type != nil {
+		i.diagnoseType(scope, *typedef.Type)
+	}
+}
+
+// makes the items of imported packages known in imported and typedefs
+func (i *Interpreter) importPackages(node ImportNode, imported map[string]bool, typedefs map[string]TypedefNode) {
+	for _, item := range node.Items {
+		pkg, ok := i.packageMap[item.Package.Name()]
+		if !ok {
+			i.addUnknownDiagnostic(item.Package, "package")
+			continue
 		}
-		if node.ForBlock.Incrementor != nil {
-			i.diagnoseAssignmentNode(*node.ForBlock.Incrementor, knownSymbols)
+		symbols := i.packageSymbols[item.Package.Name()]
+		if item.Item.Name() == "*" {
+			for symbol := range symbols {
+				imported[symbol] = true
+			}
+			for _, typedef := range pkg.Typedefs() {
+				typedefs[typedef.Identifier.Name()] = typedef
+			}
+			continue
 		}
-		i.diagnoseAlwaysNode(node.ForBlock.Body, knownSymbols)
-	} else if node.FunctionNode != nil {
-		for _, arg := range node.FunctionNode.Expressions {
-			i.diagnoseExpression(arg, knownSymbols)
+		if !symbols[item.Item.Name()] {
+			i.addUnknownDiagnostic(item.Item, "member of package "+item.Package.Value)
+			continue
 		}
-	} else if node.IfBlock != nil {
-		i.diagnoseExpression(node.IfBlock.Expr, knownSymbols)
-		knownSymbols = i.diagnoseAlwaysNode(node.IfBlock.Body, knownSymbols)
-		if node.IfBlock.Else != nil {
-			knownSymbols = i.diagnoseAlwaysNode(*node.IfBlock.Else, knownSymbols)
+		imported[item.Item.Name()] = true
+		if typedef, ok := pkg.Typedef(item.Item.Name()); ok {
+			typedefs[typedef.Identifier.Name()] = typedef
 		}
-	} else if node.InteriorNode != nil {
-		knownSymbols = i.diagnoseInteriorNode(*node.InteriorNode, knownSymbols)
 	}
-	return knownSymbols
 }
-func (i *Interpreter) diagnoseAlwaysStatements(statements []AlwaysStatement, curSymbols map[string]bool) map[string]bool {
-	knownSymbols := curSymbols
-	for _, statement := range statements {
-		knownSymbols = i.diagnoseAlwaysNode(statement, knownSymbols)
+
+// checks a package-scoped value, ie cfg_pkg::IDLE
+func (i *Interpreter) diagnoseScopedValue(scope Token, identifier Token) {
+	symbols, ok := i.packageSymbols[scope.Name()]
+	if !ok {
+		i.addUnknownDiagnostic(scope, "package")
+	} else if !symbols[identifier.Name()] {
+		i.addUnknownDiagnostic(identifier, "member of package "+scope.Value)
 	}
-	return knownSymbols
 }
-func (i *Interpreter) diagnoseAssignmentNode(node AssignmentNode, curSymbols map[string]bool) map[string]bool {
-	knownSymbols := curSymbols
-	for _, variable := range node.Variables {
-		_, ok := knownSymbols[variable.Identifier.Value]
-		if !ok {
-			i.addUnknownDiagnostic(variable.Identifier, "variable")
-		}
-		for _, selector := range variable.Selectors {
-			if selector.IndexNode != nil {
-				i.diagnoseExpression(selector.IndexNode.Index, curSymbols)
-			} else if selector.RangeNode != nil {
-				i.diagnoseExpression(selector.RangeNode.From, curSymbols)
-				i.diagnoseExpression(selector.RangeNode.To, curSymbols)
+
+// checks the types and interfaces of the ports of a module or interface
+func (i *Interpreter) diagnosePorts(portList PortListNode, scope *Scope) {
+	for _, port := range portList.Declarations {
+		if port.Type != nil && port.Type.Type.Type == IDENTIFIER && port.Type.Scope == nil {
+			// a user-defined type could also be an interface without a modport
+			if _, ok := i.interfaceMap[port.Type.Type.Name()]; ok {
+				continue
 			}
 		}
+		if port.Type != nil {
+			i.diagnoseType(scope, *port.Type)
+		}
+		if port.Interface == nil {
+			continue
+		}
+
+		iface, ok := i.interfaceMap[port.Interface.Interface.Name()]
+		if !ok {
+			i.addUnknownDiagnostic(port.Interface.Interface, "interface")
+		} else if port.Interface.Modport != nil && iface.Modport(port.Interface.Modport.Name()) == nil {
+			i.addUnknownDiagnostic(*port.Interface.Modport, "modport")
+		}
 	}
-	// also check the right hand side
-	i.diagnoseExpression(node.Value, knownSymbols)
-	return knownSymbols
 }
-func (i *Interpreter) diagnoseInteriorNode(node InteriorNode, curSymbols map[string]bool) map[string]bool {
-	knownSymbols := curSymbols
 
-	if node.AssignmentNode != nil {
-		i.diagnoseAssignmentNode(*node.AssignmentNode, knownSymbols)
-	} else if node.DeclarationNode != nil {
-		for _, variable := range node.DeclarationNode.Variables {
-			knownSymbols[variable.Identifier.Value] = true
+// checks that the port list of a module or interface agrees with the port declarations in its body
+func (i *Interpreter) diagnosePortDeclarations(portList PortListNode, interior []InteriorNode, scope *Scope) {
+	ansi := len(portList.Declarations) > 0
+	listed := map[string]bool{}
+	for _, port := range portList.Ports {
+		listed[port.Name()] = true
+		if symbol := scope.Symbol(port.Name()); !ansi && symbol != nil && symbol.Direction == nil {
+			i.addDiagnostic(port, protocol.DiagnosticSeverityWarning, "Port has no direction declaration: "+port.Value)
 		}
-	} else if node.ModuleApplicationNode != nil {
-		name := node.ModuleApplicationNode.ModuleName.Value
-		mod, ok := i.moduleMap[name]
-		_, lessOk := i.builtins[name]
-		if !ok && !lessOk {
-			i.addUnknownDiagnostic(node.ModuleApplicationNode.ModuleName, "module")
+	}
+	for _, statement := range interior {
+		if statement.DeclarationNode == nil {
+			continue
+		}
+		for _, variable := range statement.DeclarationNode.Variables {
+			identifier := variable.Identifier
+			if ansi && listed[identifier.Name()] {
+				i.addDiagnostic(identifier, protocol.DiagnosticSeverityWarning, "Port is already declared in the port list: "+identifier.Value)
+			} else if statement.DeclarationNode.Type.Direction != nil && !listed[identifier.Name()] {
+				i.addDiagnostic(identifier, protocol.DiagnosticSeverityWarning, "Direction declared for a name that isn't in the port list: "+identifier.Value)
+			}
 		}
-		for _, argument := range node.ModuleApplicationNode.Arguments {
-			i.diagnoseExpression(argument.Value, knownSymbols)
+	}
+}
 
-			if argument.Label != nil && ok {
-				exists := false
-				for _, port := range mod.PortList.Ports {
-					if port.Value == argument.Label.Value {
-						exists = true
-					}
-				}
-				if !exists {
-					i.addUnknownDiagnostic(*argument.Label, "module port")
-				}
+// returns whether the argument connects a port by name, which .* does too
+func named(argument ArgumentNode) bool {
+	return argument.Label != nil || argument.Wildcard != nil
+}
+
+// checks the connections of an instance against the ports of its module, which have to be either
+// all named or all positional, with named ones connecting existing ports at most once,
+// and positional ones connecting at most as many ports as there are
+func (i *Interpreter) diagnoseConnections(node ModuleApplicationNode, scope *Scope) {
+	module := i.moduleMap[node.ModuleName.Name()]
+	ports := map[string]bool{}
+	for _, port := range module.PortList.Ports {
+		ports[port.Name()] = true
+	}
+
+	connected := map[string]bool{}
+	positional := 0
+	var wildcard *Token
+	for _, argument := range node.Arguments {
+		if named(argument) != named(node.Arguments[0]) {
+			start := argument.Span.Start
+			if argument.Label != nil {
+				start = *argument.Label
 			}
+			i.addModuleDiagnostic(start, protocol.DiagnosticSeverityError, "Cannot mix named and positional connections to module "+module.Identifier.Value, module)
+			return
+		}
+		if argument.Wildcard != nil {
+			wildcard = argument.Wildcard
+			continue
+		}
+		if argument.Label == nil {
+			positional++
+			if positional == len(module.PortList.Ports)+1 {
+				i.addModuleDiagnostic(argument.Span.Start, protocol.DiagnosticSeverityError, fmt.Sprintf("Too many connections to module %s: %d given, %d expected", module.Identifier.Value, len(node.Arguments), len(module.PortList.Ports)), module)
+			}
+			continue
+		}
+
+		label := *argument.Label
+		if !ports[label.Name()] {
+			i.addUnknownDiagnostic(label, "module port")
+			i.relateTo(module)
+		} else if connected[label.Name()] {
+			i.addModuleDiagnostic(label, protocol.DiagnosticSeverityWarning, "Port is connected more than once: "+label.Value, module)
+		} else if argument.Implicit && !i.known(scope, label.Name()) {
+			// .name connects the signal with the same name, which has to exist
+			i.addUnknownDiagnostic(label, "variable")
+		} else if !argument.Implicit && argument.Value.Span == (Span{}) {
+			i.diagnoseUnconnected(label, label, module, true)
+		}
+		connected[label.Name()] = true
+	}
+	if positional > 0 && positional < len(module.PortList.Ports) {
+		i.addModuleDiagnostic(node.ModuleName, protocol.DiagnosticSeverityWarning, fmt.Sprintf("Too few connections to module %s: %d given, %d expected", module.Identifier.Value, positional, len(module.PortList.Ports)), module)
+	}
+	if positional > 0 {
+		return
+	}
+
+	// the ports that named connections leave out, which is all of them without any connections
+	instance := node.ModuleName
+	if node.GateName != nil {
+		instance = *node.GateName
+	}
+	for _, port := range module.PortList.Ports {
+		if connected[port.Name()] {
+			continue
+		}
+		if wildcard == nil {
+			i.diagnoseUnconnected(instance, port, module, false)
+		} else if !i.known(scope, port.Name()) {
+			i.addModuleDiagnostic(*wildcard, protocol.DiagnosticSeverityWarning, "No signal for .* to connect to port "+port.Value+" of module "+module.Identifier.Value, module)
+		}
+	}
+}
+
+// warns about a port of the module that's left unconnected, either because it was left out
+// of a named instantiation or because it was explicitly connected to nothing, ie .p(),
+// as long as warnings for that kind of port are on
+func (i *Interpreter) diagnoseUnconnected(at Token, port Token, module ModuleNode, explicit bool) {
+	symbol := i.moduleScope(module).Symbol(port.Name())
+	if symbol == nil || symbol.Direction == nil {
+		// interface ports, and ports without a direction, which was already reported
+		return
+	}
+	direction := symbol.Direction.Value
+	description := strings.ToUpper(direction[:1]) + direction[1:] + " " + port.Value + " of module " + module.Identifier.Value
+	switch {
+	case direction == "output" && i.Warnings.UnconnectedOutputs:
+		i.addModuleDiagnostic(at, protocol.DiagnosticSeverityWarning, description+" is not connected", module)
+	case direction != "output" && explicit && i.Warnings.EmptyConnections:
+		i.addModuleDiagnostic(at, protocol.DiagnosticSeverityWarning, description+" is explicitly connected to nothing", module)
+	case direction != "output" && !explicit && i.Warnings.UnconnectedInputs:
+		i.addModuleDiagnostic(at, protocol.DiagnosticSeverityWarning, description+" is not connected", module)
+	}
+}
+
+// moduleScope returns the scope of a module that could be in another file
+func (i *Interpreter) moduleScope(module ModuleNode) *Scope {
+	name := module.Identifier.Name()
+	if _, ok := i.moduleScopes[name]; !ok {
+		i.moduleScopes[name] = newModuleScope(module)
+	}
+	return i.moduleScopes[name]
+}
+
+// applies a directive to the set of defined macros
+func applyDirective(node DirectiveNode, macros map[string]bool) {
+	if node.DefineNode != nil {
+		macros["`"+node.DefineNode.Identifier.Name()] = true
+	} else if node.UndefNode != nil {
+		delete(macros, "`"+node.UndefNode.Identifier.Name())
+	}
+}
+
+// checks the identifiers of a value
+func (i *Interpreter) diagnoseValue(val ValueNode, scope *Scope) {
+	if val.Scope != nil && len(val.Value) > 0 {
+		i.diagnoseScopedValue(*val.Scope, val.Value[0])
+		return
+	}
+	if len(val.Value) > 0 && (i.diagnoseInterfaceAccess(scope, val.Value[0], val.Value[1:], false) || i.diagnoseStructAccess(scope, val.Value[0], val.Value[1:])) {
+		// it's a member of an interface or struct, so the rest are checked against that
+		return
+	}
+	for _, tok := range val.Value {
+		if tok.Type == IDENTIFIER && !i.known(scope, tok.Name()) {
+			i.addUnknownDiagnostic(tok, "variable")
+		}
+	}
+}
+func (i *Interpreter) diagnoseExpression(node ExprNode, scope *Scope) {
+	Walk(checker{interpreter: i, scope: scope}, node)
+}
+
+// checker diagnoses the statements of a module, interface, or package,
+// resolving identifiers in the innermost scope around them
+type checker struct {
+	interpreter *Interpreter
+	scope       *Scope
+}
+
+// enter returns the checker for the inside of the node that has the span,
+// which is in the node's own scope if it has one
+func (c checker) enter(span Span) checker {
+	if child := c.scope.child(span); child != nil {
+		return checker{interpreter: c.interpreter, scope: child}
+	}
+	return c
+}
+
+func (c checker) Visit(node Node, path []Node) Visitor {
+	i, scope := c.interpreter, c.scope
+	switch node := node.(type) {
+	case ValueNode:
+		i.diagnoseValue(node, scope)
+	case AssignmentNode:
+		i.diagnoseAssignmentWidth(node, scope)
+	case AssignmentVariableNode:
+		assignment := path[len(path)-1].(AssignmentNode)
+		if !i.known(scope, node.Identifier.Name()) && !(assignment.IsAssign && i.diagnoseImplicitNet(node.Identifier, scope)) {
+			i.addUnknownDiagnostic(node.Identifier, "variable")
+		}
+		if !i.diagnoseInterfaceAccess(scope, node.Identifier, node.Members, true) {
+			i.diagnoseStructAccess(scope, node.Identifier, node.Members)
 		}
-	} else if node.AlwaysNode != nil {
-		knownSymbols = i.diagnoseAlwaysNode(node.AlwaysNode.Statement, knownSymbols)
-	} else if node.DefParamNode != nil {
-		i.diagnoseExpression(node.DefParamNode.Value, knownSymbols)
-		for _, variable := range node.DefParamNode.Identifiers {
-			knownSymbols[variable.Value] = true
+	case BeginBlockNode:
+		return c.enter(node.Span)
+	case ForBlockNode:
+		return c.enter(node.Span)
+	case TaskNode:
+		return c.enter(node.Span)
+	case TypeNode:
+		// types are checked along with what they declare
+		return nil
+	case DeclarationNode:
+		i.diagnoseType(scope, node.Type)
+		for _, value := range node.Values {
+			Walk(c, value)
+		}
+		i.diagnoseDeclarationWidths(node, scope)
+		return nil
+	case ModuleApplicationNode:
+		name := node.ModuleName.Name()
+		_, ok := i.moduleMap[name]
+		_, lessOk := i.builtins[name]
+		_, isInterface := i.interfaceMap[name]
+		if !ok && !lessOk && !isInterface {
+			i.addUnknownDiagnostic(node.ModuleName, "module")
+		} else if ok {
+			i.diagnoseConnections(node, scope)
+			i.diagnoseConnectionWidths(node, scope)
+		}
+	case ArgumentNode:
+		identifier, isNet := implicitNetCandidate(node.Value)
+		if !isNet || !i.diagnoseImplicitNet(identifier, scope) {
+			Walk(c, node.Value)
+		}
+		return nil
+	case DefParamNode:
+		// the parameters it overrides belong to other modules
+		Walk(c, node.Value)
+		return nil
+	case DirectiveNode:
+		applyDirective(node, i.macros)
+		return nil
+	case ModportNode:
+		for _, port := range node.Ports {
+			if !i.known(scope, port.Identifier.Name()) {
+				i.addUnknownDiagnostic(port.Identifier, "interface signal")
+			}
 		}
-	} else if node.DirectiveNode != nil {
-		knownSymbols[node.DirectiveNode.Identifier.Value] = true
-	} else if node.GenerateNode != nil {
-		knownSymbols = i.diagnoseAlwaysStatements(node.GenerateNode.Statements, knownSymbols)
-	} else if node.InitialNode != nil {
-		i.diagnoseAlwaysNode(node.InitialNode.Statement, knownSymbols)
-	} else if node.TaskNode != nil {
-		knownSymbols = i.diagnoseAlwaysStatements(node.TaskNode.Statements, knownSymbols)
+		return nil
+	case ImportNode:
+		i.importPackages(node, i.imported, i.typedefs)
+		return nil
+	case TypedefNode:
+		i.diagnoseTypedef(node, scope)
+		return nil
 	}
+	return c
+}
+
+// forgets what the previous module imported, starting over with
+// what the file-level imports before it already resolved
+func (i *Interpreter) resetImports() {
+	i.imported = map[string]bool{}
+	for name := range i.fileImported {
+		i.imported[name] = true
+	}
+	i.typedefs = map[string]TypedefNode{}
+	for name, typedef := range i.fileTypedefs {
+		i.typedefs[name] = typedef
+	}
+}
 
-	return knownSymbols
+// remembers the parameters of instances that the defparams of a module override
+func (i *Interpreter) findOverrides(interior []InteriorNode) {
+	i.overridden = map[string]map[string]bool{}
+	for _, statement := range interior {
+		Inspect(statement, func(node Node, path []Node) bool {
+			defparam, ok := node.(DefParamNode)
+			if !ok || len(defparam.Identifiers) != 2 {
+				// parameters deeper in the hierarchy don't belong to the instances here
+				return true
+			}
+			instance := defparam.Identifiers[0].Name()
+			if i.overridden[instance] == nil {
+				i.overridden[instance] = map[string]bool{}
+			}
+			i.overridden[instance][defparam.Identifiers[1].Name()] = true
+			return true
+		})
+	}
 }
 
-func (i *Interpreter) diagnoseModule(module ModuleNode) {
-	knownSymbols := map[string]bool{}
-	for _, define := range i.defines {
-		knownSymbols["`"+define.Identifier.Value] = true
+func (i *Interpreter) diagnoseModule(module ModuleNode, scope *Scope) {
+	i.resetImports()
+	for _, importNode := range module.Imports {
+		i.importPackages(importNode, i.imported, i.typedefs)
 	}
+	i.findOverrides(module.Interior)
+	i.diagnosePorts(module.PortList, scope)
+	i.diagnosePortDeclarations(module.PortList, module.Interior, scope)
 	for _, statement := range module.Interior {
-		knownSymbols = i.diagnoseInteriorNode(statement, knownSymbols)
+		Walk(checker{interpreter: i, scope: scope}, statement)
 	}
 }
 
+func (i *Interpreter) diagnoseInterface(iface InterfaceNode, scope *Scope) {
+	i.resetImports()
+	i.findOverrides(iface.Interior)
+	i.diagnosePorts(iface.PortList, scope)
+	i.diagnosePortDeclarations(iface.PortList, iface.Interior, scope)
+	for _, statement := range iface.Interior {
+		Walk(checker{interpreter: i, scope: scope}, statement)
+	}
+}
+
+func (i *Interpreter) diagnosePackage(pkg PackageNode, scope *Scope) {
+	i.resetImports()
+	for _, statement := range pkg.Interior {
+		Walk(checker{interpreter: i, scope: scope}, statement)
+	}
+}
+
+// Interpret diagnoses the file, resolving names with its symbol table
 func (i *Interpreter) Interpret(FileNode FileNode) []protocol.Diagnostic {
+	return i.InterpretWithTable(FileNode, NewSymbolTable(FileNode))
+}
+
+// InterpretWithTable diagnoses the file with a symbol table
+// that was already made for it by NewSymbolTable
+func (i *Interpreter) InterpretWithTable(FileNode FileNode, table *Scope) []protocol.Diagnostic {
+	i.fileImported = map[string]bool{}
+	i.fileTypedefs = map[string]TypedefNode{}
+	// the file starts out with the macros of the rest of the workspace,
+	// and its own directives define and undefine them as they come
+	i.macros = map[string]bool{}
+	for fname, defs := range i.defines {
+		if fname == i.File {
+			continue
+		}
+		for _, define := range defs {
+			i.macros["`"+define.Identifier.Name()] = true
+		}
+	}
 	for _, topLevelStatement := range FileNode.Statements {
 		if topLevelStatement.Module != nil {
-			i.diagnoseModule(*topLevelStatement.Module)
+			i.nettype = FileNode.DefaultNettype(topLevelStatement.Module.Identifier)
+			i.diagnoseModule(*topLevelStatement.Module, table.child(topLevelStatement.Module.Span))
+		} else if topLevelStatement.Interface != nil {
+			i.nettype = FileNode.DefaultNettype(topLevelStatement.Interface.Identifier)
+			i.diagnoseInterface(*topLevelStatement.Interface, table.child(topLevelStatement.Interface.Span))
+		} else if topLevelStatement.Package != nil {
+			i.diagnosePackage(*topLevelStatement.Package, table.child(topLevelStatement.Package.Span))
+		} else if topLevelStatement.Import != nil {
+			// file-level imports apply to everything after them,
+			// but they're only resolved and diagnosed once
+			i.importPackages(*topLevelStatement.Import, i.fileImported, i.fileTypedefs)
+		} else if topLevelStatement.Directive != nil {
+			// macros are only known after they're defined and until they're undefined
+			applyDirective(*topLevelStatement.Directive, i.macros)
 		}
 	}
 
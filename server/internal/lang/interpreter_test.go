@@ -0,0 +1,44 @@
+package lang
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestWarnings(t *testing.T) {
+	code := "module m(input a, output y);\n  assign y = a;\nendmodule\n\nmodule top;\n  m omitted(.y());\nendmodule\n"
+	tokens, err := NewVLexer(zap.NewNop()).Lex(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ast, err := NewParser().ParseFile(tokens)
+	if err != nil {
+		t.Fatal(err)
+	}
+	modules := map[string][]ModuleNode{"m.v": {*ast.Statements[0].Module}}
+
+	tests := []struct {
+		warnings Warnings
+		expected []string
+	}{
+		{DefaultWarnings, []string{"Input a of module m is not connected"}},
+		{Warnings{UnconnectedOutputs: true}, []string{"Output y of module m is not connected"}},
+		{Warnings{UnconnectedInputs: true, UnconnectedOutputs: true}, []string{"Output y of module m is not connected", "Input a of module m is not connected"}},
+		{Warnings{}, []string{}},
+	}
+	for _, test := range tests {
+		interpreter := NewInterpreter(zap.NewNop(), modules, map[string][]InterfaceNode{}, map[string][]PackageNode{}, map[string][]DefineNode{})
+		interpreter.Warnings = test.warnings
+		diagnostics := interpreter.Interpret(ast)
+		if len(diagnostics) != len(test.expected) {
+			t.Errorf("with %+v, expected %d diagnostics, got %v", test.warnings, len(test.expected), diagnostics)
+			continue
+		}
+		for k, diagnostic := range diagnostics {
+			if diagnostic.Message != test.expected[k] {
+				t.Errorf("with %+v, diagnostic %d is %q, expected %q", test.warnings, k, diagnostic.Message, test.expected[k])
+			}
+		}
+	}
+}
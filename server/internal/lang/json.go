@@ -0,0 +1,289 @@
+package lang
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ASTVersion is the version of the JSON encoding of tokens and ASTs.
+// It changes whenever the encoding does, so that scripts can tell
+// whether they understand a document
+const ASTVersion = 2
+
+// The JSON encoding is a document of the form {"version": 2, "tokens": [...]}
+// or {"version": 2, "ast": {...}}.
+//
+// A token is an object with its kind (see TokenKind.String), value, byte offset,
+// 0-indexed line, and start and end characters in UTF-16 code units.
+// String literals also have their decoded value, which is ignored when decoding.
+//
+// A node is an object whose "kind" is the name of its type, ie "ModuleNode",
+// followed by its fields in declaration order with the first letter lowercased,
+// so ModuleNode.PortList is "portList". Fields that are nil, empty, or zero are
+// left out. Every node has a span, and the nodes that can be documented
+// have their comments in "comments" as leading and trailing tokens
+
+type tokensDocument struct {
+	Version int             `json:"version"`
+	Tokens  json.RawMessage `json:"tokens"`
+}
+
+type astDocument struct {
+	Version int             `json:"version"`
+	AST     json.RawMessage `json:"ast"`
+}
+
+type tokenJSON struct {
+	Kind    TokenKind `json:"kind"`
+	Value   string    `json:"value"`
+	Offset  int       `json:"offset"`
+	Line    int       `json:"line"`
+	Start   int       `json:"start"`
+	End     int       `json:"end"`
+	Decoded string    `json:"decoded,omitempty"`
+}
+
+var kindsByString = func() map[string]TokenKind {
+	result := map[string]TokenKind{}
+	for kind, s := range kindStrings {
+		if s != "" {
+			result[s] = TokenKind(kind)
+		}
+	}
+	return result
+}()
+
+func (k TokenKind) MarshalText() ([]byte, error) {
+	if k.String() == "unknown" {
+		return nil, fmt.Errorf("unknown token kind %d", int(k))
+	}
+	return []byte(k.String()), nil
+}
+
+func (k *TokenKind) UnmarshalText(text []byte) error {
+	kind, ok := kindsByString[string(text)]
+	if !ok {
+		return fmt.Errorf("unknown token kind %q", text)
+	}
+	*k = kind
+	return nil
+}
+
+func (t Token) MarshalJSON() ([]byte, error) {
+	return json.Marshal(tokenJSON{
+		Kind:    t.Type,
+		Value:   t.Value,
+		Offset:  t.Offset(),
+		Line:    t.Line(),
+		Start:   t.StartCharacter(),
+		End:     t.EndCharacter(),
+		Decoded: t.Decoded(),
+	})
+}
+
+func (t *Token) UnmarshalJSON(data []byte) error {
+	var token tokenJSON
+	if err := json.Unmarshal(data, &token); err != nil {
+		return err
+	}
+	*t = Token{
+		Type:           token.Kind,
+		Value:          token.Value,
+		offset:         int32(token.Offset),
+		line:           int32(token.Line),
+		startCharacter: int32(token.Start),
+		endCharacter:   int32(token.End),
+	}
+	return nil
+}
+
+// MarshalTokens returns the JSON encoding of the tokens
+func MarshalTokens(tokens []Token) ([]byte, error) {
+	if tokens == nil {
+		tokens = []Token{}
+	}
+	encoded, err := json.Marshal(tokens)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(tokensDocument{Version: ASTVersion, Tokens: encoded})
+}
+
+// UnmarshalTokens decodes tokens encoded by MarshalTokens
+func UnmarshalTokens(data []byte) ([]Token, error) {
+	var document tokensDocument
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+	if document.Version != ASTVersion {
+		return nil, fmt.Errorf("unsupported version %d, expected %d", document.Version, ASTVersion)
+	}
+	var tokens []Token
+	err := json.Unmarshal(document.Tokens, &tokens)
+	return tokens, err
+}
+
+// MarshalAST returns the JSON encoding of the ast
+func MarshalAST(ast FileNode) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if err := encodeNode(buf, reflect.ValueOf(ast)); err != nil {
+		return nil, err
+	}
+	return json.Marshal(astDocument{Version: ASTVersion, AST: buf.Bytes()})
+}
+
+// UnmarshalAST decodes an ast encoded by MarshalAST
+func UnmarshalAST(data []byte) (FileNode, error) {
+	var document astDocument
+	if err := json.Unmarshal(data, &document); err != nil {
+		return FileNode{}, err
+	}
+	if document.Version != ASTVersion {
+		return FileNode{}, fmt.Errorf("unsupported version %d, expected %d", document.Version, ASTVersion)
+	}
+	var result FileNode
+	err := decodeNode(document.AST, reflect.ValueOf(&result).Elem())
+	return result, err
+}
+
+var tokenType = reflect.TypeOf(Token{})
+
+// nodes are the structs with a span, which get tagged with their kind
+func isNode(t reflect.Type) bool {
+	_, ok := t.FieldByName("Span")
+	return ok
+}
+
+// the name of a field in JSON, ie portList for PortList
+func fieldName(name string) string {
+	r, size := utf8.DecodeRuneInString(name)
+	return string(unicode.ToLower(r)) + name[size:]
+}
+
+// whether a field is left out of the encoding
+func omitted(v reflect.Value) bool {
+	if v.Kind() == reflect.Slice {
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+func encodeNode(buf *bytes.Buffer, v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Ptr:
+		return encodeNode(buf, v.Elem())
+	case reflect.Slice:
+		buf.WriteByte('[')
+		for i := 0; i < v.Len(); i++ {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := encodeNode(buf, v.Index(i)); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+		return nil
+	case reflect.Struct:
+		if v.Type() == tokenType {
+			break
+		}
+		buf.WriteByte('{')
+		first := true
+		if isNode(v.Type()) {
+			fmt.Fprintf(buf, `"kind":%q`, v.Type().Name())
+			first = false
+		}
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() || omitted(v.Field(i)) {
+				continue
+			}
+			if !first {
+				buf.WriteByte(',')
+			}
+			first = false
+			fmt.Fprintf(buf, "%q:", fieldName(field.Name))
+			if err := encodeNode(buf, v.Field(i)); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+		return nil
+	}
+
+	// tokens and plain values encode themselves
+	encoded, err := json.Marshal(v.Interface())
+	if err != nil {
+		return err
+	}
+	buf.Write(encoded)
+	return nil
+}
+
+func decodeNode(data json.RawMessage, v reflect.Value) error {
+	if string(data) == "null" {
+		return nil
+	}
+	switch v.Kind() {
+	case reflect.Ptr:
+		v.Set(reflect.New(v.Type().Elem()))
+		return decodeNode(data, v.Elem())
+	case reflect.Slice:
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return err
+		}
+		slice := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := decodeNode(item, slice.Index(i)); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+		return nil
+	case reflect.Struct:
+		if v.Type() == tokenType {
+			break
+		}
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return err
+		}
+		if isNode(v.Type()) {
+			var kind string
+			if err := json.Unmarshal(fields["kind"], &kind); err != nil || kind != v.Type().Name() {
+				return fmt.Errorf("expected a %s, got %s", v.Type().Name(), fields["kind"])
+			}
+			delete(fields, "kind")
+		}
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			value, ok := fields[fieldName(field.Name)]
+			if !field.IsExported() || !ok {
+				continue
+			}
+			if err := decodeNode(value, v.Field(i)); err != nil {
+				return err
+			}
+			delete(fields, fieldName(field.Name))
+		}
+		if len(fields) > 0 {
+			unknown := []string{}
+			for name := range fields {
+				unknown = append(unknown, name)
+			}
+			sort.Strings(unknown)
+			return fmt.Errorf("unknown fields of %s: %v", v.Type().Name(), unknown)
+		}
+		return nil
+	}
+
+	// tokens and plain values decode themselves
+	return json.Unmarshal(data, v.Addr().Interface())
+}
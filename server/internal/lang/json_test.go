@@ -0,0 +1,134 @@
+package lang
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+var update = flag.Bool("update", false, "rewrite golden files with the current output")
+
+// checkGolden compares the output to the golden file at path,
+// or rewrites the golden file when -update is passed
+func checkGolden(t *testing.T, path string, output []byte) {
+	t.Helper()
+	if *update {
+		if err := os.WriteFile(path, output, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v, run with -update to create it", err)
+	}
+	if !bytes.Equal(expected, output) {
+		t.Errorf("output doesn't match %s, run with -update if the change is intended", path)
+	}
+}
+
+// lexAndParse lexes and parses a file, picking the language from its extension
+func lexAndParse(t *testing.T, path string) ([]Token, FileNode) {
+	t.Helper()
+	code, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lexer, parser := NewVLexer(zap.NewNop()), NewParser()
+	if strings.HasSuffix(path, ".sv") {
+		lexer, parser = NewSVLexer(zap.NewNop()), NewSVParser()
+	}
+	tokens, err := lexer.Lex(string(code))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ast, err := parser.ParseFile(tokens)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tokens, ast
+}
+
+func indent(t *testing.T, data []byte) []byte {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	if err := json.Indent(buf, data, "", "  "); err != nil {
+		t.Fatal(err)
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes()
+}
+
+func TestJSONGolden(t *testing.T) {
+	inputs, err := filepath.Glob(filepath.Join("testdata", "json", "*.*v"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, input := range inputs {
+		t.Run(filepath.Base(input), func(t *testing.T) {
+			tokens, ast := lexAndParse(t, input)
+
+			encodedTokens, err := MarshalTokens(tokens)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, input+".tokens.json", indent(t, encodedTokens))
+			decodedTokens, err := UnmarshalTokens(encodedTokens)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(decodedTokens) != len(tokens) {
+				t.Fatalf("decoded %d tokens, expected %d", len(decodedTokens), len(tokens))
+			}
+			for i := range tokens {
+				if decodedTokens[i] != tokens[i] {
+					t.Errorf("token %d decoded as %+v, expected %+v", i, decodedTokens[i], tokens[i])
+				}
+			}
+
+			encodedAST, err := MarshalAST(ast)
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, input+".ast.json", indent(t, encodedAST))
+			decodedAST, err := UnmarshalAST(encodedAST)
+			if err != nil {
+				t.Fatal(err)
+			}
+			reencoded, err := MarshalAST(decodedAST)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(reencoded, encodedAST) {
+				t.Errorf("ast doesn't survive a round trip through json")
+			}
+		})
+	}
+}
+
+func TestUnmarshalASTErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		expected string
+	}{
+		{"version", `{"version": 1, "ast": {"kind": "FileNode"}}`, "unsupported version 1"},
+		{"kind", `{"version": 2, "ast": {"kind": "ModuleNode"}}`, "expected a FileNode"},
+		{"field", `{"version": 2, "ast": {"kind": "FileNode", "modules": []}}`, "unknown fields of FileNode: [modules]"},
+		{"token kind", `{"version": 2, "ast": {"kind": "FileNode", "span": {"start": {"kind": "nope"}}}}`, `unknown token kind "nope"`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := UnmarshalAST([]byte(test.document))
+			if err == nil || !strings.Contains(err.Error(), test.expected) {
+				t.Errorf("expected an error containing %q, got %v", test.expected, err)
+			}
+		})
+	}
+}
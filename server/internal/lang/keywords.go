@@ -1,29 +1,25 @@
 package lang
 
-var Keywords = []string{
-	"endmodule",
-	"begin",
-	"end",
-	"endcase",
-	"endgenerate",
-	"assign",
-	"initial",
-	"negedge",
-	"posedge",
-	"default",
-	"endtask",
+// Directives are the names of the compiler directives, without their backticks.
+// Reserved words are in reserved.go
+var Directives = []string{
 	"include",
 	"define",
 	"timescale",
-	"wire",
-	"reg",
-	"genvar",
-	"parameter",
-	"integer",
-	"input",
-	"output",
-	"inout",
-	"defparam",
+	"undef",
+	"default_nettype",
+	"resetall",
+	"celldefine",
+	"endcelldefine",
+	"ifdef",
+	"ifndef",
+	"elsif",
+	"endif",
+	"pragma",
+	"unconnected_drive",
+	"nounconnected_drive",
+	"begin_keywords",
+	"end_keywords",
 }
 var Snippets = map[string]string{
 	"module":   "module $1();\nendmodule",
@@ -47,3 +43,16 @@ var Snippets = map[string]string{
 	"notif0":   "notif0 ${1:name}(${2:a}, ${3:b}, ${4:c});",
 	"notif1":   "notif1 ${1:name}(${2:a}, ${3:b}, ${4:c});",
 }
+
+// SVSnippets are the extra snippets of the SystemVerilog design subset
+var SVSnippets = map[string]string{
+	"always_ff":    "always_ff @(posedge $1) begin\nend",
+	"always_comb":  "always_comb begin\nend",
+	"always_latch": "always_latch begin\nend",
+	"interface":    "interface $1;\nendinterface",
+	"modport":      "modport $1($2);",
+	"package":      "package $1;\nendpackage",
+	"import":       "import $1::*;",
+	"enum":         "typedef enum {$2} $1;",
+	"struct":       "typedef struct packed {\n$2\n} $1;",
+}
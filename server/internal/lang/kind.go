@@ -0,0 +1,301 @@
+package lang
+
+import "sort"
+
+//go:generate go run gen_reserved.go
+
+// TokenKind is the kind of a token
+type TokenKind int
+
+const (
+	ILLEGAL TokenKind = iota
+	EOF
+
+	// trivia
+	WHITESPACE
+	NEWLINE
+	COMMENT
+
+	IDENTIFIER
+	LITERAL
+	FUNCLITERAL // system functions that return values, ie $time
+	SIGNED      // $signed and $unsigned
+
+	// operators and punctuation
+	INCREMENT
+	COMPARATOR
+	OPERATOR
+	TILDE
+	LPAREN
+	RPAREN
+	LBRACKET
+	RBRACKET
+	LCURL
+	RCURL
+	SCOPE
+	COLON
+	COMMA
+	DOT
+	SEMICOLON
+	QUESTION
+	AT
+	EQUAL
+	POUND
+	DOLLAR
+
+	directive_beg
+	INCLUDE
+	DEFINE
+	TIMESCALE
+	UNDEF
+	DEFAULT_NETTYPE
+	DIRECTIVE // the rest of the directives, which only span the rest of their line
+	directive_end
+
+	keyword_beg
+	MODULE
+	ENDMODULE
+	BEGIN
+	END
+	CASE
+	ENDCASE
+	GENERATE
+	ENDGENERATE
+	FOR
+	IF
+	ELSE
+	ASSIGN
+	INITIAL
+	ALWAYS
+	EDGE
+	DEFAULT
+	TASK
+	ENDTASK
+	INTERFACE
+	ENDINTERFACE
+	MODPORT
+	PACKAGE
+	ENDPACKAGE
+	IMPORT
+	TYPEDEF
+	ENUM
+	STRUCT
+	PACKED
+	TYPE
+	DIRECTION
+	DEFPARAM
+	GATE
+	OR
+	KEYWORD // reserved words that don't have a kind of their own
+	keyword_end
+
+	// kinds that the lexer never produces, but that later passes assign
+	EXISTING_MODULE
+	PORT
+	FORMAT_STRING
+)
+
+var kindStrings = [...]string{
+	ILLEGAL:         "illegal",
+	EOF:             "EOF",
+	WHITESPACE:      "whitespace",
+	NEWLINE:         "newline",
+	COMMENT:         "comment",
+	IDENTIFIER:      "identifier",
+	LITERAL:         "literal",
+	FUNCLITERAL:     "funcliteral",
+	SIGNED:          "signed",
+	INCREMENT:       "increment",
+	COMPARATOR:      "comparator",
+	OPERATOR:        "operator",
+	TILDE:           "tilde",
+	LPAREN:          "lparen",
+	RPAREN:          "rparen",
+	LBRACKET:        "lbracket",
+	RBRACKET:        "rbracket",
+	LCURL:           "lcurl",
+	RCURL:           "rcurl",
+	SCOPE:           "scope",
+	COLON:           "colon",
+	COMMA:           "comma",
+	DOT:             "dot",
+	SEMICOLON:       "semicolon",
+	QUESTION:        "question",
+	AT:              "at",
+	EQUAL:           "equal",
+	POUND:           "pound",
+	DOLLAR:          "dollar",
+	INCLUDE:         "include",
+	DEFINE:          "define",
+	TIMESCALE:       "timescale",
+	UNDEF:           "undef",
+	DEFAULT_NETTYPE: "default_nettype",
+	DIRECTIVE:       "directive",
+	MODULE:          "module",
+	ENDMODULE:       "endmodule",
+	BEGIN:           "begin",
+	END:             "end",
+	CASE:            "case",
+	ENDCASE:         "endcase",
+	GENERATE:        "generate",
+	ENDGENERATE:     "endgenerate",
+	FOR:             "for",
+	IF:              "if",
+	ELSE:            "else",
+	ASSIGN:          "assign",
+	INITIAL:         "initial",
+	ALWAYS:          "always",
+	EDGE:            "edge",
+	DEFAULT:         "default",
+	TASK:            "task",
+	ENDTASK:         "endtask",
+	INTERFACE:       "interface",
+	ENDINTERFACE:    "endinterface",
+	MODPORT:         "modport",
+	PACKAGE:         "package",
+	ENDPACKAGE:      "endpackage",
+	IMPORT:          "import",
+	TYPEDEF:         "typedef",
+	ENUM:            "enum",
+	STRUCT:          "struct",
+	PACKED:          "packed",
+	TYPE:            "type",
+	DIRECTION:       "direction",
+	DEFPARAM:        "defparam",
+	GATE:            "gate",
+	OR:              "or",
+	KEYWORD:         "keyword",
+	EXISTING_MODULE: "existing_module",
+	PORT:            "port",
+	FORMAT_STRING:   "format_string",
+}
+
+func (k TokenKind) String() string {
+	if k >= 0 && int(k) < len(kindStrings) && kindStrings[k] != "" {
+		return kindStrings[k]
+	}
+	return "unknown"
+}
+
+// IsKeyword returns whether tokens of this kind are reserved words
+func (k TokenKind) IsKeyword() bool {
+	return k > keyword_beg && k < keyword_end
+}
+
+// IsDirective returns whether tokens of this kind are compiler directives
+func (k TokenKind) IsDirective() bool {
+	return k > directive_beg && k < directive_end
+}
+
+// the kinds of the reserved words that the parser cares about;
+// every other reserved word is just a KEYWORD
+var keywordKinds = map[string]TokenKind{
+	"module":       MODULE,
+	"macromodule":  MODULE,
+	"endmodule":    ENDMODULE,
+	"begin":        BEGIN,
+	"end":          END,
+	"case":         CASE,
+	"casex":        CASE,
+	"casez":        CASE,
+	"endcase":      ENDCASE,
+	"generate":     GENERATE,
+	"endgenerate":  ENDGENERATE,
+	"for":          FOR,
+	"if":           IF,
+	"else":         ELSE,
+	"assign":       ASSIGN,
+	"initial":      INITIAL,
+	"always":       ALWAYS,
+	"always_ff":    ALWAYS,
+	"always_comb":  ALWAYS,
+	"always_latch": ALWAYS,
+	"posedge":      EDGE,
+	"negedge":      EDGE,
+	"edge":         EDGE,
+	"default":      DEFAULT,
+	"task":         TASK,
+	"endtask":      ENDTASK,
+	"interface":    INTERFACE,
+	"endinterface": ENDINTERFACE,
+	"modport":      MODPORT,
+	"package":      PACKAGE,
+	"endpackage":   ENDPACKAGE,
+	"import":       IMPORT,
+	"typedef":      TYPEDEF,
+	"enum":         ENUM,
+	"struct":       STRUCT,
+	"packed":       PACKED,
+	// variables
+	"reg":        TYPE,
+	"integer":    TYPE,
+	"real":       TYPE,
+	"realtime":   TYPE,
+	"time":       TYPE,
+	"genvar":     TYPE,
+	"parameter":  TYPE,
+	"localparam": TYPE,
+	"logic":      TYPE,
+	"bit":        TYPE,
+	"byte":       TYPE,
+	"shortint":   TYPE,
+	"int":        TYPE,
+	"longint":    TYPE,
+	// nets
+	"wire":    TYPE,
+	"tri":     TYPE,
+	"tri0":    TYPE,
+	"tri1":    TYPE,
+	"wand":    TYPE,
+	"triand":  TYPE,
+	"wor":     TYPE,
+	"trior":   TYPE,
+	"trireg":  TYPE,
+	"uwire":   TYPE,
+	"supply0": TYPE,
+	"supply1": TYPE,
+	// ports
+	"input":    DIRECTION,
+	"output":   DIRECTION,
+	"inout":    DIRECTION,
+	"defparam": DEFPARAM,
+	// gates, which are applied like modules
+	"and":    GATE,
+	"nand":   GATE,
+	"nor":    GATE,
+	"xor":    GATE,
+	"xnor":   GATE,
+	"buf":    GATE,
+	"not":    GATE,
+	"bufif0": GATE,
+	"bufif1": GATE,
+	"notif0": GATE,
+	"notif1": GATE,
+	// or is also how events are combined
+	"or": OR,
+}
+
+// KeywordKind returns the kind of a word in the given language:
+// a keyword kind if it's reserved, or IDENTIFIER otherwise
+func KeywordKind(word string, language Language) TokenKind {
+	reservedIn, ok := reservedWords[word]
+	if !ok || reservedIn > language {
+		return IDENTIFIER
+	}
+	if kind, ok := keywordKinds[word]; ok {
+		return kind
+	}
+	return KEYWORD
+}
+
+// ReservedWords returns all the reserved words of the given language
+func ReservedWords(language Language) []string {
+	result := []string{}
+	for word, reservedIn := range reservedWords {
+		if reservedIn <= language {
+			result = append(result, word)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
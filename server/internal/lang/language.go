@@ -0,0 +1,16 @@
+package lang
+
+// Language is the HDL that the lexer and parser accept
+type Language int
+
+const (
+	Verilog       Language = iota // IEEE 1364-2005
+	SystemVerilog                 // the synthesizable subset of IEEE 1800
+)
+
+func (l Language) String() string {
+	if l == SystemVerilog {
+		return "SystemVerilog"
+	}
+	return "Verilog"
+}
@@ -1,37 +1,67 @@
 package lang
 
 import (
-	"errors"
 	"regexp"
+	"strings"
+	"unicode/utf8"
 
 	"go.uber.org/zap"
 )
 
-// Token represents a token
+// Token represents a token. Its positions are int32s since
+// there's one for every few bytes of code, which keeps it small
 type Token struct {
-	Type           string
+	Type           TokenKind
 	Value          string
-	line           int
-	startCharacter int
-	endCharacter   int
+	offset         int32
+	line           int32
+	startCharacter int32
+	endCharacter   int32
 }
 
-// Returns the start character, inclusive, of this token
-// character is 0-indexed
+// Returns the byte offset of the start of this token
+// into the code it was lexed from
+func (t Token) Offset() int {
+	return int(t.offset)
+}
+
+// Returns the start character, inclusive, of this token.
+// character is 0-indexed and counted in UTF-16 code units, like LSP positions
 func (t Token) StartCharacter() int {
-	return t.startCharacter
+	return int(t.startCharacter)
 }
 
-// Returns the end character, inclusive, of this token.
-// character is 0-indexed
+// Returns the end character, exclusive, of this token.
+// character is 0-indexed and counted in UTF-16 code units, like LSP positions
 func (t Token) EndCharacter() int {
-	return t.endCharacter
+	return int(t.endCharacter)
 }
 
 // Returns the line number of this token.
 // Line is 0-indexed
 func (t Token) Line() int {
-	return t.line
+	return int(t.line)
+}
+
+// Returns the name of this token, which is what symbols are looked up by.
+// Escaped identifiers name the same thing as their plain spelling,
+// ie \cpu3 and cpu3, so the backslash is dropped; Value keeps the original spelling
+func (t Token) Name() string {
+	if t.Type == IDENTIFIER && strings.HasPrefix(t.Value, `\`) {
+		return t.Value[1:]
+	}
+	return t.Value
+}
+
+var simpleIdentifierRegex = regexp.MustCompile(`^[A-Za-z_][a-zA-Z0-9_$]*$`)
+
+// Spelling returns how a name has to be written in source, which
+// is escaped if it isn't a simple identifier. It's the inverse of Token.Name
+func Spelling(name string) string {
+	if simpleIdentifierRegex.MatchString(name) {
+		return name
+	}
+	return `\` + name + " "
 }
 
 // Lexer is a lexer
@@ -59,26 +89,25 @@ func (l *Lexer) AddMapping(pattern *regexp.Regexp, mapper func(string) ([]Token,
 
 // helper to make adding a mapping easier when you don't need to capture
 // the value
-func (l *Lexer) AddMappingNoCapture(pattern *regexp.Regexp, Type string) {
+func (l *Lexer) AddMappingNoCapture(pattern *regexp.Regexp, Type TokenKind) {
 	l.AddMapping(pattern, func(code string) ([]Token, error) {
 		return []Token{{Type: Type, Value: code}}, nil
 	})
 }
 
 // Lex the code, returning a list of tokens found in the code,
-// or an error if something went wrong
+// or an error if something went wrong. Characters that no mapping
+// accepts become ILLEGAL tokens, and lexing continues after them
 func (l *Lexer) Lex(code string) ([]Token, error) {
 	tokens := []Token{}
 	line := 0
-	lineStart := 0
+	character := 0
 	for i := 0; i < len(code); {
 		// figure out which of the tokens will consume
 		// the most characters, and match that token
 		// with the code
 		maxLength := 0
-		f := func(_ string) ([]Token, error) {
-			return []Token{}, errors.New("no token found")
-		}
+		var f func(string) ([]Token, error)
 
 		// enforce order of precedence (mappings inserted first take precedence)
 		for j := 0; j < len(l.funcs); j++ {
@@ -89,11 +118,12 @@ func (l *Lexer) Lex(code string) ([]Token, error) {
 			}
 		}
 
-		// if no token was found
+		// if no token was found, the next character is an error
 		if maxLength == 0 {
-			l.logger.Sugar().Errorf("no token found!! Still have code: ", code[i:])
-			l.logger.Sugar().Errorf("current char: ", code[i])
-			break
+			_, maxLength = utf8.DecodeRuneInString(code[i:])
+			f = func(code string) ([]Token, error) {
+				return []Token{{Type: ILLEGAL, Value: code}}, nil
+			}
 		}
 
 		// now, match the token with the code
@@ -103,17 +133,18 @@ func (l *Lexer) Lex(code string) ([]Token, error) {
 		}
 		curPos := i
 		for _, t := range ts {
-			t.startCharacter = curPos - lineStart
-			t.endCharacter = curPos + len(t.Value) - lineStart
-			t.line = line
+			width := UTF16Len(t.Value)
+			t.offset = int32(curPos)
+			t.startCharacter = int32(character)
+			t.endCharacter = int32(character + width)
+			t.line = int32(line)
 			tokens = append(tokens, t)
 
-			// update line info and i
-			for j := 0; j < len(t.Value); j++ {
-				if code[curPos+j] == '\n' {
-					line++
-					lineStart = curPos + j + 1
-				}
+			// update line info
+			character += width
+			if newline := strings.LastIndexByte(t.Value, '\n'); newline >= 0 {
+				line += strings.Count(t.Value, "\n")
+				character = UTF16Len(t.Value[newline+1:])
 			}
 			curPos += len(t.Value)
 		}
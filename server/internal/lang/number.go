@@ -0,0 +1,165 @@
+package lang
+
+import (
+	"errors"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// the IEEE 1364-2005 number grammar, section 3.5.1
+const (
+	sizePattern     = `[1-9][0-9_]*`
+	decimalPattern  = `'[sS]?[dD][ \t]*(([0-9][0-9_]*)|([xXzZ?]_*))`
+	binaryPattern   = `'[sS]?[bB][ \t]*[01xXzZ?][01xXzZ?_]*`
+	octalPattern    = `'[sS]?[oO][ \t]*[0-7xXzZ?][0-7xXzZ?_]*`
+	hexPattern      = `'[sS]?[hH][ \t]*[0-9a-fA-FxXzZ?][0-9a-fA-FxXzZ?_]*`
+	unsignedPattern = `[0-9][0-9_]*`
+	realPattern     = `[0-9][0-9_]*(((\.[0-9][0-9_]*)?[eE][+-]?[0-9][0-9_]*)|(\.[0-9][0-9_]*))`
+	numberPattern   = `((` + sizePattern + `[ \t]*)?(` + decimalPattern + `|` + binaryPattern + `|` + octalPattern + `|` + hexPattern + `))|(` + realPattern + `)|(` + unsignedPattern + `)`
+)
+
+var numberRegex = regexp.MustCompile(`^(` + numberPattern + `)$`)
+
+// Number is the structured form of a numeric literal
+type Number struct {
+	Size      int    // number of bits the literal was given, or 0 if it's unsized
+	Signed    bool   // whether the literal is signed, ie 8'sh7F or a plain decimal
+	Base      int    // 2, 8, 10, or 16
+	Bits      string // bit pattern, most significant bit first, made of 0, 1, x, and z
+	Truncated bool   // whether bits that weren't 0 had to be dropped to fit the size
+	Fill      bool   // whether it's an unbased unsized literal like '1, which fills its context
+	Real      bool   // whether it's a real number, in which case only RealValue is meaningful
+	RealValue float64
+}
+
+// Width returns the number of bits of the literal
+func (n Number) Width() int {
+	if n.Real {
+		return 64
+	}
+	return len(n.Bits)
+}
+
+// Uint64 returns the value of the literal, and false if it
+// has x or z bits or doesn't fit in 64 bits
+func (n Number) Uint64() (uint64, bool) {
+	if n.Real || strings.ContainsAny(n.Bits, "xz") {
+		return 0, false
+	}
+	value, ok := new(big.Int).SetString(n.Bits, 2)
+	if !ok || !value.IsUint64() {
+		return 0, false
+	}
+	return value.Uint64(), true
+}
+
+// returns the bits that a single digit of the given base stands for
+func digitBits(digit byte, base int) string {
+	width := map[int]int{2: 1, 8: 3, 16: 4}[base]
+	switch digit {
+	case 'x', 'X':
+		return strings.Repeat("x", width)
+	case 'z', 'Z', '?':
+		return strings.Repeat("z", width)
+	}
+	value, _ := strconv.ParseUint(string(digit), base, 8)
+	bits := strconv.FormatUint(value, 2)
+	return strings.Repeat("0", width-len(bits)) + bits
+}
+
+// resizes a bit pattern, extending with x or z if that's the leftmost bit
+// and with 0 otherwise
+func resizeBits(bits string, size int) (result string, truncated bool) {
+	if len(bits) > size {
+		dropped := bits[:len(bits)-size]
+		return bits[len(bits)-size:], strings.Trim(dropped, "0") != ""
+	}
+	fill := "0"
+	if bits[0] == 'x' || bits[0] == 'z' {
+		fill = bits[:1]
+	}
+	return strings.Repeat(fill, size-len(bits)) + bits, false
+}
+
+// ParseNumber parses the value of a numeric literal token into a Number
+func ParseNumber(literal string) (result Number, err error) {
+	// unbased unsized literals fill whatever they're in
+	if len(literal) == 2 && literal[0] == '\'' && strings.ContainsRune("01xXzZ", rune(literal[1])) {
+		result.Fill = true
+		result.Bits = strings.ToLower(literal[1:])
+		return
+	}
+	if !numberRegex.MatchString(literal) {
+		err = errors.New("not a number: " + literal)
+		return
+	}
+	literal = strings.ReplaceAll(literal, "_", "")
+
+	// real numbers
+	if strings.ContainsAny(literal, ".eE") && !strings.Contains(literal, "'") {
+		result.Real = true
+		result.Signed = true
+		result.Base = 10
+		result.RealValue, err = strconv.ParseFloat(literal, 64)
+		return
+	}
+
+	// plain decimals are signed and unsized
+	digits := literal
+	result.Base = 10
+	result.Signed = true
+	if tick := strings.IndexByte(literal, '\''); tick >= 0 {
+		if size := strings.TrimSpace(literal[:tick]); size != "" {
+			result.Size, err = strconv.Atoi(size)
+			if err != nil {
+				return
+			}
+		}
+		base := strings.ToLower(literal[tick+1:])
+		result.Signed = base[0] == 's'
+		base = strings.TrimPrefix(base, "s")
+		result.Base = map[byte]int{'b': 2, 'o': 8, 'd': 10, 'h': 16}[base[0]]
+		digits = strings.TrimSpace(base[1:])
+	}
+
+	// get the bits
+	if result.Base == 10 {
+		switch digits[0] {
+		case 'x', 'X':
+			result.Bits = "x"
+		case 'z', 'Z', '?':
+			result.Bits = "z"
+		default:
+			value, ok := new(big.Int).SetString(digits, 10)
+			if !ok {
+				err = errors.New("not a decimal number: " + digits)
+				return
+			}
+			result.Bits = value.Text(2)
+		}
+	} else {
+		bits := strings.Builder{}
+		for i := 0; i < len(digits); i++ {
+			bits.WriteString(digitBits(digits[i], result.Base))
+		}
+		result.Bits = bits.String()
+	}
+
+	// unsized numbers are at least 32 bits
+	size := result.Size
+	if size == 0 {
+		size = 32
+		if len(result.Bits) > size {
+			size = len(result.Bits)
+		}
+	}
+	result.Bits, result.Truncated = resizeBits(result.Bits, size)
+	return
+}
+
+// Number parses a numeric literal token into a Number
+func (t Token) Number() (Number, error) {
+	return ParseNumber(t.Value)
+}
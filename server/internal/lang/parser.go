@@ -5,11 +5,21 @@ import (
 )
 
 type FileNode struct {
-	Statements []TopLevelStatement
+	Statements     []TopLevelStatement
+	NettypeRegions []NettypeRegion // regions of the file that have a `default_nettype
+	Span           Span
+}
+type NettypeRegion struct {
+	Start   Token  // the directive that starts this region
+	Nettype string // the net type of implicit nets in this region, ie wire or none
 }
 type TopLevelStatement struct {
 	Directive *DirectiveNode
 	Module    *ModuleNode
+	Interface *InterfaceNode
+	Package   *PackageNode
+	Import    *ImportNode
+	Span      Span
 }
 type InteriorNode struct {
 	DeclarationNode       *DeclarationNode
@@ -19,102 +29,229 @@ type InteriorNode struct {
 	AlwaysNode            *AlwaysNode
 	DefParamNode          *DefParamNode
 	InitialNode           *InitialNode
-	DirectiveNode         *DefineNode
+	DirectiveNode         *DirectiveNode
 	TaskNode              *TaskNode
+	ModportNode           *ModportNode // only inside interfaces
+	ImportNode            *ImportNode
+	TypedefNode           *TypedefNode
+	Span                  Span
 }
 type ModuleNode struct {
 	Identifier Token        // name of module
+	Imports    []ImportNode // imports in the module header
 	PortList   PortListNode // list of ports
 	Interior   []InteriorNode
+	Comments   Trivia // comments that document the module
+	Span       Span
 }
 type PortListNode struct {
-	Ports []Token // list of ports (identifiers)
+	Ports        []Token    // list of ports (identifiers)
+	Declarations []PortNode // one per port if the ports are declared ANSI-style, otherwise empty
+	Span         Span
+}
+type PortNode struct {
+	Identifier Token
+	Type       *TypeNode          // direction and type of a regular port, could be nil
+	Interface  *InterfacePortNode // interface of an interface port, could be nil
+	Comments   Trivia
+	Span       Span
+}
+type InterfacePortNode struct {
+	Interface Token  // name of the interface
+	Modport   *Token // name of the modport, could be nil
+	Span      Span
+}
+type InterfaceNode struct {
+	Identifier Token        // name of interface
+	PortList   PortListNode // list of ports
+	Interior   []InteriorNode
+	Comments   Trivia
+	Span       Span
+}
+type PackageNode struct {
+	Identifier Token // name of package
+	Interior   []InteriorNode
+	Comments   Trivia
+	Span       Span
+}
+type ImportNode struct {
+	Items []ImportItemNode
+	Span  Span
+}
+type ImportItemNode struct {
+	Package Token // name of the package
+	Item    Token // name of the imported item, or * for everything
+	Span    Span
+}
+type TypedefNode struct {
+	Identifier Token       // name of the new type
+	Type       *TypeNode   // the aliased type, could be nil
+	Enum       *EnumNode   // could be nil
+	Struct     *StructNode // could be nil
+	Comments   Trivia
+	Span       Span
+}
+type EnumNode struct {
+	Type    *TypeNode // base type, could be nil
+	Members []EnumMemberNode
+	Span    Span
+}
+type EnumMemberNode struct {
+	Identifier Token
+	Value      *ExprNode // could be nil
+	Comments   Trivia
+	Span       Span
+}
+type StructNode struct {
+	Packed  bool
+	Members []DeclarationNode
+	Span    Span
+}
+type ModportNode struct {
+	Identifier Token // name of the modport
+	Ports      []ModportPortNode
+	Comments   Trivia
+	Span       Span
+}
+type ModportPortNode struct {
+	Direction  Token // input, output, or inout
+	Identifier Token // name of the interface signal
+	Span       Span
 }
 type DefineNode struct {
 	Identifier Token // name of the define
+	Comments   Trivia
+	Span       Span
+}
+type UndefNode struct {
+	Identifier Token // name of the macro to undefine
+	Span       Span
+}
+type DefaultNettypeNode struct {
+	Nettype Token // wire, tri, none, etc.
+	Span    Span
 }
 type DirectiveNode struct {
-	DefineNode *DefineNode
+	Directive          Token   // the directive itself, ie `define
+	Arguments          []Token // the rest of the directive's line, ie the name and body of a define
+	DefineNode         *DefineNode
+	UndefNode          *UndefNode
+	DefaultNettypeNode *DefaultNettypeNode
+	Span               Span
 }
 type AssignmentNode struct {
 	Variables       []AssignmentVariableNode
 	Value           ExprNode
 	IsAssign        bool
-	IsDelayedAssign bool // true if used <= instead of =
+	IsDelayedAssign bool   // true if used <= instead of =
+	Increment       *Token // ++ or -- if this is an increment, in which case there is no value
+	Span            Span
 }
 type AssignmentVariableNode struct {
 	Identifier Token
+	Members    []Token // members accessed with dots, ie awvalid in m.awvalid
 	Selectors  []SelectorNode
+	Span       Span
 }
 type IndexNode struct {
 	Index ExprNode
+	Span  Span
 }
 type SelectorNode struct {
 	IndexNode *IndexNode
 	RangeNode *RangeNode
+	Span      Span
 }
 type ValueNode struct {
+	Unary     *Token // ~, !, or - before the value, could be nil
+	Scope     *Token // package that the value is in, could be nil
 	Value     []Token
 	Selectors []SelectorNode
+	Span      Span
 }
 type SizedValueNode struct {
-	Size   *Token
-	Values []ValueNode
+	Signed   *Token // $signed or $unsigned around the value, could be nil
+	Size     *Token // replication count, ie 4 in {4{a}}, could be nil
+	Values   []ValueNode
+	Elements []SizedValueNode // what's concatenated when there's more than one, ie 2{a} and b in {2{a}, b}, whose values are also in Values
+	Span     Span
 }
 type DeclarationNode struct {
 	Type      TypeNode
 	Variables []VariableNode
 	Values    []ExprNode
+	Comments  Trivia
+	Span      Span
 }
 type VariableNode struct {
 	Identifier Token
 	Ranges     []RangeNode
+	Span       Span
 }
 type RangeNode struct {
 	From ExprNode
 	To   ExprNode
+	Span Span
 }
 type TypeNode struct {
-	Type   Token
-	Ranges []RangeNode
+	Direction *Token // input, output, or inout, could be nil. Without a type, the direction is also the Type
+	Scope     *Token // package that a user-defined type is in, could be nil
+	Type      Token
+	Ranges    []RangeNode
+	Span      Span
 }
 type ModuleApplicationNode struct {
 	ModuleName Token  // name of the module
 	GateName   *Token // name of this gate construct, could be nil
 	Range      *RangeNode
 	Arguments  []ArgumentNode
+	Comments   Trivia
+	Span       Span
 }
 type ArgumentNode struct {
-	Label *Token   // label for argument name, could be nil
-	Value ExprNode // value of the argument
+	Label    *Token   // label for argument name, could be nil
+	Value    ExprNode // value of the argument
+	Implicit bool     // true for .name, which connects the port to the signal with the same name
+	Wildcard *Token   // the * of .*, which connects every other port to the signal with its name, could be nil
+	Span     Span
 }
 type ExprNode struct {
 	Value      SizedValueNode
+	Nested     *ExprNode // a parenthesized expression, in place of the value
 	Combinator *Token
 	Right      *ExprNode
 	ExprTrue   *ExprNode
 	ExprFalse  *ExprNode
+	Span       Span
 }
 type GenerateNode struct {
 	Statements []AlwaysStatement
+	Span       Span
 }
 type BeginBlockNode struct {
+	Label      *Token // name after begin :, could be nil
 	Statements []AlwaysStatement
+	Span       Span
 }
 type ForBlockNode struct {
-	Initializer *AssignmentNode
-	Condition   *ExprNode
-	Incrementor *AssignmentNode
-	Body        AlwaysStatement
+	InitializerType *TypeNode // type of the loop variable if it's declared in the loop
+	Initializer     *AssignmentNode
+	Condition       *ExprNode
+	Incrementor     *AssignmentNode
+	Body            AlwaysStatement
+	Span            Span
 }
 type IfBlockNode struct {
 	Expr ExprNode
 	Body AlwaysStatement
 	Else *AlwaysStatement
+	Span Span
 }
 type AlwaysNode struct {
+	Always    Token // always, always_ff, always_comb, or always_latch
 	Times     []TimeNode
 	Statement AlwaysStatement
+	Span      Span
 }
 type AlwaysStatement struct {
 	DelayNode    *DelayNode
@@ -124,61 +261,108 @@ type AlwaysStatement struct {
 	InteriorNode *InteriorNode
 	FunctionNode *FunctionNode
 	CaseNode     *CaseBlock
+	Span         Span
 }
 type TimeNode struct {
 	Time       *Token // negedge, posedge, or nil
 	Identifier Token
+	Span       Span
 }
 type DelayNode struct {
 	Amount Token
+	Span   Span
 }
 type FunctionNode struct {
 	Function    Token
 	Expressions []ExprNode
+	Span        Span
 }
 type DefParamNode struct {
 	Identifiers []Token
 	Value       ExprNode
+	Span        Span
 }
 type InitialNode struct {
 	Statement AlwaysStatement
+	Span      Span
 }
 type CaseBlock struct {
+	Case    Token // case, casex, or casez
 	Expr    ExprNode
 	Cases   []CaseNode
 	Default *AlwaysStatement
+	Span    Span
 }
 type CaseNode struct {
 	Conditions []ExprNode
 	Statement  AlwaysStatement
+	Span       Span
 }
 type TaskNode struct {
 	Identifier Token
 	Statements []AlwaysStatement
+	Comments   Trivia
+	Span       Span
 }
 type Parser struct {
-	skipTokens            []string
+	skipTokens            []TokenKind
+	language              Language
 	FarthestErrorPosition int
 	FarthestError         *error
 }
 
+// NewParser makes a parser for Verilog
 func NewParser() *Parser {
+	return newParser(Verilog)
+}
+
+// NewSVParser makes a parser for the SystemVerilog design subset
+func NewSVParser() *Parser {
+	return newParser(SystemVerilog)
+}
+
+func newParser(language Language) *Parser {
 	return &Parser{
-		skipTokens:            []string{"whitespace", "comment", "newline"},
+		// ILLEGAL tokens are reported by the lexer, so parsing just carries on past them
+		skipTokens:            []TokenKind{WHITESPACE, COMMENT, NEWLINE, ILLEGAL},
+		language:              language,
 		FarthestErrorPosition: -1,
 		FarthestError:         nil,
 	}
 }
 
+// parseError is an error from parsing a token. Most of them are from alternatives
+// that backtracking moves past, so the message is only made when it's needed
+type parseError struct {
+	from          string
+	expected      []string
+	expectedKinds []TokenKind // expected as kinds, if expected isn't given
+	got           Token
+	pos           int
+}
+
+func (e *parseError) Error() string {
+	expected := e.expected
+	if expected == nil {
+		expected = kindNames(e.expectedKinds)
+	}
+	return fmt.Sprintf("parsing %s, expected %v, got: %v at position %d", e.from, expected, e.got, e.pos)
+}
+
 func (p *Parser) newErrorFrom(from string, expected []string, pos int, tokens []Token) error {
-	err := fmt.Errorf("parsing %s, expected %v, got: %v at position %d", from, expected, tokens[pos], pos)
-	if pos > p.FarthestErrorPosition {
-		p.FarthestErrorPosition = pos
+	return p.fail(&parseError{from: from, expected: expected, got: tokens[pos], pos: pos})
+}
+
+// fail keeps track of the error that got the farthest, which is the one that's reported
+func (p *Parser) fail(e *parseError) error {
+	err := error(e)
+	if e.pos > p.FarthestErrorPosition {
+		p.FarthestErrorPosition = e.pos
 		p.FarthestError = &err
 	}
 	return err
 }
-func (p *Parser) skip(tokens []Token, skippables []string, pos int) int {
+func (p *Parser) skip(tokens []Token, skippables []TokenKind, pos int) int {
 	i := pos
 	for ; i < len(tokens); i++ {
 		skippable := false
@@ -196,12 +380,12 @@ func (p *Parser) skip(tokens []Token, skippables []string, pos int) int {
 }
 
 // returned position is the position of the expected (or failed) token
-func (p *Parser) CheckToken(from string, expected []string, pos int, tokens []Token) (int, error) {
+func (p *Parser) CheckToken(from string, expected []TokenKind, pos int, tokens []Token) (int, error) {
 	// skip over any skippables
 	pos = p.skip(tokens, p.skipTokens, pos)
 
 	if pos >= len(tokens) {
-		return -1, p.newErrorFrom(from, expected, len(tokens), append(tokens, Token{Type: "EOF"}))
+		return -1, p.fail(&parseError{from: from, expectedKinds: expected, got: Token{Type: EOF}, pos: len(tokens)})
 	}
 
 	for _, tp := range expected {
@@ -209,7 +393,25 @@ func (p *Parser) CheckToken(from string, expected []string, pos int, tokens []To
 			return pos, nil
 		}
 	}
-	return -1, p.newErrorFrom(from, expected, pos, tokens)
+	return -1, p.fail(&parseError{from: from, expectedKinds: expected, got: tokens[pos], pos: pos})
+}
+
+// returns the names of the given kinds, for error messages
+func kindNames(kinds []TokenKind) []string {
+	names := make([]string, len(kinds))
+	for i, kind := range kinds {
+		names[i] = kind.String()
+	}
+	return names
+}
+
+// peek returns the kind of the next token that isn't skipped, or EOF if there isn't one
+func (p *Parser) peek(tokens []Token, pos int) TokenKind {
+	pos = p.skip(tokens, p.skipTokens, pos)
+	if pos >= len(tokens) {
+		return EOF
+	}
+	return tokens[pos].Type
 }
 
 func (p *Parser) isEOF(tokens []Token, pos int) bool {
@@ -223,7 +425,8 @@ func (p *Parser) isEOF(tokens []Token, pos int) bool {
 
 // returned position is the position after the rbracket
 func (p *Parser) parseRangeNode(tokens []Token, pos int) (result RangeNode, newPos int, err error) {
-	pos, err = p.CheckToken("range node", []string{"lbracket"}, pos, tokens)
+	start := pos
+	pos, err = p.CheckToken("range node", []TokenKind{LBRACKET}, pos, tokens)
 	if err != nil {
 		return
 	}
@@ -237,7 +440,7 @@ func (p *Parser) parseRangeNode(tokens []Token, pos int) (result RangeNode, newP
 	result.From = fromNode
 
 	// double check for colon
-	pos, err = p.CheckToken("range node", []string{"colon"}, pos, tokens)
+	pos, err = p.CheckToken("range node", []TokenKind{COLON}, pos, tokens)
 	if err != nil {
 		return
 	}
@@ -251,19 +454,21 @@ func (p *Parser) parseRangeNode(tokens []Token, pos int) (result RangeNode, newP
 	result.To = toNode
 
 	// double check for rbracket
-	pos, err = p.CheckToken("range node", []string{"rbracket"}, pos, tokens)
+	pos, err = p.CheckToken("range node", []TokenKind{RBRACKET}, pos, tokens)
 	if err != nil {
 		return
 	}
 	pos++
 	newPos = pos
+	result.Span = p.span(tokens, start, newPos)
 	return
 }
 
 // <selector> -> LBRACKET <expr> [COLON <expr>] RBRACKET
 func (p *Parser) parseSelectorNode(tokens []Token, pos int) (result SelectorNode, newPos int, err error) {
+	start := pos
 	// check for lbracket
-	pos, err = p.CheckToken("selector node", []string{"lbracket"}, pos, tokens)
+	pos, err = p.CheckToken("selector node", []TokenKind{LBRACKET}, pos, tokens)
 	if err != nil {
 		return
 	}
@@ -275,7 +480,7 @@ func (p *Parser) parseSelectorNode(tokens []Token, pos int) (result SelectorNode
 	}
 
 	// check for colon
-	potentialPos, e := p.CheckToken("selector node", []string{"colon"}, pos, tokens)
+	potentialPos, e := p.CheckToken("selector node", []TokenKind{COLON}, pos, tokens)
 	if e == nil {
 		// had a colon, so extract the second
 		pos = potentialPos
@@ -293,20 +498,28 @@ func (p *Parser) parseSelectorNode(tokens []Token, pos int) (result SelectorNode
 	}
 
 	// check for rbracket
-	pos, err = p.CheckToken("selector node", []string{"rbracket"}, pos, tokens)
+	pos, err = p.CheckToken("selector node", []TokenKind{RBRACKET}, pos, tokens)
 	if err != nil {
 		return
 	}
 	pos++
 
 	newPos = pos
+	result.Span = p.span(tokens, start, newPos)
+	// the index or range covers the brackets too, like a range node does
+	if result.IndexNode != nil {
+		result.IndexNode.Span = result.Span
+	} else {
+		result.RangeNode.Span = result.Span
+	}
 	return
 }
 
 // <sized_value> -> [ LITERAL ] LCURL <sized_value> { COMMA <sized_value> } RCURL | <value>
 func (p *Parser) parseSized(tokens []Token, pos int) (result SizedValueNode, newPos int, err error) {
+	start := pos
 	// seems to be a sized value
-	potentialPos, e := p.CheckToken("sized value", []string{"literal", "identifier"}, pos, tokens)
+	potentialPos, e := p.CheckToken("sized value", []TokenKind{LITERAL, IDENTIFIER}, pos, tokens)
 	if e == nil {
 		// there was a size
 		result.Size = &tokens[potentialPos]
@@ -314,7 +527,7 @@ func (p *Parser) parseSized(tokens []Token, pos int) (result SizedValueNode, new
 	}
 
 	// now take the lcurl
-	pos, err = p.CheckToken("sized value", []string{"lcurl"}, pos, tokens)
+	pos, err = p.CheckToken("sized value", []TokenKind{LCURL}, pos, tokens)
 	if err != nil {
 		return
 	}
@@ -329,28 +542,40 @@ func (p *Parser) parseSized(tokens []Token, pos int) (result SizedValueNode, new
 	}
 
 	// take the rest
+	elements := []SizedValueNode{}
 	for e == nil {
+		elements = append(elements, sizedNode)
 		result.Values = append(result.Values, sizedNode.Values...)
 		pos = potentialPos
 
-		potentialPos, e = p.CheckToken("sized value", []string{"comma"}, pos, tokens)
+		potentialPos, e = p.CheckToken("sized value", []TokenKind{COMMA}, pos, tokens)
 		if e != nil {
 			break
 		}
 		sizedNode, potentialPos, e = p.parseSizedValueNode(tokens, potentialPos+1)
 	}
+	// a replication by itself keeps its count, ie {4{a}}, while
+	// replications next to other values keep theirs in their elements
+	if result.Size == nil && len(elements) == 1 {
+		result.Size = elements[0].Size
+	}
+	if len(elements) > 1 {
+		result.Elements = elements
+	}
 
 	// take the rcurl
-	pos, err = p.CheckToken("sized value", []string{"rcurl"}, pos, tokens)
+	pos, err = p.CheckToken("sized value", []TokenKind{RCURL}, pos, tokens)
 	if err != nil {
 		return
 	}
 	pos++
 	newPos = pos
+	result.Span = p.span(tokens, start, newPos)
 	return
 }
 
 func (p *Parser) parseSizedValueNode(tokens []Token, pos int) (result SizedValueNode, newPos int, err error) {
+	start := pos
 	// try just taking a value
 	sizedNode, potentialPos, e := p.parseSized(tokens, pos)
 	if e == nil {
@@ -368,18 +593,21 @@ func (p *Parser) parseSizedValueNode(tokens []Token, pos int) (result SizedValue
 		pos = potentialPos
 	}
 	newPos = pos
+	result.Span = p.span(tokens, start, newPos)
 	return
 }
 
 // <maybe_signed> -> <sized_value> | SIGNED LPAREN <sized_value> RPAREN
 func (p *Parser) parseSigned(tokens []Token, pos int) (result SizedValueNode, newPos int, err error) {
-	potentialPos, e := p.CheckToken("signed", []string{"signed"}, pos, tokens)
+	start := pos
+	potentialPos, e := p.CheckToken("signed", []TokenKind{SIGNED}, pos, tokens)
 	if e == nil {
 		// it was signed
+		signed := &tokens[potentialPos]
 		pos = potentialPos + 1
 
 		// take lparen
-		pos, err = p.CheckToken("signed", []string{"lparen"}, pos, tokens)
+		pos, err = p.CheckToken("signed", []TokenKind{LPAREN}, pos, tokens)
 		if err != nil {
 			return
 		}
@@ -390,9 +618,10 @@ func (p *Parser) parseSigned(tokens []Token, pos int) (result SizedValueNode, ne
 		if err != nil {
 			return
 		}
+		result.Signed = signed
 
 		// take rparen
-		pos, err = p.CheckToken("signed", []string{"rparen"}, pos, tokens)
+		pos, err = p.CheckToken("signed", []TokenKind{RPAREN}, pos, tokens)
 		if err != nil {
 			return
 		}
@@ -403,43 +632,55 @@ func (p *Parser) parseSigned(tokens []Token, pos int) (result SizedValueNode, ne
 		result, pos, err = p.parseSizedValueNode(tokens, pos)
 	}
 	newPos = pos
+	result.Span = p.span(tokens, start, newPos)
 	return
 }
 
 // returned position is the position after the value node
 func (p *Parser) parseValueNode(tokens []Token, pos int) (result ValueNode, newPos int, err error) {
-	// <value> -> [TILDE| - ] (LITERAL|(<identifier> { DOT <identifier> })|FUNCLITERAL) { <selector> }
+	start := pos
+	// <value> -> [TILDE| - ] [<scope>] (LITERAL|(<identifier> { DOT <identifier> })|FUNCLITERAL) { <selector> }
 
 	// get optional tilde or minus
-	potentialPos, e := p.CheckToken("value node", []string{"tilde", "operator"}, pos, tokens)
+	potentialPos, e := p.CheckToken("value node", []TokenKind{TILDE, OPERATOR}, pos, tokens)
 	if e == nil {
 		// there was some sort of unary operator
-		if tokens[potentialPos].Type == "operator" && tokens[potentialPos].Value != "-" {
+		if tokens[potentialPos].Type == OPERATOR && tokens[potentialPos].Value != "-" {
 			err = fmt.Errorf("expected tilde or minus but got %s", tokens[potentialPos].Value)
 			return
 		}
+		result.Unary = &tokens[potentialPos]
 		pos = potentialPos + 1
 	}
 
-	pos, err = p.CheckToken("value node", []string{"identifier", "literal", "funcliteral"}, pos, tokens)
+	// get the package, optionally
+	if p.language == SystemVerilog {
+		scope, potentialPos, e := p.parseScope(tokens, pos)
+		if e == nil {
+			result.Scope = &scope
+			pos = potentialPos
+		}
+	}
+
+	pos, err = p.CheckToken("value node", []TokenKind{IDENTIFIER, LITERAL, FUNCLITERAL}, pos, tokens)
 	if err != nil {
 		return
 	}
 	// take the value
 	result.Value = append(result.Value, tokens[pos])
-	if tokens[pos].Type == "identifier" {
+	if tokens[pos].Type == IDENTIFIER {
 		pos++
 		// potentially take the next identifiers
-		potentialPos, e = p.CheckToken("value node", []string{"dot"}, pos, tokens)
+		potentialPos, e = p.CheckToken("value node", []TokenKind{DOT}, pos, tokens)
 		for e == nil {
 			// take the identifier
-			pos, err = p.CheckToken("value node", []string{"identifier"}, potentialPos+1, tokens)
+			pos, err = p.CheckToken("value node", []TokenKind{IDENTIFIER}, potentialPos+1, tokens)
 			if err != nil {
 				return
 			}
 			result.Value = append(result.Value, tokens[pos])
 			pos++
-			potentialPos, e = p.CheckToken("value node", []string{"dot"}, pos, tokens)
+			potentialPos, e = p.CheckToken("value node", []TokenKind{DOT}, pos, tokens)
 		}
 	} else {
 		pos++
@@ -454,22 +695,26 @@ func (p *Parser) parseValueNode(tokens []Token, pos int) (result ValueNode, newP
 	}
 
 	newPos = pos
+	result.Span = p.span(tokens, start, newPos)
 	return
 }
 
 func (p *Parser) parseExpression(tokens []Token, pos int) (result ExprNode, newPos int, err error) {
+	start := pos
 	// <expr> -> (<value> | LPAREN <expr> RPAREN) [(OPERATOR|COMPARATOR) <expr>]  [ QUESTION <expr> COLON <expr> ]
 
-	potentialPos, e := p.CheckToken("expression", []string{"lparen"}, pos, tokens)
+	potentialPos, e := p.CheckToken("expression", []TokenKind{LPAREN}, pos, tokens)
 	if e == nil {
 		// nested expression
-		pos = potentialPos + 1
-		result, pos, err = p.parseExpression(tokens, pos)
-		if err != nil {
+		nested, potentialPos, e := p.parseExpression(tokens, potentialPos+1)
+		if e != nil {
+			err = e
 			return
 		}
+		result.Nested = &nested
+		pos = potentialPos
 		// check for rparen
-		pos, err = p.CheckToken("expression", []string{"rparen"}, pos, tokens)
+		pos, err = p.CheckToken("expression", []TokenKind{RPAREN}, pos, tokens)
 		if err != nil {
 			return
 		}
@@ -486,7 +731,7 @@ func (p *Parser) parseExpression(tokens []Token, pos int) (result ExprNode, newP
 	}
 
 	// check for operator
-	potentialPos, e = p.CheckToken("expression", []string{"operator", "comparator"}, pos, tokens)
+	potentialPos, e = p.CheckToken("expression", []TokenKind{OPERATOR, COMPARATOR}, pos, tokens)
 	if e == nil {
 		// had an operator or comparator
 		pos = potentialPos
@@ -504,7 +749,7 @@ func (p *Parser) parseExpression(tokens []Token, pos int) (result ExprNode, newP
 	}
 
 	// check for ternary
-	potentialPos, e = p.CheckToken("expression", []string{"question"}, pos, tokens)
+	potentialPos, e = p.CheckToken("expression", []TokenKind{QUESTION}, pos, tokens)
 	if e == nil {
 		// get the true expression
 		pos = potentialPos + 1
@@ -517,36 +762,48 @@ func (p *Parser) parseExpression(tokens []Token, pos int) (result ExprNode, newP
 		pos = potentialPos
 
 		// need a colon
-		pos, err = p.CheckToken("expression", []string{"colon"}, pos, tokens)
+		pos, err = p.CheckToken("expression", []TokenKind{COLON}, pos, tokens)
 		if err != nil {
 			return
 		}
 		pos++
 
 		// get the false expression
-		tmp, potentialPos, e = p.parseExpression(tokens, pos)
+		falseExpr, potentialPos, e := p.parseExpression(tokens, pos)
 		if e != nil {
 			err = e
 			return
 		}
-		result.ExprFalse = &tmp
+		result.ExprFalse = &falseExpr
 		pos = potentialPos
 	}
 
 	newPos = pos
+	result.Span = p.span(tokens, start, newPos)
 	return
 }
 
 func (p *Parser) parseArgument(tokens []Token, pos int) (result ArgumentNode, newPos int, err error) {
+	start := pos
 	// dot for named parameter, identifier/lcurcly/literal for value
-	pos, err = p.CheckToken("argument", []string{"dot", "identifier", "lcurl", "literal"}, pos, tokens)
+	pos, err = p.CheckToken("argument", []TokenKind{DOT, IDENTIFIER, LCURL, LITERAL}, pos, tokens)
 	if err != nil {
 		return
 	}
-	if tokens[pos].Type == "dot" {
+	if tokens[pos].Type == DOT {
 		// named parameter
 		pos++
-		pos, err = p.CheckToken("argument", []string{"identifier"}, pos, tokens)
+		if p.language == SystemVerilog {
+			// .* connects everything else implicitly
+			potentialPos, e := p.CheckToken("argument", []TokenKind{OPERATOR}, pos, tokens)
+			if e == nil && tokens[potentialPos].Value == "*" {
+				result.Wildcard = &tokens[potentialPos]
+				newPos = potentialPos + 1
+				result.Span = p.span(tokens, start, newPos)
+				return
+			}
+		}
+		pos, err = p.CheckToken("argument", []TokenKind{IDENTIFIER}, pos, tokens)
 		if err != nil {
 			return
 		}
@@ -554,7 +811,15 @@ func (p *Parser) parseArgument(tokens []Token, pos int) (result ArgumentNode, ne
 		pos++
 
 		// check for lparen
-		pos, err = p.CheckToken("argument", []string{"lparen"}, pos, tokens)
+		potentialPos, e := p.CheckToken("argument", []TokenKind{LPAREN}, pos, tokens)
+		if e != nil && p.language == SystemVerilog {
+			// .name connects the signal with the same name
+			result.Implicit = true
+			newPos = pos
+			result.Span = p.span(tokens, start, newPos)
+			return
+		}
+		pos, err = potentialPos, e
 		if err != nil {
 			return
 		}
@@ -572,12 +837,13 @@ func (p *Parser) parseArgument(tokens []Token, pos int) (result ArgumentNode, ne
 		}
 
 		// check for rparen
-		pos, err = p.CheckToken("argument", []string{"rparen"}, pos, tokens)
+		pos, err = p.CheckToken("argument", []TokenKind{RPAREN}, pos, tokens)
 		if err != nil {
 			return
 		}
 		pos++
 		newPos = pos
+		result.Span = p.span(tokens, start, newPos)
 		return
 	} else {
 		// just a value
@@ -591,6 +857,7 @@ func (p *Parser) parseArgument(tokens []Token, pos int) (result ArgumentNode, ne
 			// TODO - error handling
 		}
 		newPos = pos
+		result.Span = p.span(tokens, start, newPos)
 		return
 	}
 }
@@ -607,7 +874,7 @@ func (p *Parser) parseArguments(tokens []Token, pos int) (result []ArgumentNode,
 	}
 
 	// now take the rest
-	potentialPos, e = p.CheckToken("arguments", []string{"comma"}, pos, tokens)
+	potentialPos, e = p.CheckToken("arguments", []TokenKind{COMMA}, pos, tokens)
 	for e == nil {
 		argument, potentialPos, e = p.parseArgument(tokens, potentialPos+1) // potentialPos was position of comma
 		if e == nil {
@@ -619,14 +886,15 @@ func (p *Parser) parseArguments(tokens []Token, pos int) (result []ArgumentNode,
 			err = e
 			return
 		}
-		potentialPos, e = p.CheckToken("arguments", []string{"comma"}, pos, tokens)
+		potentialPos, e = p.CheckToken("arguments", []TokenKind{COMMA}, pos, tokens)
 	}
 	newPos = pos
 	return
 }
 func (p *Parser) parseModuleApplication(tokens []Token, pos int) (result ModuleApplicationNode, newPos int, err error) {
-	// module name
-	pos, err = p.CheckToken("module application", []string{"identifier"}, pos, tokens)
+	start := pos
+	// module name, which could also be a gate like and
+	pos, err = p.CheckToken("module application", []TokenKind{IDENTIFIER, GATE, OR}, pos, tokens)
 	if err != nil {
 		return
 	}
@@ -634,7 +902,7 @@ func (p *Parser) parseModuleApplication(tokens []Token, pos int) (result ModuleA
 	pos++
 
 	// might have a gate name
-	potentialPos, e := p.CheckToken("module application", []string{"identifier"}, pos, tokens)
+	potentialPos, e := p.CheckToken("module application", []TokenKind{IDENTIFIER}, pos, tokens)
 	if e == nil {
 		result.GateName = &tokens[potentialPos]
 		pos = potentialPos + 1
@@ -648,7 +916,7 @@ func (p *Parser) parseModuleApplication(tokens []Token, pos int) (result ModuleA
 	}
 
 	// check for lparen
-	pos, err = p.CheckToken("module application", []string{"lparen"}, pos, tokens)
+	pos, err = p.CheckToken("module application", []TokenKind{LPAREN}, pos, tokens)
 	if err != nil {
 		return
 	}
@@ -666,25 +934,28 @@ func (p *Parser) parseModuleApplication(tokens []Token, pos int) (result ModuleA
 	}
 
 	// check for rparen
-	pos, err = p.CheckToken("module application", []string{"rparen"}, pos, tokens)
+	pos, err = p.CheckToken("module application", []TokenKind{RPAREN}, pos, tokens)
 	if err != nil {
 		return
 	}
 	pos++
 
 	// check for semicolon
-	pos, err = p.CheckToken("module application", []string{"semicolon"}, pos, tokens)
+	pos, err = p.CheckToken("module application", []TokenKind{SEMICOLON}, pos, tokens)
 	if err != nil {
 		return
 	}
 	pos++
 	newPos = pos
+	result.Span = p.span(tokens, start, newPos)
+	result.Comments = p.comments(tokens, start, newPos)
 	return
 }
 
 func (p *Parser) parseVariableNode(tokens []Token, pos int) (result VariableNode, newPos int, err error) {
+	start := pos
 	// identifier optionally followed by a range
-	pos, err = p.CheckToken("variable", []string{"identifier"}, pos, tokens)
+	pos, err = p.CheckToken("variable", []TokenKind{IDENTIFIER}, pos, tokens)
 	if err != nil {
 		return
 	}
@@ -699,11 +970,12 @@ func (p *Parser) parseVariableNode(tokens []Token, pos int) (result VariableNode
 		rangeNode, potentialPos, e = p.parseRangeNode(tokens, pos) // try taking the next range
 	}
 	newPos = pos
+	result.Span = p.span(tokens, start, newPos)
 	return
 }
 func (p *Parser) parseAssignables(tokens []Token, pos int) (result []AssignmentVariableNode, newPos int, err error) {
 	//	<assignable> -> [LCURL] <single_var> {COMMA <single_var>} [RCURL]
-	potentialPos, e := p.CheckToken("assignables", []string{"lcurl"}, pos, tokens)
+	potentialPos, e := p.CheckToken("assignables", []TokenKind{LCURL}, pos, tokens)
 	// it's ok if it fails since it's optional
 	needRcurl := false
 	if e == nil {
@@ -720,7 +992,7 @@ func (p *Parser) parseAssignables(tokens []Token, pos int) (result []AssignmentV
 	result = append(result, assignable)
 	pos = potentialPos
 	// now try taking the rest
-	potentialPos, e = p.CheckToken("assignables", []string{"comma"}, pos, tokens)
+	potentialPos, e = p.CheckToken("assignables", []TokenKind{COMMA}, pos, tokens)
 	for e == nil {
 		assignable, potentialPos, e = p.parseAssignableVariable(tokens, potentialPos+1)
 		if e != nil {
@@ -729,12 +1001,12 @@ func (p *Parser) parseAssignables(tokens []Token, pos int) (result []AssignmentV
 		}
 		result = append(result, assignable)
 		pos = potentialPos
-		potentialPos, e = p.CheckToken("assignables", []string{"comma"}, pos, tokens)
+		potentialPos, e = p.CheckToken("assignables", []TokenKind{COMMA}, pos, tokens)
 	}
 
 	if needRcurl {
 		// check for rcurl
-		pos, err = p.CheckToken("assignables", []string{"rcurl"}, pos, tokens)
+		pos, err = p.CheckToken("assignables", []TokenKind{RCURL}, pos, tokens)
 		if err != nil {
 			return
 		}
@@ -745,14 +1017,27 @@ func (p *Parser) parseAssignables(tokens []Token, pos int) (result []AssignmentV
 
 }
 func (p *Parser) parseAssignableVariable(tokens []Token, pos int) (result AssignmentVariableNode, newPos int, err error) {
-	// <assignable_variable> -> <identifier> {<selector>}
-	pos, err = p.CheckToken("assignmentvariable", []string{"identifier"}, pos, tokens)
+	start := pos
+	// <assignable_variable> -> <identifier> { DOT <identifier> } {<selector>}
+	pos, err = p.CheckToken("assignmentvariable", []TokenKind{IDENTIFIER}, pos, tokens)
 	if err != nil {
 		return
 	}
 	result.Identifier = tokens[pos]
 	pos++
 
+	// take members
+	potentialPos, e := p.CheckToken("assignmentvariable", []TokenKind{DOT}, pos, tokens)
+	for e == nil {
+		pos, err = p.CheckToken("assignmentvariable", []TokenKind{IDENTIFIER}, potentialPos+1, tokens)
+		if err != nil {
+			return
+		}
+		result.Members = append(result.Members, tokens[pos])
+		pos++
+		potentialPos, e = p.CheckToken("assignmentvariable", []TokenKind{DOT}, pos, tokens)
+	}
+
 	// take selectors
 	selector, potentialPos, e := p.parseSelectorNode(tokens, pos)
 	for e == nil {
@@ -761,11 +1046,13 @@ func (p *Parser) parseAssignableVariable(tokens []Token, pos int) (result Assign
 		selector, potentialPos, e = p.parseSelectorNode(tokens, pos)
 	}
 	newPos = pos
+	result.Span = p.span(tokens, start, newPos)
 	return
 }
 func (p *Parser) parseAssignmentNodeWithoutSemicolon(tokens []Token, pos int) (result AssignmentNode, newPos int, err error) {
+	start := pos
 	//<assignment_without_semicolon> -> [ASSIGN] <assignable> (EQUAL | <=) <expr>
-	potentialPos, e := p.CheckToken("assignment", []string{"assign"}, pos, tokens)
+	potentialPos, e := p.CheckToken("assignment", []TokenKind{ASSIGN}, pos, tokens)
 	// it's ok if it fails since it's optional
 	if e == nil {
 		pos = potentialPos + 1
@@ -781,10 +1068,10 @@ func (p *Parser) parseAssignmentNodeWithoutSemicolon(tokens []Token, pos int) (r
 	pos = potentialPos
 
 	// check for equal
-	pos, err = p.CheckToken("assignment", []string{"equal", "comparator"}, pos, tokens)
+	pos, err = p.CheckToken("assignment", []TokenKind{EQUAL, COMPARATOR}, pos, tokens)
 	if err != nil {
 		return
-	} else if tokens[pos].Type == "comparator" {
+	} else if tokens[pos].Type == COMPARATOR {
 		if tokens[pos].Value != "<=" {
 			err = fmt.Errorf("expected = or <=, got %s", tokens[pos].Value)
 			return
@@ -804,9 +1091,46 @@ func (p *Parser) parseAssignmentNodeWithoutSemicolon(tokens []Token, pos int) (r
 	pos = potentialPos
 
 	newPos = pos
+	result.Span = p.span(tokens, start, newPos)
+	return
+}
+
+// <increment> -> <assignable_var> INCREMENT | INCREMENT <assignable_var>
+func (p *Parser) parseIncrement(tokens []Token, pos int) (result AssignmentNode, newPos int, err error) {
+	start := pos
+	// prefix increment
+	potentialPos, e := p.CheckToken("increment", []TokenKind{INCREMENT}, pos, tokens)
+	if e == nil {
+		result.Increment = &tokens[potentialPos]
+		variable, potentialPos, e := p.parseAssignableVariable(tokens, potentialPos+1)
+		if e != nil {
+			err = e
+			return
+		}
+		result.Variables = []AssignmentVariableNode{variable}
+		newPos = potentialPos
+		result.Span = p.span(tokens, start, newPos)
+		return
+	}
+
+	// postfix increment
+	variable, pos, err := p.parseAssignableVariable(tokens, pos)
+	if err != nil {
+		return
+	}
+	result.Variables = []AssignmentVariableNode{variable}
+	pos, err = p.CheckToken("increment", []TokenKind{INCREMENT}, pos, tokens)
+	if err != nil {
+		return
+	}
+	result.Increment = &tokens[pos]
+	pos++
+	newPos = pos
+	result.Span = p.span(tokens, start, newPos)
 	return
 }
 func (p *Parser) parseAssignmentNode(tokens []Token, pos int) (result AssignmentNode, newPos int, err error) {
+	start := pos
 	// <assignment> SEMICOLON
 	result, pos, err = p.parseAssignmentNodeWithoutSemicolon(tokens, pos)
 	if err != nil {
@@ -814,36 +1138,104 @@ func (p *Parser) parseAssignmentNode(tokens []Token, pos int) (result Assignment
 	}
 
 	// check for semicolon
-	pos, err = p.CheckToken("assignment", []string{"semicolon"}, pos, tokens)
+	pos, err = p.CheckToken("assignment", []TokenKind{SEMICOLON}, pos, tokens)
 	if err != nil {
 		return
 	}
 	pos++
 	newPos = pos
+	result.Span = p.span(tokens, start, newPos)
 	return
 }
 
-func (p *Parser) parseTypeNode(tokens []Token, pos int) (result TypeNode, newPos int, err error) {
-	// (TYPE | DIRECTION [TYPE]) [<range>]
-	pos, err = p.CheckToken("type", []string{"type", "direction"}, pos, tokens)
+// <scope> -> <identifier> SCOPE
+func (p *Parser) parseScope(tokens []Token, pos int) (result Token, newPos int, err error) {
+	pos, err = p.CheckToken("scope", []TokenKind{IDENTIFIER}, pos, tokens)
+	if err != nil {
+		return
+	}
+	result = tokens[pos]
+	pos++
+
+	pos, err = p.CheckToken("scope", []TokenKind{SCOPE}, pos, tokens)
 	if err != nil {
 		return
 	}
+	pos++
+	newPos = pos
+	return
+}
+
+// <user_type> -> [<scope>] <identifier>
+func (p *Parser) parseUserType(tokens []Token, pos int) (result TypeNode, newPos int, err error) {
+	start := pos
+	scope, potentialPos, e := p.parseScope(tokens, pos)
+	if e == nil {
+		result.Scope = &scope
+		pos = potentialPos
+	}
+
+	pos, err = p.CheckToken("user type", []TokenKind{IDENTIFIER}, pos, tokens)
+	if err != nil {
+		return
+	}
+	result.Type = tokens[pos]
+	pos++
+	newPos = pos
+	result.Span = p.span(tokens, start, newPos)
+	return
+}
 
-	if tokens[pos].Type == "direction" {
+func (p *Parser) parseTypeNode(tokens []Token, pos int) (result TypeNode, newPos int, err error) {
+	start := pos
+	// (TYPE | DIRECTION [TYPE | <user_type>] | <user_type>) [<range>]
+	potentialPos, e := p.CheckToken("type", []TokenKind{TYPE, DIRECTION}, pos, tokens)
+	isUserType := false
+	if e != nil {
+		if p.language != SystemVerilog {
+			err = e
+			return
+		}
+
+		// systemverilog also has user-defined types
+		result, pos, err = p.parseUserType(tokens, pos)
+		if err != nil {
+			return
+		}
+		isUserType = true
+	} else if tokens[potentialPos].Type == DIRECTION {
+		pos = potentialPos
+		direction := &tokens[pos]
 		// potentially take a type
-		potentialPos, e := p.CheckToken("type", []string{"type"}, pos+1, tokens)
+		potentialPos, e := p.CheckToken("type", []TokenKind{TYPE}, pos+1, tokens)
 		if e == nil {
 			// success!
 			result.Type = tokens[potentialPos]
 			pos = potentialPos + 1
+		} else if p.language != SystemVerilog {
+			result.Type = tokens[pos]
+			pos++
+		} else if userType, potentialPos, e := p.parseUserType(tokens, pos+1); e == nil && p.isIdentifierNext(tokens, potentialPos) {
+			// the identifier is a type only if it's followed by the name
+			result = userType
+			pos = potentialPos
 		} else {
 			result.Type = tokens[pos]
 			pos++
 		}
+		result.Direction = direction
 	} else {
+		pos = potentialPos
 		result.Type = tokens[pos]
 		pos++
+
+		// systemverilog parameters can also name their data type, ie parameter int
+		if p.language == SystemVerilog && (result.Type.Value == "parameter" || result.Type.Value == "localparam") {
+			potentialPos, e := p.CheckToken("type", []TokenKind{TYPE}, pos, tokens)
+			if e == nil {
+				pos = potentialPos + 1
+			}
+		}
 	}
 
 	// now try taking the range; it's ok if it fails since it's optional
@@ -853,11 +1245,28 @@ func (p *Parser) parseTypeNode(tokens []Token, pos int) (result TypeNode, newPos
 		pos = potentialPos
 		rangeNode, potentialPos, e = p.parseRangeNode(tokens, pos)
 	}
+
+	// a user-defined type has to be followed by a name,
+	// otherwise it's something else like a module application
+	if isUserType {
+		_, err = p.CheckToken("type", []TokenKind{IDENTIFIER}, pos, tokens)
+		if err != nil {
+			return
+		}
+	}
 	newPos = pos
+	result.Span = p.span(tokens, start, newPos)
 	return
 }
 
+// returns whether the next non-skippable token is an identifier
+func (p *Parser) isIdentifierNext(tokens []Token, pos int) bool {
+	pos = p.skip(tokens, p.skipTokens, pos)
+	return pos < len(tokens) && tokens[pos].Type == IDENTIFIER
+}
+
 func (p *Parser) parseDeclarationNode(tokens []Token, pos int) (result DeclarationNode, newPos int, err error) {
+	start := pos
 	// <declaration> -> <type> <single_var> EQUAL <expr> { COMMA <single_var> EQUAL <expr> } SEMICOLON
 	// | <type> <single_var> { COMMA <single_var> } SEMICOLON
 
@@ -879,7 +1288,7 @@ func (p *Parser) parseDeclarationNode(tokens []Token, pos int) (result Declarati
 	pos = potentialPos
 
 	// see if it's an equal
-	potentialPos, e = p.CheckToken("declaration", []string{"equal"}, pos, tokens)
+	potentialPos, e = p.CheckToken("declaration", []TokenKind{EQUAL}, pos, tokens)
 	if e == nil {
 		// it's an equal, so there's a value
 		valueNode, potentialPos, e := p.parseExpression(tokens, potentialPos+1)
@@ -892,7 +1301,7 @@ func (p *Parser) parseDeclarationNode(tokens []Token, pos int) (result Declarati
 		}
 
 		// see if there's more variables
-		potentialPos, e = p.CheckToken("declaration", []string{"comma"}, pos, tokens)
+		potentialPos, e = p.CheckToken("declaration", []TokenKind{COMMA}, pos, tokens)
 		if e == nil {
 			pos = potentialPos
 			// it's a comma, so there's more variables
@@ -905,7 +1314,7 @@ func (p *Parser) parseDeclarationNode(tokens []Token, pos int) (result Declarati
 				result.Variables = append(result.Variables, variableNode)
 
 				// get equal
-				pos, err = p.CheckToken("declaration", []string{"equal"}, pos, tokens)
+				pos, err = p.CheckToken("declaration", []TokenKind{EQUAL}, pos, tokens)
 				if err != nil {
 					return
 				}
@@ -919,13 +1328,13 @@ func (p *Parser) parseDeclarationNode(tokens []Token, pos int) (result Declarati
 				result.Values = append(result.Values, valueNode)
 
 				// possibly continue
-				potentialPos, e = p.CheckToken("declaration", []string{"comma"}, pos, tokens)
+				potentialPos, e = p.CheckToken("declaration", []TokenKind{COMMA}, pos, tokens)
 			}
 		}
 
 	} else {
 		// see if there's more variables
-		potentialPos, e = p.CheckToken("declaration", []string{"comma"}, pos, tokens)
+		potentialPos, e = p.CheckToken("declaration", []TokenKind{COMMA}, pos, tokens)
 		if e == nil {
 			pos = potentialPos
 			// it's a comma, so there's more variables
@@ -937,39 +1346,43 @@ func (p *Parser) parseDeclarationNode(tokens []Token, pos int) (result Declarati
 				}
 				result.Variables = append(result.Variables, variableNode)
 				pos = potentialPos
-				potentialPos, e = p.CheckToken("declaration", []string{"comma"}, pos, tokens)
+				potentialPos, e = p.CheckToken("declaration", []TokenKind{COMMA}, pos, tokens)
 			}
 		}
 	}
 
 	// check for semicolon
-	pos, err = p.CheckToken("declaration", []string{"semicolon"}, pos, tokens)
+	pos, err = p.CheckToken("declaration", []TokenKind{SEMICOLON}, pos, tokens)
 	if err != nil {
 		return
 	}
 	pos++
 	newPos = pos
+	result.Span = p.span(tokens, start, newPos)
+	result.Comments = p.comments(tokens, start, newPos)
 	return
 }
 
 func (p *Parser) parseBeginBlock(tokens []Token, pos int) (result BeginBlockNode, newPos int, err error) {
+	start := pos
 	// BEGIN [ COLON <identifier> ] { <alwaysable_statement> } END
-	pos, err = p.CheckToken("begin block", []string{"begin"}, pos, tokens)
+	pos, err = p.CheckToken("begin block", []TokenKind{BEGIN}, pos, tokens)
 	if err != nil {
 		return
 	}
 	pos++
 
 	// optionally, get colon
-	potentialPos, e := p.CheckToken("begin block", []string{"colon"}, pos, tokens)
+	potentialPos, e := p.CheckToken("begin block", []TokenKind{COLON}, pos, tokens)
 	if e == nil {
 		pos = potentialPos + 1
 
 		// get identifier
-		pos, err = p.CheckToken("begin block", []string{"identifier"}, pos, tokens)
+		pos, err = p.CheckToken("begin block", []TokenKind{IDENTIFIER}, pos, tokens)
 		if err != nil {
 			return
 		}
+		result.Label = &tokens[pos]
 		pos++
 	}
 
@@ -983,25 +1396,27 @@ func (p *Parser) parseBeginBlock(tokens []Token, pos int) (result BeginBlockNode
 	pos = potentialPos
 
 	// check for end
-	pos, err = p.CheckToken("begin block", []string{"end"}, pos, tokens)
+	pos, err = p.CheckToken("begin block", []TokenKind{END}, pos, tokens)
 	if err != nil {
 		return
 	}
 	pos++
 	newPos = pos
+	result.Span = p.span(tokens, start, newPos)
 	return
 }
 func (p *Parser) parseIfBlock(tokens []Token, pos int) (result IfBlockNode, newPos int, err error) {
+	start := pos
 	// IF LPAREN <expr> RPAREN <generateable_statement> [ELSE <generateable_statement>]
 	// get if
-	pos, err = p.CheckToken("if block", []string{"if"}, pos, tokens)
+	pos, err = p.CheckToken("if block", []TokenKind{IF}, pos, tokens)
 	if err != nil {
 		return
 	}
 	pos++
 
 	// get lparen
-	pos, err = p.CheckToken("if block", []string{"lparen"}, pos, tokens)
+	pos, err = p.CheckToken("if block", []TokenKind{LPAREN}, pos, tokens)
 	if err != nil {
 		return
 	}
@@ -1017,7 +1432,7 @@ func (p *Parser) parseIfBlock(tokens []Token, pos int) (result IfBlockNode, newP
 	pos = potentialPos
 
 	// get rparen
-	pos, err = p.CheckToken("if block", []string{"rparen"}, pos, tokens)
+	pos, err = p.CheckToken("if block", []TokenKind{RPAREN}, pos, tokens)
 	if err != nil {
 		return
 	}
@@ -1033,7 +1448,7 @@ func (p *Parser) parseIfBlock(tokens []Token, pos int) (result IfBlockNode, newP
 	pos = potentialPos
 
 	// get else
-	potentialPos, e = p.CheckToken("if block", []string{"else"}, pos, tokens)
+	potentialPos, e = p.CheckToken("if block", []TokenKind{ELSE}, pos, tokens)
 	if e == nil {
 		pos = potentialPos + 1
 		// get always statement
@@ -1047,30 +1462,44 @@ func (p *Parser) parseIfBlock(tokens []Token, pos int) (result IfBlockNode, newP
 	}
 
 	newPos = pos
+	result.Span = p.span(tokens, start, newPos)
 	return
 }
 func (p *Parser) parseForBlock(tokens []Token, pos int) (result ForBlockNode, newPos int, err error) {
-	// <for> -> FOR LPAREN [<assignment_without_semicolon>] SEMICOLON [<expr>] SEMICOLON [<assignment_without_semicolon>] RPAREN <begin_block>
+	start := pos
+	// <for> -> FOR LPAREN [[<type>] <assignment_without_semicolon>] SEMICOLON [<expr>] SEMICOLON [<assignment_without_semicolon> | <increment>] RPAREN <alwaysable_statement>
 	// get for
-	pos, err = p.CheckToken("for block", []string{"for"}, pos, tokens)
+	pos, err = p.CheckToken("for block", []TokenKind{FOR}, pos, tokens)
 	if err != nil {
 		return
 	}
 	pos++
 	// get lparen
-	pos, err = p.CheckToken("for block", []string{"lparen"}, pos, tokens)
+	pos, err = p.CheckToken("for block", []TokenKind{LPAREN}, pos, tokens)
 	if err != nil {
 		return
 	}
 	pos++
+	// systemverilog loops can declare their variable, ie int i = 0
+	if p.language == SystemVerilog {
+		typeNode, potentialPos, e := p.parseTypeNode(tokens, pos)
+		if e == nil && typeNode.Type.Type == TYPE {
+			result.InitializerType = &typeNode
+			pos = potentialPos
+		}
+	}
 	// get assignment_without_semicolon, optionally
 	assignmentWithoutSemicolon, potentialPos, e := p.parseAssignmentNodeWithoutSemicolon(tokens, pos)
 	if e == nil {
 		result.Initializer = &assignmentWithoutSemicolon
 		pos = potentialPos
+	} else if result.InitializerType != nil {
+		// a declared loop variable must be initialized
+		err = e
+		return
 	}
 	// get semicolon
-	pos, err = p.CheckToken("for block", []string{"semicolon"}, pos, tokens)
+	pos, err = p.CheckToken("for block", []TokenKind{SEMICOLON}, pos, tokens)
 	if err != nil {
 		return
 	}
@@ -1082,20 +1511,26 @@ func (p *Parser) parseForBlock(tokens []Token, pos int) (result ForBlockNode, ne
 		pos = potentialPos
 	}
 	// get semicolon
-	pos, err = p.CheckToken("for block", []string{"semicolon"}, pos, tokens)
+	pos, err = p.CheckToken("for block", []TokenKind{SEMICOLON}, pos, tokens)
 	if err != nil {
 		return
 	}
 	pos++
-	// get assignment_without_semicolon, optionally
-	assignmentWithoutSemicolon, potentialPos, e = p.parseAssignmentNodeWithoutSemicolon(tokens, pos)
+	// get assignment_without_semicolon or increment, optionally
+	incrementor, potentialPos, e := p.parseAssignmentNodeWithoutSemicolon(tokens, pos)
 	if e == nil {
-		result.Incrementor = &assignmentWithoutSemicolon
+		result.Incrementor = &incrementor
 		pos = potentialPos
+	} else if p.language == SystemVerilog {
+		increment, potentialPos, e := p.parseIncrement(tokens, pos)
+		if e == nil {
+			result.Incrementor = &increment
+			pos = potentialPos
+		}
 	}
 
 	// get rparen
-	pos, err = p.CheckToken("for block", []string{"rparen"}, pos, tokens)
+	pos, err = p.CheckToken("for block", []TokenKind{RPAREN}, pos, tokens)
 	if err != nil {
 		return
 	}
@@ -1109,9 +1544,11 @@ func (p *Parser) parseForBlock(tokens []Token, pos int) (result ForBlockNode, ne
 	pos = potentialPos
 	result.Body = body
 	newPos = pos
+	result.Span = p.span(tokens, start, newPos)
 	return
 }
 func (p *Parser) parseCaseNode(tokens []Token, pos int) (result CaseNode, newPos int, err error) {
+	start := pos
 	// <case> -> <expr> { COMMA <expr> } COLON <alwaysable_statement>
 	expr, pos, err := p.parseExpression(tokens, pos)
 	if err != nil {
@@ -1120,7 +1557,7 @@ func (p *Parser) parseCaseNode(tokens []Token, pos int) (result CaseNode, newPos
 	result.Conditions = append(result.Conditions, expr)
 
 	// get other expressions, optionally
-	potentialPos, e := p.CheckToken("case", []string{"comma"}, pos, tokens)
+	potentialPos, e := p.CheckToken("case", []TokenKind{COMMA}, pos, tokens)
 	for e == nil {
 		pos = potentialPos + 1
 		// get expr
@@ -1129,11 +1566,11 @@ func (p *Parser) parseCaseNode(tokens []Token, pos int) (result CaseNode, newPos
 			return
 		}
 		result.Conditions = append(result.Conditions, expr)
-		potentialPos, e = p.CheckToken("case", []string{"comma"}, pos, tokens)
+		potentialPos, e = p.CheckToken("case", []TokenKind{COMMA}, pos, tokens)
 	}
 
 	// get colon
-	pos, err = p.CheckToken("case", []string{"colon"}, pos, tokens)
+	pos, err = p.CheckToken("case", []TokenKind{COLON}, pos, tokens)
 	if err != nil {
 		return
 	}
@@ -1148,20 +1585,23 @@ func (p *Parser) parseCaseNode(tokens []Token, pos int) (result CaseNode, newPos
 	pos = potentialPos
 	result.Statement = body
 	newPos = pos
+	result.Span = p.span(tokens, start, newPos)
 	return
 }
 
 // <case_block> -> CASE LPAREN <expr> RPAREN {<case>} [ DEFAULT COLON <alwaysable_statement> ] ENDCASE
 func (p *Parser) parseCaseBlock(tokens []Token, pos int) (result CaseBlock, newPos int, err error) {
+	start := pos
 	// get case
-	pos, err = p.CheckToken("case block", []string{"case"}, pos, tokens)
+	pos, err = p.CheckToken("case block", []TokenKind{CASE}, pos, tokens)
 	if err != nil {
 		return
 	}
+	result.Case = tokens[pos]
 	pos++
 
 	// get lparen
-	pos, err = p.CheckToken("case block", []string{"lparen"}, pos, tokens)
+	pos, err = p.CheckToken("case block", []TokenKind{LPAREN}, pos, tokens)
 	if err != nil {
 		return
 	}
@@ -1175,7 +1615,7 @@ func (p *Parser) parseCaseBlock(tokens []Token, pos int) (result CaseBlock, newP
 	result.Expr = expr
 
 	// get rparen
-	pos, err = p.CheckToken("case block", []string{"rparen"}, pos, tokens)
+	pos, err = p.CheckToken("case block", []TokenKind{RPAREN}, pos, tokens)
 	if err != nil {
 		return
 	}
@@ -1190,11 +1630,11 @@ func (p *Parser) parseCaseBlock(tokens []Token, pos int) (result CaseBlock, newP
 	}
 
 	// get default, optionally
-	potentialPos, e = p.CheckToken("case block", []string{"default"}, pos, tokens)
+	potentialPos, e = p.CheckToken("case block", []TokenKind{DEFAULT}, pos, tokens)
 	if e == nil {
 		pos = potentialPos + 1
 		// get colon
-		pos, err = p.CheckToken("case block", []string{"colon"}, pos, tokens)
+		pos, err = p.CheckToken("case block", []TokenKind{COLON}, pos, tokens)
 		if err != nil {
 			return
 		}
@@ -1210,62 +1650,81 @@ func (p *Parser) parseCaseBlock(tokens []Token, pos int) (result CaseBlock, newP
 	}
 
 	// get endcase
-	pos, err = p.CheckToken("case block", []string{"endcase"}, pos, tokens)
+	pos, err = p.CheckToken("case block", []TokenKind{ENDCASE}, pos, tokens)
 	if err != nil {
 		return
 	}
 	pos++
 
 	newPos = pos
+	result.Span = p.span(tokens, start, newPos)
 	return
 }
 
 func (p *Parser) parseAlwaysStatement(tokens []Token, pos int) (result AlwaysStatement, newPos int, err error) {
+	start := pos
 	// <always_statement> -> <begin_block> | <interior_statement> | <for> | <if> | <builtin_function_call> | <delay_statement>
-	beginResult, potentialPos, e := p.parseBeginBlock(tokens, pos)
-	if e == nil {
+	// all but the interior statement start with their own token, so that picks which one it is
+	switch p.peek(tokens, pos) {
+	case BEGIN:
+		beginResult, potentialPos, e := p.parseBeginBlock(tokens, pos)
+		if e != nil {
+			err = e
+			return
+		}
 		result.BeginBlock = &beginResult
 		pos = potentialPos
-	} else {
+	case FOR:
+		forResult, potentialPos, e := p.parseForBlock(tokens, pos)
+		if e != nil {
+			err = e
+			return
+		}
+		result.ForBlock = &forResult
+		pos = potentialPos
+	case IF:
+		ifResult, potentialPos, e := p.parseIfBlock(tokens, pos)
+		if e != nil {
+			err = e
+			return
+		}
+		result.IfBlock = &ifResult
+		pos = potentialPos
+	case DOLLAR:
+		functionResult, potentialPos, e := p.parseBuiltinFunctionCall(tokens, pos)
+		if e != nil {
+			err = e
+			return
+		}
+		result.FunctionNode = &functionResult
+		pos = potentialPos
+	case POUND:
+		delayNode, potentialPos, e := p.parseDelayStatement(tokens, pos)
+		if e != nil {
+			err = e
+			return
+		}
+		result.DelayNode = &delayNode
+		pos = potentialPos
+	case CASE:
+		caseNode, potentialPos, e := p.parseCaseBlock(tokens, pos)
+		if e != nil {
+			err = e
+			return
+		}
+		result.CaseNode = &caseNode
+		pos = potentialPos
+	default:
 		interiorResult, potentialPos, e := p.parseInteriorStatement(tokens, pos)
-		if e == nil {
-			result.InteriorNode = &interiorResult
-			pos = potentialPos
-		} else {
-			forResult, potentialPos, e := p.parseForBlock(tokens, pos)
-			if e == nil {
-				result.ForBlock = &forResult
-				pos = potentialPos
-			} else {
-				ifResult, potentialPos, e := p.parseIfBlock(tokens, pos)
-				if e == nil {
-					result.IfBlock = &ifResult
-					pos = potentialPos
-				} else {
-					functionResult, potentialPos, e := p.parseBuiltinFunctionCall(tokens, pos)
-					if e == nil {
-						result.FunctionNode = &functionResult
-						pos = potentialPos
-					} else {
-						delayNode, potentialPos, e := p.parseDelayStatement(tokens, pos)
-						if e == nil {
-							result.DelayNode = &delayNode
-							pos = potentialPos
-						} else {
-							caseNode, potentialPos, e := p.parseCaseBlock(tokens, pos)
-							if e == nil {
-								result.CaseNode = &caseNode
-								pos = potentialPos
-							} else {
-								err = e
-							}
-						}
-					}
-				}
-			}
+		if e != nil {
+			err = e
+			return
 		}
+		result.InteriorNode = &interiorResult
+		pos = potentialPos
 	}
 	newPos = pos
+	result.Span = p.span(tokens, start, newPos)
 	return
 }
 func (p *Parser) parseAlwaysStatements(tokens []Token, pos int) (result []AlwaysStatement, newPos int, err error) {
@@ -1279,9 +1738,10 @@ func (p *Parser) parseAlwaysStatements(tokens []Token, pos int) (result []Always
 	return
 }
 func (p *Parser) parseGenerate(tokens []Token, pos int) (result GenerateNode, newPos int, err error) {
+	start := pos
 	// <generate> -> GENERATE <generateable_statements> ENDGENERATE
 	// get generate
-	pos, err = p.CheckToken("generate", []string{"generate"}, pos, tokens)
+	pos, err = p.CheckToken("generate", []TokenKind{GENERATE}, pos, tokens)
 	if err != nil {
 		return
 	}
@@ -1297,37 +1757,40 @@ func (p *Parser) parseGenerate(tokens []Token, pos int) (result GenerateNode, ne
 	pos = potentialPos
 
 	// get endgenerate
-	pos, err = p.CheckToken("generate", []string{"endgenerate"}, pos, tokens)
+	pos, err = p.CheckToken("generate", []TokenKind{ENDGENERATE}, pos, tokens)
 	if err != nil {
 		return
 	}
 	pos++
 
 	newPos = pos
+	result.Span = p.span(tokens, start, newPos)
 	return
 }
 
-// <time> -> [TIME] <identifier>
+// <time> -> [EDGE] <identifier>
 func (p *Parser) parseTime(tokens []Token, pos int) (result TimeNode, newPos int, err error) {
+	start := pos
 	// get time, optionally
-	potentialPos, e := p.CheckToken("time", []string{"time"}, pos, tokens)
+	potentialPos, e := p.CheckToken("time", []TokenKind{EDGE}, pos, tokens)
 	if e == nil {
 		result.Time = &tokens[potentialPos]
 		pos = potentialPos + 1
 	}
 
 	// get identifier
-	pos, err = p.CheckToken("time", []string{"identifier"}, pos, tokens)
+	pos, err = p.CheckToken("time", []TokenKind{IDENTIFIER}, pos, tokens)
 	if err != nil {
 		return
 	}
 	result.Identifier = tokens[pos]
 	pos++
 	newPos = pos
+	result.Span = p.span(tokens, start, newPos)
 	return
 }
 
-// <event> -> <time> { OR <time> }
+// <event> -> <time> { (OR | COMMA) <time> }
 func (p *Parser) parseEvent(tokens []Token, pos int) (result []TimeNode, newPos int, err error) {
 	// get time
 	timeNode, pos, err := p.parseTime(tokens, pos)
@@ -1337,21 +1800,15 @@ func (p *Parser) parseEvent(tokens []Token, pos int) (result []TimeNode, newPos
 	result = append(result, timeNode)
 
 	// get other times
-	potentialPos, e := p.CheckToken("event", []string{"identifier"}, pos, tokens)
+	potentialPos, e := p.CheckToken("event", []TokenKind{OR, COMMA}, pos, tokens)
 	for e == nil {
-		// special case because or is technically a valid identifier
-		if tokens[potentialPos].Value != "or" {
-			err = fmt.Errorf("expected 'or' but got '%s'", tokens[potentialPos].Value)
-			return
-		}
-
 		// take the time
 		timeNode, potentialPos, e = p.parseTime(tokens, potentialPos+1)
 		if e == nil {
 			result = append(result, timeNode)
 			pos = potentialPos
 
-			potentialPos, e = p.CheckToken("event", []string{"identifier"}, pos, tokens)
+			potentialPos, e = p.CheckToken("event", []TokenKind{OR, COMMA}, pos, tokens)
 		} else {
 			err = e
 		}
@@ -1362,39 +1819,43 @@ func (p *Parser) parseEvent(tokens []Token, pos int) (result []TimeNode, newPos
 
 // <delay_statement> -> POUND [ LITERAL | <identifier> ]
 func (p *Parser) parseDelayStatement(tokens []Token, pos int) (result DelayNode, newPos int, err error) {
-	pos, err = p.CheckToken("delay", []string{"pound"}, pos, tokens)
+	start := pos
+	pos, err = p.CheckToken("delay", []TokenKind{POUND}, pos, tokens)
 	if err != nil {
 		return
 	}
 	pos++
 
 	// get literal or identifier
-	pos, err = p.CheckToken("delay", []string{"literal", "identifier"}, pos, tokens)
+	pos, err = p.CheckToken("delay", []TokenKind{LITERAL, IDENTIFIER}, pos, tokens)
 	if err != nil {
 		return
 	}
 	result.Amount = tokens[pos]
 	pos++
 	newPos = pos
+	result.Span = p.span(tokens, start, newPos)
 	return
 }
 
 func (p *Parser) parseAlways(tokens []Token, pos int) (result AlwaysNode, newPos int, err error) {
+	start := pos
 	// <always> -> ALWAYS [ AT LPAREN <event> RPAREN ] <alwaysable_statement>
 
 	// get always
-	pos, err = p.CheckToken("always", []string{"always"}, pos, tokens)
+	pos, err = p.CheckToken("always", []TokenKind{ALWAYS}, pos, tokens)
 	if err != nil {
 		return
 	}
+	result.Always = tokens[pos]
 	pos++
 
 	// get at, optionally
-	potentialPos, e := p.CheckToken("always", []string{"at"}, pos, tokens)
+	potentialPos, e := p.CheckToken("always", []TokenKind{AT}, pos, tokens)
 	if e == nil {
 		pos = potentialPos + 1
 		// get lparen
-		pos, err = p.CheckToken("always", []string{"lparen"}, pos, tokens)
+		pos, err = p.CheckToken("always", []TokenKind{LPAREN}, pos, tokens)
 		if err != nil {
 			return
 		}
@@ -1409,7 +1870,7 @@ func (p *Parser) parseAlways(tokens []Token, pos int) (result AlwaysNode, newPos
 		result.Times = event
 
 		// get rparen
-		pos, err = p.CheckToken("always", []string{"rparen"}, pos, tokens)
+		pos, err = p.CheckToken("always", []TokenKind{RPAREN}, pos, tokens)
 		if err != nil {
 			return
 		}
@@ -1425,21 +1886,23 @@ func (p *Parser) parseAlways(tokens []Token, pos int) (result AlwaysNode, newPos
 	result.Statement = alwaysStatement
 	pos = potentialPos
 	newPos = pos
+	result.Span = p.span(tokens, start, newPos)
 	return
 }
 
 func (p *Parser) parseBuiltinFunctionCall(tokens []Token, pos int) (result FunctionNode, newPos int, err error) {
+	start := pos
 	// <builtin_function_call> -> DOLLAR <identifier> LPAREN <expr> { COMMA <expr> } RPAREN SEMICOLON
 
 	// get dollar
-	pos, err = p.CheckToken("builtin function call", []string{"dollar"}, pos, tokens)
+	pos, err = p.CheckToken("builtin function call", []TokenKind{DOLLAR}, pos, tokens)
 	if err != nil {
 		return
 	}
 	pos++
 
 	// get identifier
-	pos, err = p.CheckToken("builtin function call", []string{"identifier"}, pos, tokens)
+	pos, err = p.CheckToken("builtin function call", []TokenKind{IDENTIFIER}, pos, tokens)
 	if err != nil {
 		return
 	}
@@ -1447,7 +1910,7 @@ func (p *Parser) parseBuiltinFunctionCall(tokens []Token, pos int) (result Funct
 	pos++
 
 	// get lparen, optionally
-	potentialPos, e := p.CheckToken("builtin function call", []string{"lparen"}, pos, tokens)
+	potentialPos, e := p.CheckToken("builtin function call", []TokenKind{LPAREN}, pos, tokens)
 	if e == nil {
 		pos = potentialPos + 1
 
@@ -1461,7 +1924,7 @@ func (p *Parser) parseBuiltinFunctionCall(tokens []Token, pos int) (result Funct
 		pos = potentialPos
 
 		// get any other expressions
-		potentialPos, e = p.CheckToken("builtin function call", []string{"comma"}, pos, tokens)
+		potentialPos, e = p.CheckToken("builtin function call", []TokenKind{COMMA}, pos, tokens)
 		for e == nil {
 			// take the expr
 			expr, potentialPos, e = p.parseExpression(tokens, potentialPos+1)
@@ -1472,11 +1935,11 @@ func (p *Parser) parseBuiltinFunctionCall(tokens []Token, pos int) (result Funct
 				err = e
 				return
 			}
-			potentialPos, e = p.CheckToken("builtin function call", []string{"comma"}, pos, tokens)
+			potentialPos, e = p.CheckToken("builtin function call", []TokenKind{COMMA}, pos, tokens)
 		}
 
 		// get rparen
-		pos, err = p.CheckToken("builtin function call", []string{"rparen"}, pos, tokens)
+		pos, err = p.CheckToken("builtin function call", []TokenKind{RPAREN}, pos, tokens)
 		if err != nil {
 			return
 		}
@@ -1484,27 +1947,29 @@ func (p *Parser) parseBuiltinFunctionCall(tokens []Token, pos int) (result Funct
 	}
 
 	// get semicolon
-	pos, err = p.CheckToken("builtin function call", []string{"semicolon"}, pos, tokens)
+	pos, err = p.CheckToken("builtin function call", []TokenKind{SEMICOLON}, pos, tokens)
 	if err != nil {
 		return
 	}
 	pos++
 	newPos = pos
+	result.Span = p.span(tokens, start, newPos)
 	return
 }
 
 func (p *Parser) parseDefParamNode(tokens []Token, pos int) (result DefParamNode, newPos int, err error) {
+	start := pos
 	// <def_param> -> DEFPARAM <identifier> { DOT <identifier> } EQUAL <expr> SEMICOLON
 
 	// get defparam
-	pos, err = p.CheckToken("def param", []string{"defparam"}, pos, tokens)
+	pos, err = p.CheckToken("def param", []TokenKind{DEFPARAM}, pos, tokens)
 	if err != nil {
 		return
 	}
 	pos++
 
 	// get identifier
-	pos, err = p.CheckToken("def param", []string{"identifier"}, pos, tokens)
+	pos, err = p.CheckToken("def param", []TokenKind{IDENTIFIER}, pos, tokens)
 	if err != nil {
 		return
 	}
@@ -1512,20 +1977,20 @@ func (p *Parser) parseDefParamNode(tokens []Token, pos int) (result DefParamNode
 	pos++
 
 	// get any other identifiers
-	potentialPos, e := p.CheckToken("def param", []string{"dot"}, pos, tokens)
+	potentialPos, e := p.CheckToken("def param", []TokenKind{DOT}, pos, tokens)
 	for e == nil {
 		// take the identifier
-		pos, err = p.CheckToken("def param", []string{"identifier"}, potentialPos+1, tokens)
+		pos, err = p.CheckToken("def param", []TokenKind{IDENTIFIER}, potentialPos+1, tokens)
 		if err != nil {
 			return
 		}
 		result.Identifiers = append(result.Identifiers, tokens[pos])
 		pos++
-		potentialPos, e = p.CheckToken("def param", []string{"dot"}, pos, tokens)
+		potentialPos, e = p.CheckToken("def param", []TokenKind{DOT}, pos, tokens)
 	}
 
 	// get equal
-	pos, err = p.CheckToken("def param", []string{"equal"}, pos, tokens)
+	pos, err = p.CheckToken("def param", []TokenKind{EQUAL}, pos, tokens)
 	if err != nil {
 		return
 	}
@@ -1541,18 +2006,20 @@ func (p *Parser) parseDefParamNode(tokens []Token, pos int) (result DefParamNode
 	pos = potentialPos
 
 	// get semicolon
-	pos, err = p.CheckToken("def param", []string{"semicolon"}, pos, tokens)
+	pos, err = p.CheckToken("def param", []TokenKind{SEMICOLON}, pos, tokens)
 	if err != nil {
 		return
 	}
 	pos++
 	newPos = pos
+	result.Span = p.span(tokens, start, newPos)
 	return
 }
 
 func (p *Parser) parseInitial(tokens []Token, pos int) (result InitialNode, newPos int, err error) {
+	start := pos
 	// <initial> -> INITIAL <alwaysable_statement>
-	pos, err = p.CheckToken("initial", []string{"initial"}, pos, tokens)
+	pos, err = p.CheckToken("initial", []TokenKind{INITIAL}, pos, tokens)
 	if err != nil {
 		return
 	}
@@ -1568,84 +2035,113 @@ func (p *Parser) parseInitial(tokens []Token, pos int) (result InitialNode, newP
 	pos = potentialPos
 
 	newPos = pos
+	result.Span = p.span(tokens, start, newPos)
 	return
 }
 
 func (p *Parser) parseInteriorStatement(tokens []Token, pos int) (result InteriorNode, newPos int, err error) {
-	// it could be either a declaration or module_application or assignment or generate
-
-	// check if it's a declaration
-	declarationNode, potentialPos, e := p.parseDeclarationNode(tokens, pos)
-	if e == nil {
-		// success!
-		result.DeclarationNode = &declarationNode
+	start := pos
+	// statements that start with a keyword can only be one thing, so the keyword picks it,
+	// and otherwise it could be either a declaration or module_application or assignment
+	switch p.peek(tokens, pos) {
+	case GENERATE:
+		generateNode, potentialPos, e := p.parseGenerate(tokens, pos)
+		if e != nil {
+			err = e
+			return
+		}
+		result.GenerateNode = &generateNode
 		pos = potentialPos
-	} else {
-		// check if it's a module application
-		moduleApplicationNode, potentialPos, e := p.parseModuleApplication(tokens, pos)
+	case ALWAYS:
+		alwaysNode, potentialPos, e := p.parseAlways(tokens, pos)
+		if e != nil {
+			err = e
+			return
+		}
+		result.AlwaysNode = &alwaysNode
+		pos = potentialPos
+	case DEFPARAM:
+		defParamNode, potentialPos, e := p.parseDefParamNode(tokens, pos)
+		if e != nil {
+			err = e
+			return
+		}
+		result.DefParamNode = &defParamNode
+		pos = potentialPos
+	case INITIAL:
+		initialNode, potentialPos, e := p.parseInitial(tokens, pos)
+		if e != nil {
+			err = e
+			return
+		}
+		result.InitialNode = &initialNode
+		pos = potentialPos
+	case DEFINE, UNDEF, DEFAULT_NETTYPE, TIMESCALE, INCLUDE, DIRECTIVE:
+		directiveNode, potentialPos, e := p.parseDirective(tokens, pos)
+		if e != nil {
+			err = e
+			return
+		}
+		result.DirectiveNode = directiveNode
+		pos = potentialPos
+	case TASK:
+		taskNode, potentialPos, e := p.parseTask(tokens, pos)
+		if e != nil {
+			err = e
+			return
+		}
+		result.TaskNode = &taskNode
+		pos = potentialPos
+	case IMPORT:
+		importNode, potentialPos, e := p.parseImport(tokens, pos)
+		if e != nil {
+			err = e
+			return
+		}
+		result.ImportNode = &importNode
+		pos = potentialPos
+	case TYPEDEF:
+		typedefNode, potentialPos, e := p.parseTypedef(tokens, pos)
+		if e != nil {
+			err = e
+			return
+		}
+		result.TypedefNode = &typedefNode
+		pos = potentialPos
+	default:
+		// check if it's a declaration
+		declarationNode, potentialPos, e := p.parseDeclarationNode(tokens, pos)
 		if e == nil {
 			// success!
-			result.ModuleApplicationNode = &moduleApplicationNode
+			result.DeclarationNode = &declarationNode
 			pos = potentialPos
 		} else {
-			// check if it's an assignment
-			assignmentNode, potentialPos, e := p.parseAssignmentNode(tokens, pos)
+			// check if it's a module application
+			moduleApplicationNode, potentialPos, e := p.parseModuleApplication(tokens, pos)
 			if e == nil {
 				// success!
-				result.AssignmentNode = &assignmentNode
+				result.ModuleApplicationNode = &moduleApplicationNode
 				pos = potentialPos
 			} else {
-				// check if it's a generate
-				generateNode, potentialPos, e := p.parseGenerate(tokens, pos)
-				if e == nil {
-					result.GenerateNode = &generateNode
-					pos = potentialPos
-				} else {
-					// check if it's an always
-					alwaysNode, potentialPos, e := p.parseAlways(tokens, pos)
-					if e == nil {
-						result.AlwaysNode = &alwaysNode
-						pos = potentialPos
-					} else {
-						// check if it's a defparam
-						defParamNode, potentialPos, e := p.parseDefParamNode(tokens, pos)
-						if e == nil {
-							result.DefParamNode = &defParamNode
-							pos = potentialPos
-						} else {
-							// check if it's an initial
-							initialNode, potentialPos, e := p.parseInitial(tokens, pos)
-							if e == nil {
-								result.InitialNode = &initialNode
-								pos = potentialPos
-							} else {
-								// check if it's a directive
-								directiveNode, potentialPos, e := p.parseDirective(tokens, pos)
-								if e == nil {
-									result.DirectiveNode = directiveNode
-									pos = potentialPos
-								} else {
-									// check if it's a task
-									taskNode, potentialPos, e := p.parseTask(tokens, pos)
-									if e == nil {
-										result.TaskNode = &taskNode
-										pos = potentialPos
-									} else {
-										err = e
-									}
-								}
-							}
-						}
-					}
+				// check if it's an assignment
+				assignmentNode, potentialPos, e := p.parseAssignmentNode(tokens, pos)
+				if e != nil {
+					err = e
+					return
 				}
+				// success!
+				result.AssignmentNode = &assignmentNode
+				pos = potentialPos
 			}
 		}
 	}
 
 	newPos = pos
+	result.Span = p.span(tokens, start, newPos)
 	return
 }
 func (p *Parser) parseModuleInterior(tokens []Token, pos int) (result []InteriorNode, newPos int, err error) {
+	newPos = pos
 	for {
 		nestedStatement, potentialPos, e := p.parseInteriorStatement(tokens, pos)
 		if e != nil {
@@ -1657,20 +2153,21 @@ func (p *Parser) parseModuleInterior(tokens []Token, pos int) (result []Interior
 	}
 }
 func (p *Parser) parseTask(tokens []Token, pos int) (result TaskNode, newPos int, err error) {
-	pos, err = p.CheckToken("task", []string{"task"}, pos, tokens)
+	start := pos
+	pos, err = p.CheckToken("task", []TokenKind{TASK}, pos, tokens)
 	if err != nil {
 		return
 	}
 	pos++
 
-	pos, err = p.CheckToken("task", []string{"identifier"}, pos, tokens)
+	pos, err = p.CheckToken("task", []TokenKind{IDENTIFIER}, pos, tokens)
 	if err != nil {
 		return
 	}
 	result.Identifier = tokens[pos]
 	pos++
 
-	pos, err = p.CheckToken("task", []string{"semicolon"}, pos, tokens)
+	pos, err = p.CheckToken("task", []TokenKind{SEMICOLON}, pos, tokens)
 	if err != nil {
 		return
 	}
@@ -1682,12 +2179,14 @@ func (p *Parser) parseTask(tokens []Token, pos int) (result TaskNode, newPos int
 	}
 
 	// get endtask
-	pos, err = p.CheckToken("task", []string{"endtask"}, pos, tokens)
+	pos, err = p.CheckToken("task", []TokenKind{ENDTASK}, pos, tokens)
 	if err != nil {
 		return
 	}
 	pos++
 	newPos = pos
+	result.Span = p.span(tokens, start, newPos)
+	result.Comments = p.comments(tokens, start, newPos)
 	return
 }
 
@@ -1695,77 +2194,159 @@ func (p *Parser) parseTask(tokens []Token, pos int) (result TaskNode, newPos int
 // Module Definition Section
 // ==============================
 
-// Returns a list of ports, and newPos is the position after the list
-func (p *Parser) parsePorts(tokens []Token, pos int) (result []Token, newPos int, err error) {
-	// ports is just the list of identifiers
+// <port> -> <type> <identifier> | <identifier> [DOT <identifier>] <identifier> | <identifier>
+func (p *Parser) parsePort(tokens []Token, pos int) (result PortNode, newPos int, err error) {
+	start := pos
+	// try a regular ANSI-style declaration
+	typeNode, potentialPos, e := p.parseTypeNode(tokens, pos)
+	if e == nil {
+		result.Type = &typeNode
+		pos = potentialPos
+	} else if p.language == SystemVerilog {
+		// try an interface port
+		interfaceNode, potentialPos, e := p.parseInterfacePort(tokens, pos)
+		if e == nil {
+			result.Interface = &interfaceNode
+			pos = potentialPos
+		}
+	}
+
+	// get the name
+	pos, err = p.CheckToken("port", []TokenKind{IDENTIFIER}, pos, tokens)
+	if err != nil {
+		return
+	}
+	result.Identifier = tokens[pos]
+	pos++
+	newPos = pos
+	result.Span = p.span(tokens, start, newPos)
+	result.Comments = p.comments(tokens, start, newPos)
+	return
+}
 
-	// get the first identifier
-	pos, err = p.CheckToken("ports", []string{"identifier"}, pos, tokens)
+// <interface_port> -> <identifier> [DOT <identifier>], followed by the name of the port
+func (p *Parser) parseInterfacePort(tokens []Token, pos int) (result InterfacePortNode, newPos int, err error) {
+	start := pos
+	pos, err = p.CheckToken("interface port", []TokenKind{IDENTIFIER}, pos, tokens)
 	if err != nil {
 		return
 	}
-	result = append(result, tokens[pos])
+	result.Interface = tokens[pos]
 	pos++
 
-	// now take the rest
-	potentialPos, e := p.CheckToken("ports", []string{"comma"}, pos, tokens)
-	for e == nil {
-		pos, err = p.CheckToken("ports", []string{"identifier"}, potentialPos+1, tokens)
+	// get the modport, optionally
+	potentialPos, e := p.CheckToken("interface port", []TokenKind{DOT}, pos, tokens)
+	if e == nil {
+		pos, err = p.CheckToken("interface port", []TokenKind{IDENTIFIER}, potentialPos+1, tokens)
 		if err != nil {
 			return
 		}
-		result = append(result, tokens[pos])
+		result.Modport = &tokens[pos]
 		pos++
-		potentialPos, e = p.CheckToken("ports", []string{"comma"}, pos, tokens)
+	}
+
+	// the name of the port needs to come next
+	_, err = p.CheckToken("interface port", []TokenKind{IDENTIFIER}, pos, tokens)
+	if err != nil {
+		return
 	}
 	newPos = pos
+	result.Span = p.span(tokens, start, newPos)
 	return
 }
 
 // Returns a list of ports, and newPos is the position after the list
-func (p *Parser) parsePortList(tokens []Token, pos int) (result PortListNode, newPos int, err error) {
-	// take lparen
-	pos, err = p.CheckToken("port list", []string{"lparen"}, pos, tokens)
+func (p *Parser) parsePorts(tokens []Token, pos int) (result PortListNode, newPos int, err error) {
+	start := pos
+	// <ports> -> <port> { COMMA <port> }
+	port, pos, err := p.parsePort(tokens, pos)
 	if err != nil {
 		return
 	}
-	pos++
+	ports := []PortNode{port}
+
+	// now take the rest
+	potentialPos, e := p.CheckToken("ports", []TokenKind{COMMA}, pos, tokens)
+	for e == nil {
+		port, pos, err = p.parsePort(tokens, potentialPos+1)
+		if err != nil {
+			return
+		}
+		// ANSI-style ports without a type share the type of the previous port
+		if port.Type == nil && port.Interface == nil {
+			port.Type = ports[len(ports)-1].Type
+			port.Interface = ports[len(ports)-1].Interface
+		}
+		ports = append(ports, port)
+		potentialPos, e = p.CheckToken("ports", []TokenKind{COMMA}, pos, tokens)
+	}
+
+	for _, port := range ports {
+		result.Ports = append(result.Ports, port.Identifier)
+	}
+	if ports[0].Type != nil || ports[0].Interface != nil {
+		result.Declarations = ports
+	}
+	newPos = pos
+	result.Span = p.span(tokens, start, newPos)
+	return
+}
+
+// Returns a list of ports, and newPos is the position after the list
+func (p *Parser) parsePortList(tokens []Token, pos int) (result PortListNode, newPos int, err error) {
+	start := pos
+	// take lparen
+	pos, err = p.CheckToken("port list", []TokenKind{LPAREN}, pos, tokens)
+	if err != nil {
+		return
+	}
+	pos++
 
 	// get ports if any
 	ports, potentialPos, e := p.parsePorts(tokens, pos)
 	if e == nil {
 		// got ports successfully
-		result.Ports = ports
+		result = ports
 		pos = potentialPos
 	}
 
 	// then get the rparen
-	pos, err = p.CheckToken("port list", []string{"rparen"}, pos, tokens)
+	pos, err = p.CheckToken("port list", []TokenKind{RPAREN}, pos, tokens)
 	if err != nil {
 		return
 	}
 	pos++
 
 	newPos = pos
+	result.Span = p.span(tokens, start, newPos)
 	return
 }
 
 func (p *Parser) parseModule(tokens []Token, pos int) (result ModuleNode, newPos int, err error) {
-	// MODULE <identifier> [<port_list>] SEMICOLON <interior> ENDMODULE [SEMICOLON]
-	pos, err = p.CheckToken("module", []string{"module"}, pos, tokens)
+	start := pos
+	// MODULE <identifier> { <import> } [<port_list>] SEMICOLON <interior> ENDMODULE [SEMICOLON]
+	pos, err = p.CheckToken("module", []TokenKind{MODULE}, pos, tokens)
 	if err != nil {
 		return
 	}
 	pos++
 
 	// get the identifier
-	pos, err = p.CheckToken("module", []string{"identifier"}, pos, tokens)
+	pos, err = p.CheckToken("module", []TokenKind{IDENTIFIER}, pos, tokens)
 	if err != nil {
 		return
 	}
 	result.Identifier = tokens[pos]
 	pos++
 
+	// get any imports
+	importNode, potentialPos, e := p.parseImport(tokens, pos)
+	for e == nil {
+		result.Imports = append(result.Imports, importNode)
+		pos = potentialPos
+		importNode, potentialPos, e = p.parseImport(tokens, pos)
+	}
+
 	// get the port list if any
 	portList, potentialPos, e := p.parsePortList(tokens, pos)
 	if e == nil {
@@ -1775,7 +2356,7 @@ func (p *Parser) parseModule(tokens []Token, pos int) (result ModuleNode, newPos
 	}
 
 	// get the semicolon
-	pos, err = p.CheckToken("module", []string{"semicolon"}, pos, tokens)
+	pos, err = p.CheckToken("module", []TokenKind{SEMICOLON}, pos, tokens)
 	if err != nil {
 		return
 	}
@@ -1793,74 +2374,634 @@ func (p *Parser) parseModule(tokens []Token, pos int) (result ModuleNode, newPos
 	}
 
 	// get the endmodule
-	pos, err = p.CheckToken("module", []string{"endmodule"}, pos, tokens)
+	pos, err = p.CheckToken("module", []TokenKind{ENDMODULE}, pos, tokens)
 	if err != nil {
 		return
 	}
 	pos++
 
 	// get the semicolon, optionally
-	potentialPos, e = p.CheckToken("module", []string{"semicolon"}, pos, tokens)
+	potentialPos, e = p.CheckToken("module", []TokenKind{SEMICOLON}, pos, tokens)
+	if e == nil {
+		pos = potentialPos
+	}
+	pos++
+	newPos = pos
+	result.Span = p.span(tokens, start, newPos)
+	result.Comments = p.comments(tokens, start, newPos)
+	return
+}
+
+// <modport_port> -> DIRECTION <identifier>
+func (p *Parser) parseModportPort(tokens []Token, pos int, direction *Token) (result ModportPortNode, newPos int, err error) {
+	start := pos
+	// the direction can be omitted if it's the same as the previous port's
+	potentialPos, e := p.CheckToken("modport port", []TokenKind{DIRECTION}, pos, tokens)
+	if e == nil {
+		direction = &tokens[potentialPos]
+		pos = potentialPos + 1
+	} else if direction == nil {
+		err = e
+		return
+	}
+	result.Direction = *direction
+
+	pos, err = p.CheckToken("modport port", []TokenKind{IDENTIFIER}, pos, tokens)
+	if err != nil {
+		return
+	}
+	result.Identifier = tokens[pos]
+	pos++
+	newPos = pos
+	result.Span = p.span(tokens, start, newPos)
+	return
+}
+
+// <modport> -> MODPORT <identifier> LPAREN <modport_port> { COMMA <modport_port> } RPAREN SEMICOLON
+func (p *Parser) parseModport(tokens []Token, pos int) (result ModportNode, newPos int, err error) {
+	start := pos
+	pos, err = p.CheckToken("modport", []TokenKind{MODPORT}, pos, tokens)
+	if err != nil {
+		return
+	}
+	pos++
+
+	// get the name
+	pos, err = p.CheckToken("modport", []TokenKind{IDENTIFIER}, pos, tokens)
+	if err != nil {
+		return
+	}
+	result.Identifier = tokens[pos]
+	pos++
+
+	// get lparen
+	pos, err = p.CheckToken("modport", []TokenKind{LPAREN}, pos, tokens)
+	if err != nil {
+		return
+	}
+	pos++
+
+	// get the ports
+	port, pos, err := p.parseModportPort(tokens, pos, nil)
+	if err != nil {
+		return
+	}
+	result.Ports = append(result.Ports, port)
+	potentialPos, e := p.CheckToken("modport", []TokenKind{COMMA}, pos, tokens)
+	for e == nil {
+		port, pos, err = p.parseModportPort(tokens, potentialPos+1, &port.Direction)
+		if err != nil {
+			return
+		}
+		result.Ports = append(result.Ports, port)
+		potentialPos, e = p.CheckToken("modport", []TokenKind{COMMA}, pos, tokens)
+	}
+
+	// get rparen
+	pos, err = p.CheckToken("modport", []TokenKind{RPAREN}, pos, tokens)
+	if err != nil {
+		return
+	}
+	pos++
+
+	// get semicolon
+	pos, err = p.CheckToken("modport", []TokenKind{SEMICOLON}, pos, tokens)
+	if err != nil {
+		return
+	}
+	pos++
+	newPos = pos
+	result.Span = p.span(tokens, start, newPos)
+	result.Comments = p.comments(tokens, start, newPos)
+	return
+}
+
+func (p *Parser) parseInterfaceInterior(tokens []Token, pos int) (result []InteriorNode, newPos int, err error) {
+	newPos = pos
+	for {
+		// modports can only be inside interfaces
+		modportNode, potentialPos, e := p.parseModport(tokens, pos)
+		if e == nil {
+			result = append(result, InteriorNode{ModportNode: &modportNode, Span: modportNode.Span})
+			pos = potentialPos
+			newPos = pos
+			continue
+		}
+
+		nestedStatement, potentialPos, e := p.parseInteriorStatement(tokens, pos)
+		if e != nil {
+			return
+		}
+		result = append(result, nestedStatement)
+		pos = potentialPos
+		newPos = pos
+	}
+}
+
+func (p *Parser) parseInterface(tokens []Token, pos int) (result InterfaceNode, newPos int, err error) {
+	start := pos
+	// INTERFACE <identifier> [<port_list>] SEMICOLON <interface_interior> ENDINTERFACE
+	pos, err = p.CheckToken("interface", []TokenKind{INTERFACE}, pos, tokens)
+	if err != nil {
+		return
+	}
+	pos++
+
+	// get the identifier
+	pos, err = p.CheckToken("interface", []TokenKind{IDENTIFIER}, pos, tokens)
+	if err != nil {
+		return
+	}
+	result.Identifier = tokens[pos]
+	pos++
+
+	// get the port list if any
+	portList, potentialPos, e := p.parsePortList(tokens, pos)
 	if e == nil {
+		result.PortList = portList
 		pos = potentialPos
 	}
+
+	// get the semicolon
+	pos, err = p.CheckToken("interface", []TokenKind{SEMICOLON}, pos, tokens)
+	if err != nil {
+		return
+	}
+	pos++
+
+	// get the interior
+	result.Interior, pos, err = p.parseInterfaceInterior(tokens, pos)
+	if err != nil {
+		return
+	}
+
+	// get the endinterface
+	pos, err = p.CheckToken("interface", []TokenKind{ENDINTERFACE}, pos, tokens)
+	if err != nil {
+		return
+	}
 	pos++
+
+	// get the name after endinterface, optionally
+	potentialPos, e = p.CheckToken("interface", []TokenKind{COLON}, pos, tokens)
+	if e == nil {
+		pos, err = p.CheckToken("interface", []TokenKind{IDENTIFIER}, potentialPos+1, tokens)
+		if err != nil {
+			return
+		}
+		pos++
+	}
 	newPos = pos
+	result.Span = p.span(tokens, start, newPos)
+	result.Comments = p.comments(tokens, start, newPos)
 	return
 }
 
+// ==============================
+// Package Section
+// ==============================
+
+// <import_item> -> <scope> (<identifier> | STAR)
+func (p *Parser) parseImportItem(tokens []Token, pos int) (result ImportItemNode, newPos int, err error) {
+	start := pos
+	result.Package, pos, err = p.parseScope(tokens, pos)
+	if err != nil {
+		return
+	}
+
+	pos, err = p.CheckToken("import", []TokenKind{IDENTIFIER, OPERATOR}, pos, tokens)
+	if err != nil {
+		return
+	}
+	if tokens[pos].Type == OPERATOR && tokens[pos].Value != "*" {
+		err = p.newErrorFrom("import", []string{"identifier", "*"}, pos, tokens)
+		return
+	}
+	result.Item = tokens[pos]
+	pos++
+	newPos = pos
+	result.Span = p.span(tokens, start, newPos)
+	return
+}
+
+// <import> -> IMPORT <import_item> { COMMA <import_item> } SEMICOLON
+func (p *Parser) parseImport(tokens []Token, pos int) (result ImportNode, newPos int, err error) {
+	start := pos
+	pos, err = p.CheckToken("import", []TokenKind{IMPORT}, pos, tokens)
+	if err != nil {
+		return
+	}
+	pos++
+
+	item, pos, err := p.parseImportItem(tokens, pos)
+	if err != nil {
+		return
+	}
+	result.Items = append(result.Items, item)
+	potentialPos, e := p.CheckToken("import", []TokenKind{COMMA}, pos, tokens)
+	for e == nil {
+		item, pos, err = p.parseImportItem(tokens, potentialPos+1)
+		if err != nil {
+			return
+		}
+		result.Items = append(result.Items, item)
+		potentialPos, e = p.CheckToken("import", []TokenKind{COMMA}, pos, tokens)
+	}
+
+	pos, err = p.CheckToken("import", []TokenKind{SEMICOLON}, pos, tokens)
+	if err != nil {
+		return
+	}
+	pos++
+	newPos = pos
+	result.Span = p.span(tokens, start, newPos)
+	return
+}
+
+// <enum_member> -> <identifier> [EQUAL <expr>]
+func (p *Parser) parseEnumMember(tokens []Token, pos int) (result EnumMemberNode, newPos int, err error) {
+	start := pos
+	pos, err = p.CheckToken("enum member", []TokenKind{IDENTIFIER}, pos, tokens)
+	if err != nil {
+		return
+	}
+	result.Identifier = tokens[pos]
+	pos++
+
+	// get the value, optionally
+	potentialPos, e := p.CheckToken("enum member", []TokenKind{EQUAL}, pos, tokens)
+	if e == nil {
+		value, potentialPos, e := p.parseExpression(tokens, potentialPos+1)
+		if e != nil {
+			err = e
+			return
+		}
+		result.Value = &value
+		pos = potentialPos
+	}
+	newPos = pos
+	result.Span = p.span(tokens, start, newPos)
+	result.Comments = p.comments(tokens, start, newPos)
+	return
+}
+
+// <enum> -> ENUM [<type>] LCURL <enum_member> { COMMA <enum_member> } RCURL
+func (p *Parser) parseEnum(tokens []Token, pos int) (result EnumNode, newPos int, err error) {
+	start := pos
+	pos, err = p.CheckToken("enum", []TokenKind{ENUM}, pos, tokens)
+	if err != nil {
+		return
+	}
+	pos++
+
+	// get the base type, optionally
+	typeNode, potentialPos, e := p.parseTypeNode(tokens, pos)
+	if e == nil {
+		result.Type = &typeNode
+		pos = potentialPos
+	}
+
+	pos, err = p.CheckToken("enum", []TokenKind{LCURL}, pos, tokens)
+	if err != nil {
+		return
+	}
+	pos++
+
+	member, pos, err := p.parseEnumMember(tokens, pos)
+	if err != nil {
+		return
+	}
+	result.Members = append(result.Members, member)
+	potentialPos, e = p.CheckToken("enum", []TokenKind{COMMA}, pos, tokens)
+	for e == nil {
+		member, pos, err = p.parseEnumMember(tokens, potentialPos+1)
+		if err != nil {
+			return
+		}
+		result.Members = append(result.Members, member)
+		potentialPos, e = p.CheckToken("enum", []TokenKind{COMMA}, pos, tokens)
+	}
+
+	pos, err = p.CheckToken("enum", []TokenKind{RCURL}, pos, tokens)
+	if err != nil {
+		return
+	}
+	pos++
+	newPos = pos
+	result.Span = p.span(tokens, start, newPos)
+	return
+}
+
+// <struct> -> STRUCT [PACKED] LCURL { <declaration> } RCURL
+func (p *Parser) parseStruct(tokens []Token, pos int) (result StructNode, newPos int, err error) {
+	start := pos
+	pos, err = p.CheckToken("struct", []TokenKind{STRUCT}, pos, tokens)
+	if err != nil {
+		return
+	}
+	pos++
+
+	// get packed, optionally
+	potentialPos, e := p.CheckToken("struct", []TokenKind{PACKED}, pos, tokens)
+	if e == nil {
+		result.Packed = true
+		pos = potentialPos + 1
+	}
+
+	pos, err = p.CheckToken("struct", []TokenKind{LCURL}, pos, tokens)
+	if err != nil {
+		return
+	}
+	pos++
+
+	// get the members
+	declarationNode, potentialPos, e := p.parseDeclarationNode(tokens, pos)
+	for e == nil {
+		result.Members = append(result.Members, declarationNode)
+		pos = potentialPos
+		declarationNode, potentialPos, e = p.parseDeclarationNode(tokens, pos)
+	}
+
+	pos, err = p.CheckToken("struct", []TokenKind{RCURL}, pos, tokens)
+	if err != nil {
+		return
+	}
+	pos++
+	newPos = pos
+	result.Span = p.span(tokens, start, newPos)
+	return
+}
+
+// <typedef> -> TYPEDEF (<enum> | <struct> | <type>) <identifier> SEMICOLON
+func (p *Parser) parseTypedef(tokens []Token, pos int) (result TypedefNode, newPos int, err error) {
+	start := pos
+	pos, err = p.CheckToken("typedef", []TokenKind{TYPEDEF}, pos, tokens)
+	if err != nil {
+		return
+	}
+	pos++
+
+	// get the aliased type
+	enumNode, potentialPos, e := p.parseEnum(tokens, pos)
+	if e == nil {
+		result.Enum = &enumNode
+		pos = potentialPos
+	} else {
+		structNode, potentialPos, e := p.parseStruct(tokens, pos)
+		if e == nil {
+			result.Struct = &structNode
+			pos = potentialPos
+		} else {
+			typeNode, potentialPos, e := p.parseTypeNode(tokens, pos)
+			if e != nil {
+				err = e
+				return
+			}
+			result.Type = &typeNode
+			pos = potentialPos
+		}
+	}
+
+	// get the name
+	pos, err = p.CheckToken("typedef", []TokenKind{IDENTIFIER}, pos, tokens)
+	if err != nil {
+		return
+	}
+	result.Identifier = tokens[pos]
+	pos++
+
+	pos, err = p.CheckToken("typedef", []TokenKind{SEMICOLON}, pos, tokens)
+	if err != nil {
+		return
+	}
+	pos++
+	newPos = pos
+	result.Span = p.span(tokens, start, newPos)
+	result.Comments = p.comments(tokens, start, newPos)
+	return
+}
+
+func (p *Parser) parsePackage(tokens []Token, pos int) (result PackageNode, newPos int, err error) {
+	start := pos
+	// PACKAGE <identifier> SEMICOLON <interior> ENDPACKAGE [COLON <identifier>]
+	pos, err = p.CheckToken("package", []TokenKind{PACKAGE}, pos, tokens)
+	if err != nil {
+		return
+	}
+	pos++
+
+	// get the identifier
+	pos, err = p.CheckToken("package", []TokenKind{IDENTIFIER}, pos, tokens)
+	if err != nil {
+		return
+	}
+	result.Identifier = tokens[pos]
+	pos++
+
+	// get the semicolon
+	pos, err = p.CheckToken("package", []TokenKind{SEMICOLON}, pos, tokens)
+	if err != nil {
+		return
+	}
+	pos++
+
+	// get the interior
+	result.Interior, pos, err = p.parseModuleInterior(tokens, pos)
+	if err != nil {
+		return
+	}
+
+	// get the endpackage
+	pos, err = p.CheckToken("package", []TokenKind{ENDPACKAGE}, pos, tokens)
+	if err != nil {
+		return
+	}
+	pos++
+
+	// get the name after endpackage, optionally
+	potentialPos, e := p.CheckToken("package", []TokenKind{COLON}, pos, tokens)
+	if e == nil {
+		pos, err = p.CheckToken("package", []TokenKind{IDENTIFIER}, potentialPos+1, tokens)
+		if err != nil {
+			return
+		}
+		pos++
+	}
+	newPos = pos
+	result.Span = p.span(tokens, start, newPos)
+	result.Comments = p.comments(tokens, start, newPos)
+	return
+}
+
+// Typedefs returns all the typedefs declared in the package
+func (p PackageNode) Typedefs() []TypedefNode {
+	typedefs := []TypedefNode{}
+	for _, statement := range p.Interior {
+		if statement.TypedefNode != nil {
+			typedefs = append(typedefs, *statement.TypedefNode)
+		}
+	}
+	return typedefs
+}
+
+// Typedef returns the typedef with the given name, if there is one
+func (p PackageNode) Typedef(name string) (TypedefNode, bool) {
+	for _, typedef := range p.Typedefs() {
+		if typedef.Identifier.Name() == name {
+			return typedef, true
+		}
+	}
+	return TypedefNode{}, false
+}
+
+// Symbols returns the names of everything the package makes available to importers:
+// its variables and parameters, typedefs, enum literals, and tasks
+func (p PackageNode) Symbols() map[string]bool {
+	symbols := map[string]bool{}
+	for _, statement := range p.Interior {
+		if statement.DeclarationNode != nil {
+			for _, variable := range statement.DeclarationNode.Variables {
+				symbols[variable.Identifier.Name()] = true
+			}
+		} else if statement.TypedefNode != nil {
+			symbols[statement.TypedefNode.Identifier.Name()] = true
+			if statement.TypedefNode.Enum != nil {
+				for _, member := range statement.TypedefNode.Enum.Members {
+					symbols[member.Identifier.Name()] = true
+				}
+			}
+		} else if statement.TaskNode != nil {
+			symbols[statement.TaskNode.Identifier.Name()] = true
+		}
+	}
+	return symbols
+}
+
+// Member returns the declaration of the struct member with the given name, or nil if there isn't one
+func (s StructNode) Member(name string) *DeclarationNode {
+	for idx, member := range s.Members {
+		for _, variable := range member.Variables {
+			if variable.Identifier.Name() == name {
+				return &s.Members[idx]
+			}
+		}
+	}
+	return nil
+}
+
+// Modport returns the modport with the given name, or nil if there isn't one
+func (i InterfaceNode) Modport(name string) *ModportNode {
+	for _, statement := range i.Interior {
+		if statement.ModportNode != nil && statement.ModportNode.Identifier.Name() == name {
+			return statement.ModportNode
+		}
+	}
+	return nil
+}
+
 // ==============================
 // Directive Section
 // ==============================
+// returns the position of the next newline, or the end of the tokens
+// if there isn't one
+func (p *Parser) skipLine(tokens []Token, pos int) int {
+	for pos < len(tokens) && tokens[pos].Type != NEWLINE {
+		pos++
+	}
+	return pos
+}
+
 func (p *Parser) parseDefine(tokens []Token, pos int) (result *DefineNode, newPos int, err error) {
+	start := pos
 	// DEFINE <identifier> ... NEWLINE
-	pos, err = p.CheckToken("define", []string{"define"}, pos, tokens)
+	pos, err = p.CheckToken("define", []TokenKind{DEFINE}, pos, tokens)
 	if err != nil {
 		return
 	}
 	pos++
 
 	// get the identifier
-	pos, err = p.CheckToken("define", []string{"identifier"}, pos, tokens)
+	pos, err = p.CheckToken("define", []TokenKind{IDENTIFIER}, pos, tokens)
 	if err != nil {
 		return
 	}
 	result = &DefineNode{Identifier: tokens[pos]}
 	pos++
 
-	// skip to newline
-	for tokens[pos].Type != "newline" {
-		pos++
+	newPos = p.skipLine(tokens, pos)
+	result.Span = p.span(tokens, start, newPos)
+	result.Comments = p.comments(tokens, start, newPos)
+	return
+}
+
+// <undef> -> UNDEF <identifier>
+func (p *Parser) parseUndef(tokens []Token, pos int) (result *UndefNode, newPos int, err error) {
+	start := pos
+	pos, err = p.CheckToken("undef", []TokenKind{UNDEF}, pos, tokens)
+	if err != nil {
+		return
 	}
+	pos++
+
+	pos, err = p.CheckToken("undef", []TokenKind{IDENTIFIER}, pos, tokens)
+	if err != nil {
+		return
+	}
+	result = &UndefNode{Identifier: tokens[pos]}
+	pos++
 	newPos = pos
+	result.Span = p.span(tokens, start, newPos)
 	return
 }
-func (p *Parser) skipTimescale(tokens []Token, pos int) (newPos int, err error) {
-	// TIMESCALE
-	pos, err = p.CheckToken("timescale", []string{"timescale"}, pos, tokens)
+
+// <default_nettype> -> DEFAULT_NETTYPE <net_type>
+func (p *Parser) parseDefaultNettype(tokens []Token, pos int) (result *DefaultNettypeNode, newPos int, err error) {
+	start := pos
+	pos, err = p.CheckToken("default nettype", []TokenKind{DEFAULT_NETTYPE}, pos, tokens)
 	if err != nil {
 		return
 	}
 	pos++
 
-	// skip to newline
-	for tokens[pos].Type != "newline" {
-		pos++
+	// wire is lexed as a type, the rest are plain identifiers
+	pos, err = p.CheckToken("default nettype", []TokenKind{TYPE, IDENTIFIER}, pos, tokens)
+	if err != nil {
+		return
 	}
-
+	switch tokens[pos].Value {
+	case "wire", "tri", "tri0", "tri1", "wand", "triand", "wor", "trior", "trireg", "uwire", "none":
+		result = &DefaultNettypeNode{Nettype: tokens[pos]}
+	default:
+		err = p.newErrorFrom("default nettype", []string{"net type"}, pos, tokens)
+		return
+	}
+	pos++
 	newPos = pos
+	result.Span = p.span(tokens, start, newPos)
+	return
+}
+
+func (p *Parser) skipTimescale(tokens []Token, pos int) (newPos int, err error) {
+	// TIMESCALE
+	pos, err = p.CheckToken("timescale", []TokenKind{TIMESCALE}, pos, tokens)
+	if err != nil {
+		return
+	}
+	pos++
+
+	newPos = p.skipLine(tokens, pos)
 	return
 }
 
 // <include> -> include <literal>
 func (p *Parser) skipInclude(tokens []Token, pos int) (newPos int, err error) {
-	pos, err = p.CheckToken("include", []string{"include"}, pos, tokens)
+	pos, err = p.CheckToken("include", []TokenKind{INCLUDE}, pos, tokens)
 	if err != nil {
 		return
 	}
 	pos++
 
-	pos, err = p.CheckToken("include", []string{"literal"}, pos, tokens)
+	pos, err = p.CheckToken("include", []TokenKind{LITERAL}, pos, tokens)
 	if err != nil {
 		return
 	}
@@ -1868,175 +3009,190 @@ func (p *Parser) skipInclude(tokens []Token, pos int) (newPos int, err error) {
 	newPos = pos
 	return
 }
-func (p *Parser) parseDirective(tokens []Token, pos int) (result *DefineNode, newPos int, err error) {
-	// directive is just a define, timescale, or include
-	result, newPos, err = p.parseDefine(tokens, pos)
-	if err == nil {
-		return // success with define
+
+// <other_directive> -> DIRECTIVE <non-newline> NEWLINE
+func (p *Parser) skipOtherDirective(tokens []Token, pos int) (newPos int, err error) {
+	pos, err = p.CheckToken("directive", []TokenKind{DIRECTIVE}, pos, tokens)
+	if err != nil {
+		return
 	}
+	pos++
+
+	newPos = p.skipLine(tokens, pos)
+	return
+}
 
-	newPos, err = p.skipTimescale(tokens, pos)
-	if err == nil {
-		return // success with timescale
+func (p *Parser) parseDirective(tokens []Token, pos int) (result *DirectiveNode, newPos int, err error) {
+	// directive is a define, undef, default_nettype, timescale, include, or any other directive
+	start := pos
+	pos, err = p.CheckToken("directive", []TokenKind{DEFINE, UNDEF, DEFAULT_NETTYPE, TIMESCALE, INCLUDE, DIRECTIVE}, pos, tokens)
+	if err != nil {
+		return
 	}
+	result = &DirectiveNode{Directive: tokens[pos]}
 
-	newPos, err = p.skipInclude(tokens, pos)
-	if err == nil {
-		return // success with include
+	result.DefineNode, newPos, err = p.parseDefine(tokens, pos)
+	if err != nil {
+		result.UndefNode, newPos, err = p.parseUndef(tokens, pos)
 	}
-	return // failure with all three
+	if err != nil {
+		result.DefaultNettypeNode, newPos, err = p.parseDefaultNettype(tokens, pos)
+	}
+	if err != nil {
+		newPos, err = p.skipTimescale(tokens, pos)
+	}
+	if err != nil {
+		newPos, err = p.skipInclude(tokens, pos)
+	}
+	if err != nil {
+		newPos, err = p.skipOtherDirective(tokens, pos)
+	}
+	if err != nil {
+		result = nil
+		return // failure with all of them
+	}
+
+	// the arguments are everything after the directive, without surrounding trivia
+	first, last, ok := p.bounds(tokens, pos+1, newPos)
+	if ok {
+		result.Arguments = append([]Token{}, tokens[first:last+1]...)
+	}
+	result.Span = p.span(tokens, start, newPos)
+	return
 }
 
 func (p *Parser) ParseFile(tokens []Token) (result FileNode, err error) {
-	pos := 0
+	result.Statements, err = p.parseStatements(tokens, 0)
+	if err != nil {
+		return
+	}
+	result.NettypeRegions = nettypeRegions(result.Statements)
+	result.Span = p.span(tokens, 0, len(tokens))
+	return
+}
 
+// parseStatements parses the top level statements from pos until the end of the tokens
+func (p *Parser) parseStatements(tokens []Token, pos int) (result []TopLevelStatement, err error) {
 	for !p.isEOF(tokens, pos) {
-		// it's either a directive or a module
+		// it's either a directive, a module, an interface, a package, or an import
 		// try directive
 		directive, newPos, e := p.parseDirective(tokens, pos)
 		if e != nil {
 			// try module
 			module, newPos, e := p.parseModule(tokens, pos)
+			if e == nil {
+				result = append(result, TopLevelStatement{
+					Module: &module, Span: module.Span})
+				pos = newPos
+				continue
+			}
+
+			// try interface
+			iface, newPos, e := p.parseInterface(tokens, pos)
+			if e == nil {
+				result = append(result, TopLevelStatement{
+					Interface: &iface, Span: iface.Span})
+				pos = newPos
+				continue
+			}
+
+			// try package
+			pkg, newPos, e := p.parsePackage(tokens, pos)
+			if e == nil {
+				result = append(result, TopLevelStatement{
+					Package: &pkg, Span: pkg.Span})
+				pos = newPos
+				continue
+			}
+
+			// try import
+			importNode, newPos, e := p.parseImport(tokens, pos)
 			if e != nil {
 				err = *p.FarthestError
 				return
 			}
-			result.Statements = append(result.Statements, TopLevelStatement{
-				Module: &module})
+			result = append(result, TopLevelStatement{
+				Import: &importNode, Span: importNode.Span})
 			pos = newPos
 		} else {
-			result.Statements = append(result.Statements, TopLevelStatement{
-				Directive: &DirectiveNode{DefineNode: directive},
+			result = append(result, TopLevelStatement{
+				Directive: directive,
+				Span:      directive.Span,
 			})
 			pos = newPos
 		}
 	}
-
 	return
 }
-func getInteriorStatementsFromAlwaysStatements(statements []AlwaysStatement) []InteriorNode {
-	var result []InteriorNode
+
+// nettypeRegions keeps track of which parts of the file have which default nettype
+func nettypeRegions(statements []TopLevelStatement) []NettypeRegion {
+	var result []NettypeRegion
 	for _, statement := range statements {
-		result = append(result, getInteriorStatementsFromAlwaysStatement(statement)...)
-	}
-	return result
-}
-func getInteriorStatementsFromAlwaysStatement(statement AlwaysStatement) []InteriorNode {
-	var result []InteriorNode
-	if statement.BeginBlock != nil {
-		result = append(result, getInteriorStatementsFromAlwaysStatements(statement.BeginBlock.Statements)...)
-	} else if statement.CaseNode != nil {
-		// add regular cases
-		for _, caseNode := range statement.CaseNode.Cases {
-			result = append(result, getInteriorStatementsFromAlwaysStatement(caseNode.Statement)...)
-		}
-		// add default case
-		if statement.CaseNode.Default != nil {
-			result = append(result, getInteriorStatementsFromAlwaysStatement(*statement.CaseNode.Default)...)
-		}
-	} else if statement.ForBlock != nil {
-		result = append(result, getInteriorStatementsFromAlwaysStatement(statement.ForBlock.Body)...)
-	} else if statement.IfBlock != nil {
-		result = append(result, getInteriorStatementsFromAlwaysStatement(statement.IfBlock.Body)...)
-		if statement.IfBlock.Else != nil {
-			result = append(result, getInteriorStatementsFromAlwaysStatement(*statement.IfBlock.Else)...)
-		}
-	} else if statement.InteriorNode != nil {
-		result = append(result, getInteriorStatementsFromInteriorNode(*statement.InteriorNode)...)
-	}
-	return result
-}
-func getInteriorStatementsFromInteriorNode(interiorNode InteriorNode) []InteriorNode {
-	var result []InteriorNode
-	if interiorNode.AlwaysNode != nil {
-		result = append(result, getInteriorStatementsFromAlwaysStatement(interiorNode.AlwaysNode.Statement)...)
-	} else if interiorNode.GenerateNode != nil {
-		result = append(result, getInteriorStatementsFromAlwaysStatements(interiorNode.GenerateNode.Statements)...)
-	} else if interiorNode.InitialNode != nil {
-		result = append(result, getInteriorStatementsFromAlwaysStatement(interiorNode.InitialNode.Statement)...)
-	} else if interiorNode.TaskNode != nil {
-		result = append(result, getInteriorStatementsFromAlwaysStatements(interiorNode.TaskNode.Statements)...)
-	} else {
-		// this belongs to the result
-		result = append(result, interiorNode)
+		directive := statement.Directive
+		if directive == nil {
+			continue
+		}
+		if directive.DefaultNettypeNode != nil {
+			result = append(result, NettypeRegion{
+				Start:   directive.Directive,
+				Nettype: directive.DefaultNettypeNode.Nettype.Value,
+			})
+		} else if directive.Directive.Value == "`resetall" {
+			result = append(result, NettypeRegion{
+				Start:   directive.Directive,
+				Nettype: "wire",
+			})
+		}
 	}
 	return result
 }
-func GetInteriorStatementsFromModule(module ModuleNode) []InteriorNode {
-	var result []InteriorNode
-	for _, statement := range module.Interior {
-		result = append(result, getInteriorStatementsFromInteriorNode(statement)...)
+
+// DefaultNettype returns the default nettype in effect at the given token,
+// which is wire unless a `default_nettype says otherwise
+func (f FileNode) DefaultNettype(at Token) string {
+	nettype := "wire"
+	for _, region := range f.NettypeRegions {
+		if region.Start.Line() > at.Line() || (region.Start.Line() == at.Line() && region.Start.StartCharacter() > at.StartCharacter()) {
+			break
+		}
+		nettype = region.Nettype
 	}
-	return result
+	return nettype
 }
 
-func GetInteriorStatements(fileNode FileNode) []InteriorNode {
+// returns the interior statements inside of the node, looking
+// through always, generate, initial, and task blocks
+func getInteriorStatements(node Node) []InteriorNode {
 	var result []InteriorNode
-	for _, statements := range fileNode.Statements {
-		if statements.Module != nil {
-			result = append(result, GetInteriorStatementsFromModule(*statements.Module)...)
+	Inspect(node, func(node Node, path []Node) bool {
+		interiorNode, ok := node.(InteriorNode)
+		if ok && interiorNode.AlwaysNode == nil && interiorNode.GenerateNode == nil && interiorNode.InitialNode == nil && interiorNode.TaskNode == nil {
+			result = append(result, interiorNode)
 		}
-	}
+		return true
+	})
 	return result
 }
-func getFunctionStatementsFromAlwaysStatements(statements []AlwaysStatement) []FunctionNode {
-	var result []FunctionNode
-	for _, statement := range statements {
-		result = append(result, getFunctionStatementsFromAlwaysStatement(statement)...)
-	}
-	return result
+func GetInteriorStatementsFromModule(module ModuleNode) []InteriorNode {
+	return getInteriorStatements(module)
 }
-func getFunctionStatementsFromAlwaysStatement(statement AlwaysStatement) []FunctionNode {
-	var result []FunctionNode
-	if statement.BeginBlock != nil {
-		result = append(result, getFunctionStatementsFromAlwaysStatements(statement.BeginBlock.Statements)...)
-	} else if statement.CaseNode != nil {
-		// add regular cases
-		for _, caseNode := range statement.CaseNode.Cases {
-			result = append(result, getFunctionStatementsFromAlwaysStatement(caseNode.Statement)...)
-		}
-		// add default case
-		if statement.CaseNode.Default != nil {
-			result = append(result, getFunctionStatementsFromAlwaysStatement(*statement.CaseNode.Default)...)
-		}
-	} else if statement.ForBlock != nil {
-		result = append(result, getFunctionStatementsFromAlwaysStatement(statement.ForBlock.Body)...)
-	} else if statement.FunctionNode != nil {
-		result = append(result, *statement.FunctionNode)
-	} else if statement.IfBlock != nil {
-		result = append(result, getFunctionStatementsFromAlwaysStatement(statement.IfBlock.Body)...)
-		if statement.IfBlock.Else != nil {
-			result = append(result, getFunctionStatementsFromAlwaysStatement(*statement.IfBlock.Else)...)
-		}
-	} else if statement.InteriorNode != nil {
-		result = append(result, getFunctionStatementsFromInteriorNode(*statement.InteriorNode)...)
-	}
-	return result
+func GetInteriorStatementsFromInterface(iface InterfaceNode) []InteriorNode {
+	return getInteriorStatements(iface)
 }
-func getFunctionStatementsFromInteriorNode(interiorNode InteriorNode) []FunctionNode {
-	var result []FunctionNode
-	if interiorNode.AlwaysNode != nil {
-		result = append(result, getFunctionStatementsFromAlwaysStatement(interiorNode.AlwaysNode.Statement)...)
-	} else if interiorNode.GenerateNode != nil {
-		result = append(result, getFunctionStatementsFromAlwaysStatements(interiorNode.GenerateNode.Statements)...)
-	} else if interiorNode.InitialNode != nil {
-		result = append(result, getFunctionStatementsFromAlwaysStatement(interiorNode.InitialNode.Statement)...)
-	} else if interiorNode.TaskNode != nil {
-		result = append(result, getFunctionStatementsFromAlwaysStatements(interiorNode.TaskNode.Statements)...)
-	}
-	return result
+
+func GetInteriorStatements(fileNode FileNode) []InteriorNode {
+	return getInteriorStatements(fileNode)
 }
 
 func GetFunctionNodes(fileNode FileNode) []FunctionNode {
 	var result []FunctionNode
-	for _, statements := range fileNode.Statements {
-		if statements.Module != nil {
-			interior := statements.Module.Interior
-			for _, statement := range interior {
-				result = append(result, getFunctionStatementsFromInteriorNode(statement)...)
-			}
+	Inspect(fileNode, func(node Node, path []Node) bool {
+		if functionNode, ok := node.(FunctionNode); ok {
+			result = append(result, functionNode)
 		}
-	}
+		return true
+	})
 	return result
 }
 
@@ -2047,7 +3203,7 @@ Grammar:
 // Top Level Grammar
 // ==============================
 <file> -> <statement> { <statement> }
-<statement> -> <module> | <directive>
+<statement> -> <module> | <directive> | <interface> | <package> | <import>
 
 // useful helper grammars
 <identifier> -> IDENTIFIER
@@ -2056,31 +3212,58 @@ Grammar:
 // ==============================
 // Directive Grammar
 // ==============================
-<directive> -> <include> | <timescale> | <define>
+<directive> -> <include> | <timescale> | <define> | <undef> | <default_nettype> | <other_directive>
 <include> -> INCLUDE LITERAL
 <timescale> -> TIMESCALE <non-newline> NEWLINE
 <define> -> DEFINE <identifier> <non-newline> NEWLINE
+<undef> -> UNDEF <identifier>
+<default_nettype> -> DEFAULT_NETTYPE <net_type>
+<net_type> -> WIRE | TRI | TRI0 | TRI1 | WAND | TRIAND | WOR | TRIOR | TRIREG | UWIRE | NONE
+<other_directive> -> DIRECTIVE <non-newline> NEWLINE
+
+// ==============================
+// Interface Grammar (SystemVerilog only)
+// ==============================
+<interface> -> INTERFACE <identifier> [<portlist>] SEMICOLON <interface_interior> ENDINTERFACE [COLON <identifier>]
+<interface_interior> -> { <modport> | <interior_statement> }
+<modport> -> MODPORT <identifier> LPAREN <modport_port> { COMMA <modport_port> } RPAREN SEMICOLON
+<modport_port> -> [DIRECTION] <identifier>
+
+// ==============================
+// Package Grammar (SystemVerilog only)
+// ==============================
+<package> -> PACKAGE <identifier> SEMICOLON <interior> ENDPACKAGE [COLON <identifier>]
+<import> -> IMPORT <import_item> { COMMA <import_item> } SEMICOLON
+<import_item> -> <scope> (<identifier> | STAR)
+<scope> -> <identifier> SCOPE
+<typedef> -> TYPEDEF (<enum> | <struct> | <type>) <identifier> SEMICOLON
+<enum> -> ENUM [<type>] LCURL <enum_member> { COMMA <enum_member> } RCURL
+<enum_member> -> <identifier> [EQUAL <expr>]
+<struct> -> STRUCT [PACKED] LCURL { <declaration> } RCURL
 
 // ==============================
 // Module Grammar
 // ==============================
-<module> -> MODULE <identifier> [<portlist>] SEMICOLON <interior> ENDMODULE [SEMICOLON]
+<module> -> MODULE <identifier> { <import> } [<portlist>] SEMICOLON <interior> ENDMODULE [SEMICOLON]
 <portlist> -> LPAREN [<ports>] RPAREN
-<ports> -> <identifier> { COMMA <identifier> }
+<ports> -> <port> { COMMA <port> }
+<port> -> <type> <identifier> | <interface_port> <identifier> | <identifier>
+<interface_port> -> <identifier> [DOT <identifier>] (SystemVerilog only)
 
 <interior> -> { <interior_statement> }
-<interior_statement>  -> <declaration> | <module_application> | <assignment> | <generate> | <always> | <defparam> | <initial> | <directive> | <task>
+<interior_statement>  -> <declaration> | <module_application> | <assignment> | <generate> | <always> | <defparam> | <initial> | <directive> | <task> | <import> | <typedef>
 <task> -> TASK <identifier> SEMICOLON <always_statement> ENDTASK [SEMICOLON]
 
 <assignable> -> [LCURL] <single_var> {COMMA <single_var>} [RCURL]
-<assignable_var> -> <identifier> {<selector>}
+<assignable_var> -> <identifier> { DOT <identifier> } {<selector>}
 <assignment_without_semicolon> -> [ASSIGN] <assignable> (EQUAL | <=) <expr>
 <assignment> -> <assignment_without_semicolon> SEMICOLON
 <single_var> -> <identifier> {<range>}
 
 <declaration> -> <type> <single_var> EQUAL <expr> { COMMA <single_var> EQUAL <expr> } SEMICOLON
 | <type> <single_var> { COMMA <single_var> } SEMICOLON
-<type> -> (TYPE | DIRECTION [TYPE]) {<range>}
+<type> -> (TYPE | DIRECTION [TYPE | <user_type>] | <user_type>) {<range>}
+<user_type> -> [<scope>] <identifier> (SystemVerilog only, must be followed by an identifier)
 <range> -> LBRACKET <integer> COLON <integer> RBRACKET
 <integer> -> LITERAL | DEFINE
 
@@ -2092,19 +3275,21 @@ Grammar:
 			| LPAREN <expr> RPAREN
 <maybed_signed> -> <sized_value> | SIGNED LPAREN <sized_value> RPAREN
 <sized_value> -> [ LITERAL | <identifier> ] LCURL <sized_value> { COMMA <sized_value> } RCURL | <value>
-<value> -> [TILDE| - ] (LITERAL|(<identifier> { DOT <identifier> })|FUNCLITERAL) { <selector> }
+<value> -> [TILDE| - ] [<scope>] (LITERAL|(<identifier> { DOT <identifier> })|FUNCLITERAL) { <selector> }
 
 <defparam> -> DEFPARAM <identifier> { DOT <identifier> } EQUAL <expr> SEMICOLON
 
 <generate> -> GENERATE { <alwaysable_statement> } ENDGENERATE
 <begin_block> -> BEGIN [ COLON <identifier> ] { <alwaysable_statement> } END
-<for> -> FOR LPAREN [<assignment_without_semicolon>] SEMICOLON [<expr>] SEMICOLON [<assignment_without_semicolon>] RPAREN <alwaysable_statement>
+<for> -> FOR LPAREN [<for_initializer>] SEMICOLON [<expr>] SEMICOLON [<assignment_without_semicolon> | <increment>] RPAREN <alwaysable_statement>
+<for_initializer> -> <assignment_without_semicolon> | <type> <assignment_without_semicolon> (SystemVerilog only)
+<increment> -> <assignable_var> INCREMENT | INCREMENT <assignable_var> (SystemVerilog only)
 <if> -> IF LPAREN <expr> RPAREN <alwaysable_statement> [ELSE <alwaysable_statement>]
 <builtin_function_call> -> DOLLAR <identifier> [LPAREN <expr> { COMMA <expr> } RPAREN] SEMICOLON
 
 <always> -> ALWAYS [ AT LPAREN <event> RPAREN ] <alwaysable_statement>
-<event> -> <time> { OR <time> }
-<time> -> [ TIME ] <identifier>
+<event> -> <time> { (OR | COMMA) <time> }
+<time> -> [ EDGE ] <identifier>
 <alwaysable_statement> -> <begin_block> | <interior_statement> | <for> | <if> | <builtin_function_call> | <delay_statement> | <case_block>
 <delay_statement> -> POUND [ LITERAL | <identifier> ]
 <case_block> -> CASE LPAREN <expr> RPAREN {<case>} [ DEFAULT COLON <alwaysable_statement> ] ENDCASE
@@ -0,0 +1,110 @@
+package lang
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// generateNested returns a module with an always block whose statements are nested depth deep
+func generateNested(depth int) string {
+	code := strings.Builder{}
+	code.WriteString("module nested (input clk, input [31:0] a, output reg [31:0] q);\n  always @(posedge clk) begin\n")
+	for i := 0; i < depth; i++ {
+		fmt.Fprintf(&code, "    if (a[%d] && (a + %d) > (q - ((a ^ q) >> %d))) begin : level%d\n", i%32, i, i%8, i)
+	}
+	code.WriteString("      q <= a ? {q[30:0], a[0]} : q;\n")
+	for i := 0; i < depth; i++ {
+		code.WriteString("    end else q <= 0;\n")
+	}
+	code.WriteString("  end\nendmodule\n")
+	return code.String()
+}
+
+// benchmarkParse parses code, reporting its throughput. The parser is linear
+// when the throughput stays the same as the code gets bigger
+func benchmarkParse(b *testing.B, code string, succeeds bool) {
+	tokens, err := NewVLexer(zap.NewNop()).Lex(code)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.SetBytes(int64(len(code)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := NewParser().ParseFile(tokens); (err == nil) != succeeds {
+			b.Fatalf("parse gave error %v", err)
+		}
+	}
+}
+
+func BenchmarkParse(b *testing.B) {
+	for _, lines := range []int{1000, 2000, 4000, 8000} {
+		b.Run(fmt.Sprintf("lines=%d", lines), func(b *testing.B) {
+			benchmarkParse(b, generateNetlist(lines), true)
+		})
+	}
+}
+
+func BenchmarkParseNested(b *testing.B) {
+	for _, depth := range []int{8, 16, 32, 64} {
+		b.Run(fmt.Sprintf("depth=%d", depth), func(b *testing.B) {
+			benchmarkParse(b, generateNested(depth), true)
+		})
+	}
+}
+
+// an error at the very end makes every statement before it fail,
+// which is the most backtracking there can be
+func BenchmarkParseError(b *testing.B) {
+	for _, depth := range []int{8, 16, 32, 64} {
+		b.Run(fmt.Sprintf("depth=%d", depth), func(b *testing.B) {
+			code := generateNested(depth)
+			benchmarkParse(b, code[:len(code)-len("  end\nendmodule\n")]+"  q <= ;\n", false)
+		})
+	}
+}
+
+func FuzzParseFile(f *testing.F) {
+	for _, pattern := range []string{"*.v", "*.sv"} {
+		inputs, err := filepath.Glob(filepath.Join("testdata", "*", pattern))
+		if err != nil {
+			f.Fatal(err)
+		}
+		for _, input := range inputs {
+			code, err := os.ReadFile(input)
+			if err != nil {
+				f.Fatal(err)
+			}
+			f.Add(string(code))
+		}
+	}
+	f.Add("`timescale 1ns/1ps")
+	f.Add("`define WIDTH 8")
+	f.Add("module m; always @(posedge clk) if (a) b <= c; else")
+	f.Fuzz(func(t *testing.T, code string) {
+		for _, language := range []Language{Verilog, SystemVerilog} {
+			tokens, err := newVLexer(zap.NewNop(), language).Lex(code)
+			if err != nil {
+				t.Fatalf("%v: lexer failed: %v", language, err)
+			}
+			ast, err := newParser(language).ParseFile(tokens)
+			if err != nil {
+				continue
+			}
+			// whatever parses can be printed and parsed again
+			printed := Print(ast)
+			tokens, err = newVLexer(zap.NewNop(), language).Lex(printed)
+			if err != nil {
+				t.Fatalf("%v: lexer failed on printed code: %v", language, err)
+			}
+			if _, err := newParser(language).ParseFile(tokens); err != nil {
+				t.Fatalf("%v: %v in printed code:\n%s", language, err, printed)
+			}
+		}
+	})
+}
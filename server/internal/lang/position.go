@@ -0,0 +1,45 @@
+package lang
+
+import "unicode/utf8"
+
+// LSP positions count characters in UTF-16 code units, while Go strings
+// are indexed by byte, so these convert between the two within a line
+
+// UTF16Len returns the number of UTF-16 code units the string takes up
+func UTF16Len(s string) int {
+	length := 0
+	for i := 0; i < len(s); {
+		if s[i] < utf8.RuneSelf {
+			length++
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		length += utf16RuneLen(r)
+		i += size
+	}
+	return length
+}
+
+// ByteOffset returns the byte offset into the line of a character counted
+// in UTF-16 code units, clamped to the end of the line
+func ByteOffset(line string, character int) int {
+	units := 0
+	for i := 0; i < len(line); {
+		if units >= character {
+			return i
+		}
+		r, size := utf8.DecodeRuneInString(line[i:])
+		units += utf16RuneLen(r)
+		i += size
+	}
+	return len(line)
+}
+
+// characters outside the basic multilingual plane, ie emoji, are surrogate pairs
+func utf16RuneLen(r rune) int {
+	if r >= 0x10000 {
+		return 2
+	}
+	return 1
+}
@@ -0,0 +1,896 @@
+package lang
+
+import "strings"
+
+// Print returns the source code of a node, ie a FileNode or a ModuleApplicationNode.
+// Parsing the printed code of a file gives back the same ast, apart from positions.
+// The layout is normalized, and comments are kept wherever the ast has them
+func Print(node Node) string {
+	p := &printer{}
+	p.node(node)
+	return p.builder.String()
+}
+
+type printer struct {
+	builder     strings.Builder
+	depth       int  // how many levels the current line is indented
+	atLineStart bool // whether the indentation still needs to be written
+}
+
+const printIndent = "    "
+
+func (p *printer) write(s string) {
+	if p.atLineStart && s != "" {
+		p.builder.WriteString(strings.Repeat(printIndent, p.depth))
+		p.atLineStart = false
+	}
+	p.builder.WriteString(s)
+}
+
+func (p *printer) newline() {
+	p.builder.WriteString("\n")
+	p.atLineStart = true
+}
+
+// writes the comments above a node, each on their own line
+func (p *printer) leading(comments Trivia) {
+	for _, group := range groupComments(comments.Leading) {
+		p.write(joinTokens(group))
+		p.newline()
+	}
+}
+
+// writes the comment after a node, on the same line
+func (p *printer) trailing(comments Trivia) {
+	if len(comments.Trailing) > 0 {
+		p.write(" " + joinTokens(comments.Trailing))
+	}
+}
+
+// spell returns the text of a token, followed by a space if it's an escaped
+// identifier since those only end at whitespace
+func spell(token Token) string {
+	if strings.HasPrefix(token.Value, `\`) {
+		return token.Value + " "
+	}
+	return token.Value
+}
+
+func joinTokens(tokens []Token) string {
+	result := strings.Builder{}
+	for _, token := range tokens {
+		result.WriteString(token.Value)
+	}
+	return result.String()
+}
+
+func (p *printer) node(node Node) {
+	switch node := node.(type) {
+	case FileNode:
+		p.file(node)
+	case TopLevelStatement:
+		p.topLevelStatement(node)
+	case ModuleNode:
+		p.module(node)
+	case InterfaceNode:
+		p.iface(node)
+	case PackageNode:
+		p.pkg(node)
+	case PortListNode:
+		p.portList(node)
+	case PortNode:
+		p.port(node, nil)
+	case InterfacePortNode:
+		p.interfacePort(node)
+	case ImportNode:
+		p.importNode(node)
+	case ImportItemNode:
+		p.importItem(node)
+	case InteriorNode:
+		p.interior(node)
+	case TypedefNode:
+		p.typedef(node)
+	case EnumNode:
+		p.enum(node)
+	case EnumMemberNode:
+		p.enumMember(node)
+	case StructNode:
+		p.structNode(node)
+	case ModportNode:
+		p.modport(node)
+	case ModportPortNode:
+		p.write(spell(node.Direction) + " " + spell(node.Identifier))
+	case DirectiveNode:
+		p.directive(node)
+	case DefineNode:
+		p.write("`define " + spell(node.Identifier))
+	case UndefNode:
+		p.write("`undef " + spell(node.Identifier))
+	case DefaultNettypeNode:
+		p.write("`default_nettype " + spell(node.Nettype))
+	case AssignmentNode:
+		p.assignment(node)
+	case AssignmentVariableNode:
+		p.assignmentVariable(node)
+	case SelectorNode:
+		p.selector(node)
+	case IndexNode:
+		p.write("[")
+		p.expression(node.Index)
+		p.write("]")
+	case RangeNode:
+		p.rangeNode(node)
+	case ValueNode:
+		p.value(node)
+	case SizedValueNode:
+		p.sizedValue(node)
+	case ExprNode:
+		p.expression(node)
+	case DeclarationNode:
+		p.declaration(node)
+	case VariableNode:
+		p.variable(node)
+	case TypeNode:
+		p.typeNode(node)
+	case ModuleApplicationNode:
+		p.moduleApplication(node)
+	case ArgumentNode:
+		p.argument(node)
+	case GenerateNode:
+		p.generate(node)
+	case AlwaysNode:
+		p.always(node)
+	case AlwaysStatement:
+		p.alwaysStatement(node)
+	case BeginBlockNode:
+		p.beginBlock(node)
+	case ForBlockNode:
+		p.forBlock(node)
+	case IfBlockNode:
+		p.ifBlock(node)
+	case CaseBlock:
+		p.caseBlock(node)
+	case CaseNode:
+		p.caseNode(node)
+	case TimeNode:
+		p.time(node)
+	case DelayNode:
+		p.write("#" + spell(node.Amount))
+	case FunctionNode:
+		p.function(node)
+	case DefParamNode:
+		p.defParam(node)
+	case InitialNode:
+		p.initial(node)
+	case TaskNode:
+		p.task(node)
+	}
+}
+
+// ==============================
+// Top Level
+// ==============================
+
+func (p *printer) file(node FileNode) {
+	for i, statement := range node.Statements {
+		// design elements are separated by blank lines, directives and imports aren't
+		isElement := statement.Module != nil || statement.Interface != nil || statement.Package != nil
+		if i > 0 {
+			previous := node.Statements[i-1]
+			if isElement || previous.Module != nil || previous.Interface != nil || previous.Package != nil {
+				p.newline()
+			}
+		}
+		p.topLevelStatement(statement)
+	}
+}
+
+func (p *printer) topLevelStatement(node TopLevelStatement) {
+	if node.Directive != nil {
+		p.directive(*node.Directive)
+	} else if node.Module != nil {
+		p.module(*node.Module)
+	} else if node.Interface != nil {
+		p.iface(*node.Interface)
+	} else if node.Package != nil {
+		p.pkg(*node.Package)
+	} else if node.Import != nil {
+		p.importNode(*node.Import)
+	}
+}
+
+func (p *printer) module(node ModuleNode) {
+	p.leading(node.Comments)
+	p.write("module " + spell(node.Identifier))
+	for _, importNode := range node.Imports {
+		p.write(" ")
+		p.importItems(importNode)
+	}
+	if len(node.Imports) > 0 && hasPortList(node.PortList) {
+		p.write(" ")
+	}
+	p.portList(node.PortList)
+	p.write(";")
+	p.newline()
+	p.interiors(node.Interior)
+	p.write("endmodule")
+	p.trailing(node.Comments)
+	p.newline()
+}
+
+func (p *printer) iface(node InterfaceNode) {
+	p.leading(node.Comments)
+	p.write("interface " + spell(node.Identifier))
+	p.portList(node.PortList)
+	p.write(";")
+	p.newline()
+	p.interiors(node.Interior)
+	p.write("endinterface")
+	p.trailing(node.Comments)
+	p.newline()
+}
+
+func (p *printer) pkg(node PackageNode) {
+	p.leading(node.Comments)
+	p.write("package " + spell(node.Identifier) + ";")
+	p.newline()
+	p.interiors(node.Interior)
+	p.write("endpackage")
+	p.trailing(node.Comments)
+	p.newline()
+}
+
+// ANSI-style ports are printed one per line, other ports all on one line
+// whether there's a port list to print, which could be an empty one, ie ()
+func hasPortList(node PortListNode) bool {
+	return len(node.Ports) > 0 || node.Span != (Span{})
+}
+
+func (p *printer) portList(node PortListNode) {
+	if !hasPortList(node) {
+		return
+	}
+	if len(node.Declarations) == 0 {
+		names := []string{}
+		for _, port := range node.Ports {
+			names = append(names, spell(port))
+		}
+		p.write("(" + strings.Join(names, ", ") + ")")
+		return
+	}
+
+	p.write("(")
+	p.newline()
+	p.depth++
+	for i, port := range node.Declarations {
+		var previous *PortNode
+		if i > 0 {
+			previous = &node.Declarations[i-1]
+		}
+		p.leading(port.Comments)
+		p.port(port, previous)
+		if i < len(node.Declarations)-1 {
+			p.write(",")
+		}
+		p.trailing(port.Comments)
+		p.newline()
+	}
+	p.depth--
+	p.write(")")
+}
+
+// ports that share the type of the previous port are printed without it
+func (p *printer) port(node PortNode, previous *PortNode) {
+	shared := previous != nil && node.Type == previous.Type && node.Interface == previous.Interface
+	if node.Type != nil && !shared {
+		p.typeNode(*node.Type)
+		p.write(" ")
+	} else if node.Interface != nil && !shared {
+		p.interfacePort(*node.Interface)
+		p.write(" ")
+	}
+	p.write(spell(node.Identifier))
+}
+
+func (p *printer) interfacePort(node InterfacePortNode) {
+	p.write(spell(node.Interface))
+	if node.Modport != nil {
+		p.write("." + spell(*node.Modport))
+	}
+}
+
+func (p *printer) importNode(node ImportNode) {
+	p.importItems(node)
+	p.newline()
+}
+
+// writes an import without ending the line
+func (p *printer) importItems(node ImportNode) {
+	p.write("import ")
+	for i, item := range node.Items {
+		if i > 0 {
+			p.write(", ")
+		}
+		p.importItem(item)
+	}
+	p.write(";")
+}
+
+func (p *printer) importItem(node ImportItemNode) {
+	p.write(spell(node.Package) + "::" + spell(node.Item))
+}
+
+func (p *printer) directive(node DirectiveNode) {
+	if node.DefineNode != nil {
+		p.leading(node.DefineNode.Comments)
+	}
+	p.write(spell(node.Directive))
+	if len(node.Arguments) > 0 {
+		p.write(" " + joinTokens(node.Arguments))
+	} else if node.DefineNode != nil {
+		p.write(" " + spell(node.DefineNode.Identifier))
+	} else if node.UndefNode != nil {
+		p.write(" " + spell(node.UndefNode.Identifier))
+	} else if node.DefaultNettypeNode != nil {
+		p.write(" " + spell(node.DefaultNettypeNode.Nettype))
+	}
+	if node.DefineNode != nil {
+		p.trailing(node.DefineNode.Comments)
+	}
+	p.newline()
+}
+
+// ==============================
+// Module Interior
+// ==============================
+
+func (p *printer) interiors(nodes []InteriorNode) {
+	p.depth++
+	for _, node := range nodes {
+		p.interior(node)
+	}
+	p.depth--
+}
+
+func (p *printer) interior(node InteriorNode) {
+	if node.DeclarationNode != nil {
+		p.leading(node.DeclarationNode.Comments)
+		p.declaration(*node.DeclarationNode)
+		p.trailing(node.DeclarationNode.Comments)
+		p.newline()
+	} else if node.AssignmentNode != nil {
+		p.assignment(*node.AssignmentNode)
+		p.write(";")
+		p.newline()
+	} else if node.ModuleApplicationNode != nil {
+		p.leading(node.ModuleApplicationNode.Comments)
+		p.moduleApplication(*node.ModuleApplicationNode)
+		p.trailing(node.ModuleApplicationNode.Comments)
+		p.newline()
+	} else if node.GenerateNode != nil {
+		p.generate(*node.GenerateNode)
+	} else if node.AlwaysNode != nil {
+		p.always(*node.AlwaysNode)
+	} else if node.DefParamNode != nil {
+		p.defParam(*node.DefParamNode)
+	} else if node.InitialNode != nil {
+		p.initial(*node.InitialNode)
+	} else if node.DirectiveNode != nil {
+		p.directive(*node.DirectiveNode)
+	} else if node.TaskNode != nil {
+		p.task(*node.TaskNode)
+	} else if node.ModportNode != nil {
+		p.modport(*node.ModportNode)
+	} else if node.ImportNode != nil {
+		p.importNode(*node.ImportNode)
+	} else if node.TypedefNode != nil {
+		p.typedef(*node.TypedefNode)
+	}
+}
+
+// writes a declaration and its semicolon without ending the line
+func (p *printer) declaration(node DeclarationNode) {
+	p.typeNode(node.Type)
+	p.write(" ")
+	for i, variable := range node.Variables {
+		if i > 0 {
+			p.write(", ")
+		}
+		p.variable(variable)
+		if i < len(node.Values) {
+			p.write(" = ")
+			p.expression(node.Values[i])
+		}
+	}
+	p.write(";")
+}
+
+func (p *printer) variable(node VariableNode) {
+	p.write(spell(node.Identifier))
+	for _, r := range node.Ranges {
+		p.rangeNode(r)
+	}
+}
+
+func (p *printer) typeNode(node TypeNode) {
+	if node.Direction != nil && *node.Direction != node.Type {
+		p.write(spell(*node.Direction) + " ")
+	}
+	if node.Scope != nil {
+		p.write(spell(*node.Scope) + "::")
+	}
+	p.write(spell(node.Type))
+	for _, r := range node.Ranges {
+		p.write(" ")
+		p.rangeNode(r)
+	}
+}
+
+func (p *printer) rangeNode(node RangeNode) {
+	p.write("[")
+	p.expression(node.From)
+	p.write(":")
+	p.expression(node.To)
+	p.write("]")
+}
+
+// writes a module application and its semicolon without ending the line
+func (p *printer) moduleApplication(node ModuleApplicationNode) {
+	p.write(spell(node.ModuleName))
+	if node.GateName != nil {
+		p.write(" " + spell(*node.GateName))
+	}
+	if node.Range != nil {
+		p.rangeNode(*node.Range)
+	}
+	p.write("(")
+	for i, argument := range node.Arguments {
+		if i > 0 {
+			p.write(", ")
+		}
+		p.argument(argument)
+	}
+	p.write(");")
+}
+
+func (p *printer) argument(node ArgumentNode) {
+	if node.Wildcard != nil {
+		p.write(".*")
+		return
+	}
+	if node.Label != nil && node.Implicit {
+		p.write("." + spell(*node.Label))
+		return
+	}
+	if node.Label != nil {
+		p.write("." + spell(*node.Label) + "(")
+		p.expression(node.Value)
+		p.write(")")
+		return
+	}
+	p.expression(node.Value)
+}
+
+func (p *printer) defParam(node DefParamNode) {
+	names := []string{}
+	for _, identifier := range node.Identifiers {
+		names = append(names, spell(identifier))
+	}
+	p.write("defparam " + strings.Join(names, ".") + " = ")
+	p.expression(node.Value)
+	p.write(";")
+	p.newline()
+}
+
+func (p *printer) modport(node ModportNode) {
+	p.leading(node.Comments)
+	p.write("modport " + spell(node.Identifier) + "(")
+	for i, port := range node.Ports {
+		if i > 0 {
+			p.write(", ")
+		}
+		p.write(spell(port.Direction) + " " + spell(port.Identifier))
+	}
+	p.write(");")
+	p.trailing(node.Comments)
+	p.newline()
+}
+
+func (p *printer) typedef(node TypedefNode) {
+	p.leading(node.Comments)
+	p.write("typedef ")
+	if node.Enum != nil {
+		p.enum(*node.Enum)
+	} else if node.Struct != nil {
+		p.structNode(*node.Struct)
+	} else if node.Type != nil {
+		p.typeNode(*node.Type)
+	}
+	p.write(" " + spell(node.Identifier) + ";")
+	p.trailing(node.Comments)
+	p.newline()
+}
+
+// enum members are printed one per line so that each keeps its comments
+func (p *printer) enum(node EnumNode) {
+	p.write("enum ")
+	if node.Type != nil {
+		p.typeNode(*node.Type)
+		p.write(" ")
+	}
+	p.write("{")
+	p.newline()
+	p.depth++
+	for i, member := range node.Members {
+		p.leading(member.Comments)
+		p.enumMember(member)
+		if i < len(node.Members)-1 {
+			p.write(",")
+		}
+		p.trailing(member.Comments)
+		p.newline()
+	}
+	p.depth--
+	p.write("}")
+}
+
+func (p *printer) enumMember(node EnumMemberNode) {
+	p.write(spell(node.Identifier))
+	if node.Value != nil {
+		p.write(" = ")
+		p.expression(*node.Value)
+	}
+}
+
+func (p *printer) structNode(node StructNode) {
+	p.write("struct ")
+	if node.Packed {
+		p.write("packed ")
+	}
+	p.write("{")
+	p.newline()
+	p.depth++
+	for _, member := range node.Members {
+		p.leading(member.Comments)
+		p.declaration(member)
+		p.trailing(member.Comments)
+		p.newline()
+	}
+	p.depth--
+	p.write("}")
+}
+
+// ==============================
+// Behavioral Statements
+// ==============================
+
+func (p *printer) generate(node GenerateNode) {
+	p.write("generate")
+	p.newline()
+	p.alwaysStatements(node.Statements)
+	p.write("endgenerate")
+	p.newline()
+}
+
+func (p *printer) always(node AlwaysNode) {
+	keyword := node.Always.Value
+	if keyword == "" {
+		keyword = "always"
+	}
+	p.write(keyword)
+	if len(node.Times) > 0 {
+		p.write(" @(")
+		for i, time := range node.Times {
+			if i > 0 {
+				p.write(" or ")
+			}
+			p.time(time)
+		}
+		p.write(")")
+	}
+	p.body(node.Statement)
+}
+
+func (p *printer) time(node TimeNode) {
+	if node.Time != nil {
+		p.write(spell(*node.Time) + " ")
+	}
+	p.write(spell(node.Identifier))
+}
+
+func (p *printer) initial(node InitialNode) {
+	p.write("initial")
+	p.body(node.Statement)
+}
+
+func (p *printer) task(node TaskNode) {
+	p.leading(node.Comments)
+	p.write("task " + spell(node.Identifier) + ";")
+	p.trailing(node.Comments)
+	p.newline()
+	p.alwaysStatements(node.Statements)
+	p.write("endtask")
+	p.newline()
+}
+
+func (p *printer) alwaysStatements(statements []AlwaysStatement) {
+	p.depth++
+	for _, statement := range statements {
+		p.alwaysStatement(statement)
+	}
+	p.depth--
+}
+
+// writes the body of a block after its header, which is on the same line
+// if it's a begin block or a delay and indented on the next otherwise
+func (p *printer) body(statement AlwaysStatement) {
+	if statement.BeginBlock != nil || statement.DelayNode != nil {
+		p.inlineBody(statement)
+		return
+	}
+	p.newline()
+	p.depth++
+	p.alwaysStatement(statement)
+	p.depth--
+}
+
+// writes the body of a block on the same line as its header, ie a case item
+func (p *printer) inlineBody(statement AlwaysStatement) {
+	p.write(" ")
+	p.alwaysStatement(statement)
+}
+
+func (p *printer) alwaysStatement(node AlwaysStatement) {
+	if node.DelayNode != nil {
+		// the delay applies to the statement after it, which continues the line
+		p.write("#" + spell(node.DelayNode.Amount) + " ")
+	} else if node.BeginBlock != nil {
+		p.beginBlock(*node.BeginBlock)
+	} else if node.ForBlock != nil {
+		p.forBlock(*node.ForBlock)
+	} else if node.IfBlock != nil {
+		p.ifBlock(*node.IfBlock)
+	} else if node.InteriorNode != nil {
+		p.interior(*node.InteriorNode)
+	} else if node.FunctionNode != nil {
+		p.function(*node.FunctionNode)
+	} else if node.CaseNode != nil {
+		p.caseBlock(*node.CaseNode)
+	}
+}
+
+func (p *printer) beginBlock(node BeginBlockNode) {
+	p.write("begin")
+	if node.Label != nil {
+		p.write(" : " + spell(*node.Label))
+	}
+	p.newline()
+	p.alwaysStatements(node.Statements)
+	p.write("end")
+	p.newline()
+}
+
+func (p *printer) ifBlock(node IfBlockNode) {
+	p.write("if (")
+	p.expression(node.Expr)
+	p.write(")")
+	p.body(node.Body)
+	if node.Else != nil {
+		p.write("else")
+		if node.Else.IfBlock != nil {
+			// else if chains stay at the same level
+			p.write(" ")
+			p.ifBlock(*node.Else.IfBlock)
+		} else {
+			p.body(*node.Else)
+		}
+	}
+}
+
+func (p *printer) forBlock(node ForBlockNode) {
+	p.write("for (")
+	if node.InitializerType != nil {
+		p.typeNode(*node.InitializerType)
+		p.write(" ")
+	}
+	if node.Initializer != nil {
+		p.assignment(*node.Initializer)
+	}
+	p.write("; ")
+	if node.Condition != nil {
+		p.expression(*node.Condition)
+	}
+	p.write("; ")
+	if node.Incrementor != nil {
+		p.assignment(*node.Incrementor)
+	}
+	p.write(")")
+	p.body(node.Body)
+}
+
+func (p *printer) caseBlock(node CaseBlock) {
+	keyword := node.Case.Value
+	if keyword == "" {
+		keyword = "case"
+	}
+	p.write(keyword + " (")
+	p.expression(node.Expr)
+	p.write(")")
+	p.newline()
+	p.depth++
+	for _, c := range node.Cases {
+		p.caseNode(c)
+	}
+	if node.Default != nil {
+		p.write("default:")
+		p.inlineBody(*node.Default)
+	}
+	p.depth--
+	p.write("endcase")
+	p.newline()
+}
+
+func (p *printer) caseNode(node CaseNode) {
+	for i, condition := range node.Conditions {
+		if i > 0 {
+			p.write(", ")
+		}
+		p.expression(condition)
+	}
+	p.write(":")
+	p.inlineBody(node.Statement)
+}
+
+func (p *printer) function(node FunctionNode) {
+	p.write("$" + spell(node.Function))
+	if len(node.Expressions) > 0 {
+		p.write("(")
+		for i, expression := range node.Expressions {
+			if i > 0 {
+				p.write(", ")
+			}
+			p.expression(expression)
+		}
+		p.write(")")
+	}
+	p.write(";")
+	p.newline()
+}
+
+// writes an assignment without its semicolon, since ones in for loops don't have one
+func (p *printer) assignment(node AssignmentNode) {
+	if node.IsAssign {
+		p.write("assign ")
+	}
+	if len(node.Variables) == 1 {
+		p.assignmentVariable(node.Variables[0])
+	} else {
+		p.write("{")
+		for i, variable := range node.Variables {
+			if i > 0 {
+				p.write(", ")
+			}
+			p.assignmentVariable(variable)
+		}
+		p.write("}")
+	}
+	if node.Increment != nil {
+		p.write(spell(*node.Increment))
+		return
+	}
+	if node.IsDelayedAssign {
+		p.write(" <= ")
+	} else {
+		p.write(" = ")
+	}
+	p.expression(node.Value)
+}
+
+func (p *printer) assignmentVariable(node AssignmentVariableNode) {
+	p.write(spell(node.Identifier))
+	for _, member := range node.Members {
+		p.write("." + spell(member))
+	}
+	for _, selector := range node.Selectors {
+		p.selector(selector)
+	}
+}
+
+// ==============================
+// Expressions
+// ==============================
+
+func (p *printer) expression(node ExprNode) {
+	if node.Nested != nil {
+		p.write("(")
+		p.expression(*node.Nested)
+		p.write(")")
+	} else {
+		p.sizedValue(node.Value)
+	}
+	if node.Combinator != nil && node.Right != nil {
+		p.write(" " + spell(*node.Combinator) + " ")
+		p.expression(*node.Right)
+	}
+	if node.ExprTrue != nil && node.ExprFalse != nil {
+		p.write(" ? ")
+		p.expression(*node.ExprTrue)
+		p.write(" : ")
+		p.expression(*node.ExprFalse)
+	}
+}
+
+func (p *printer) sizedValue(node SizedValueNode) {
+	if node.Signed != nil {
+		p.write(spell(*node.Signed) + "(")
+	}
+	switch {
+	case node.Size != nil:
+		p.write("{" + spell(*node.Size) + "{")
+		p.elements(node)
+		p.write("}}")
+	case len(node.Elements) > 0:
+		p.write("{")
+		p.elements(node)
+		p.write("}")
+	case len(node.Values) == 1:
+		p.value(node.Values[0])
+	case len(node.Values) > 1:
+		p.write("{")
+		p.values(node.Values)
+		p.write("}")
+	}
+	if node.Signed != nil {
+		p.write(")")
+	}
+}
+
+// writes what a concatenation or replication is made of
+func (p *printer) elements(node SizedValueNode) {
+	if len(node.Elements) == 0 {
+		p.values(node.Values)
+		return
+	}
+	for i, element := range node.Elements {
+		if i > 0 {
+			p.write(", ")
+		}
+		p.sizedValue(element)
+	}
+}
+
+func (p *printer) values(values []ValueNode) {
+	for i, value := range values {
+		if i > 0 {
+			p.write(", ")
+		}
+		p.value(value)
+	}
+}
+
+func (p *printer) value(node ValueNode) {
+	if node.Unary != nil {
+		p.write(spell(*node.Unary))
+	}
+	if node.Scope != nil {
+		p.write(spell(*node.Scope) + "::")
+	}
+	names := []string{}
+	for _, token := range node.Value {
+		names = append(names, spell(token))
+	}
+	p.write(strings.Join(names, "."))
+	for _, selector := range node.Selectors {
+		p.selector(selector)
+	}
+}
+
+func (p *printer) selector(node SelectorNode) {
+	if node.IndexNode != nil {
+		p.write("[")
+		p.expression(node.IndexNode.Index)
+		p.write("]")
+	} else if node.RangeNode != nil {
+		p.rangeNode(*node.RangeNode)
+	}
+}
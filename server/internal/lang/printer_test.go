@@ -0,0 +1,131 @@
+package lang
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// corpus returns every source file under testdata
+func corpus(t *testing.T) []string {
+	t.Helper()
+	result := []string{}
+	for _, pattern := range []string{"*.v", "*.sv"} {
+		matches, err := filepath.Glob(filepath.Join("testdata", "*", pattern))
+		if err != nil {
+			t.Fatal(err)
+		}
+		result = append(result, matches...)
+	}
+	return result
+}
+
+// normalize returns the ast without anything that depends on
+// where it was in the code, so asts of differently laid out code compare equal
+func normalize(t *testing.T, ast FileNode) interface{} {
+	t.Helper()
+	encoded, err := MarshalAST(ast)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result interface{}
+	if err := json.Unmarshal(encoded, &result); err != nil {
+		t.Fatal(err)
+	}
+	var strip func(value interface{})
+	strip = func(value interface{}) {
+		switch value := value.(type) {
+		case map[string]interface{}:
+			for _, key := range []string{"span", "offset", "line", "start", "end"} {
+				delete(value, key)
+			}
+			for _, child := range value {
+				strip(child)
+			}
+		case []interface{}:
+			for _, child := range value {
+				strip(child)
+			}
+		}
+	}
+	strip(result)
+	return result
+}
+
+func parseCode(t *testing.T, code string, language Language) FileNode {
+	t.Helper()
+	tokens, err := newVLexer(zap.NewNop(), language).Lex(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ast, err := newParser(language).ParseFile(tokens)
+	if err != nil {
+		t.Fatalf("%v in printed code:\n%s", err, code)
+	}
+	return ast
+}
+
+func TestPrintRoundTrip(t *testing.T) {
+	for _, input := range corpus(t) {
+		t.Run(filepath.Base(input), func(t *testing.T) {
+			language := Verilog
+			if strings.HasSuffix(input, ".sv") {
+				language = SystemVerilog
+			}
+			_, ast := lexAndParse(t, input)
+
+			printed := Print(ast)
+			reparsed := parseCode(t, printed, language)
+			if !reflect.DeepEqual(normalize(t, ast), normalize(t, reparsed)) {
+				t.Errorf("printed code parses to a different ast:\n%s", printed)
+			}
+
+			// printing is stable, so printing the printed code doesn't change it
+			if reprinted := Print(reparsed); reprinted != printed {
+				t.Errorf("printing isn't stable, first:\n%s\nthen:\n%s", printed, reprinted)
+			}
+		})
+	}
+}
+
+func TestPrint(t *testing.T) {
+	ident := func(name string) Token {
+		return Token{Type: IDENTIFIER, Value: name}
+	}
+	value := func(name string) ExprNode {
+		return ExprNode{Value: SizedValueNode{Values: []ValueNode{{Value: []Token{ident(name)}}}}}
+	}
+	label := ident("clk")
+	gate := ident("u0")
+	tests := []struct {
+		name     string
+		node     Node
+		expected string
+	}{
+		{"expression", ExprNode{
+			Nested:     &ExprNode{Value: value("a").Value, Combinator: &Token{Type: OPERATOR, Value: "+"}, Right: &ExprNode{Value: value("b").Value}},
+			Combinator: &Token{Type: OPERATOR, Value: "*"},
+			Right:      &ExprNode{Value: SizedValueNode{Size: &Token{Type: LITERAL, Value: "2"}, Values: []ValueNode{{Value: []Token{ident("c")}}}}},
+		}, "(a + b) * {2{c}}"},
+		{"module application", ModuleApplicationNode{
+			ModuleName: ident("counter"),
+			GateName:   &gate,
+			Arguments:  []ArgumentNode{{Label: &label, Value: value("clk")}, {Value: value("q")}},
+		}, "counter u0(.clk(clk), q);"},
+		{"escaped identifier", AssignmentNode{
+			Variables: []AssignmentVariableNode{{Identifier: ident(`\a+b`)}},
+			Value:     value("c"),
+		}, `\a+b  = c`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if printed := Print(test.node); printed != test.expected {
+				t.Errorf("expected %q, got %q", test.expected, printed)
+			}
+		})
+	}
+}
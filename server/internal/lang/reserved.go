@@ -0,0 +1,255 @@
+// Code generated by gen_reserved.go from reserved_words.txt; DO NOT EDIT.
+
+package lang
+
+// reservedWords maps each reserved word to the first language that reserves it
+var reservedWords = map[string]Language{
+	"always":              Verilog,
+	"and":                 Verilog,
+	"assign":              Verilog,
+	"automatic":           Verilog,
+	"begin":               Verilog,
+	"buf":                 Verilog,
+	"bufif0":              Verilog,
+	"bufif1":              Verilog,
+	"case":                Verilog,
+	"casex":               Verilog,
+	"casez":               Verilog,
+	"cell":                Verilog,
+	"cmos":                Verilog,
+	"config":              Verilog,
+	"deassign":            Verilog,
+	"default":             Verilog,
+	"defparam":            Verilog,
+	"design":              Verilog,
+	"disable":             Verilog,
+	"edge":                Verilog,
+	"else":                Verilog,
+	"end":                 Verilog,
+	"endcase":             Verilog,
+	"endconfig":           Verilog,
+	"endfunction":         Verilog,
+	"endgenerate":         Verilog,
+	"endmodule":           Verilog,
+	"endprimitive":        Verilog,
+	"endspecify":          Verilog,
+	"endtable":            Verilog,
+	"endtask":             Verilog,
+	"event":               Verilog,
+	"for":                 Verilog,
+	"force":               Verilog,
+	"forever":             Verilog,
+	"fork":                Verilog,
+	"function":            Verilog,
+	"generate":            Verilog,
+	"genvar":              Verilog,
+	"highz0":              Verilog,
+	"highz1":              Verilog,
+	"if":                  Verilog,
+	"ifnone":              Verilog,
+	"incdir":              Verilog,
+	"include":             Verilog,
+	"initial":             Verilog,
+	"inout":               Verilog,
+	"input":               Verilog,
+	"instance":            Verilog,
+	"integer":             Verilog,
+	"join":                Verilog,
+	"large":               Verilog,
+	"liblist":             Verilog,
+	"library":             Verilog,
+	"localparam":          Verilog,
+	"macromodule":         Verilog,
+	"medium":              Verilog,
+	"module":              Verilog,
+	"nand":                Verilog,
+	"negedge":             Verilog,
+	"nmos":                Verilog,
+	"nor":                 Verilog,
+	"noshowcancelled":     Verilog,
+	"not":                 Verilog,
+	"notif0":              Verilog,
+	"notif1":              Verilog,
+	"or":                  Verilog,
+	"output":              Verilog,
+	"parameter":           Verilog,
+	"pmos":                Verilog,
+	"posedge":             Verilog,
+	"primitive":           Verilog,
+	"pull0":               Verilog,
+	"pull1":               Verilog,
+	"pulldown":            Verilog,
+	"pullup":              Verilog,
+	"pulsestyle_ondetect": Verilog,
+	"pulsestyle_onevent":  Verilog,
+	"rcmos":               Verilog,
+	"real":                Verilog,
+	"realtime":            Verilog,
+	"reg":                 Verilog,
+	"release":             Verilog,
+	"repeat":              Verilog,
+	"rnmos":               Verilog,
+	"rpmos":               Verilog,
+	"rtran":               Verilog,
+	"rtranif0":            Verilog,
+	"rtranif1":            Verilog,
+	"scalared":            Verilog,
+	"showcancelled":       Verilog,
+	"signed":              Verilog,
+	"small":               Verilog,
+	"specify":             Verilog,
+	"specparam":           Verilog,
+	"strong0":             Verilog,
+	"strong1":             Verilog,
+	"supply0":             Verilog,
+	"supply1":             Verilog,
+	"table":               Verilog,
+	"task":                Verilog,
+	"time":                Verilog,
+	"tran":                Verilog,
+	"tranif0":             Verilog,
+	"tranif1":             Verilog,
+	"tri":                 Verilog,
+	"tri0":                Verilog,
+	"tri1":                Verilog,
+	"triand":              Verilog,
+	"trior":               Verilog,
+	"trireg":              Verilog,
+	"unsigned":            Verilog,
+	"use":                 Verilog,
+	"uwire":               Verilog,
+	"vectored":            Verilog,
+	"wait":                Verilog,
+	"wand":                Verilog,
+	"weak0":               Verilog,
+	"weak1":               Verilog,
+	"while":               Verilog,
+	"wire":                Verilog,
+	"wor":                 Verilog,
+	"xnor":                Verilog,
+	"xor":                 Verilog,
+	"accept_on":           SystemVerilog,
+	"alias":               SystemVerilog,
+	"always_comb":         SystemVerilog,
+	"always_ff":           SystemVerilog,
+	"always_latch":        SystemVerilog,
+	"assert":              SystemVerilog,
+	"assume":              SystemVerilog,
+	"before":              SystemVerilog,
+	"bind":                SystemVerilog,
+	"bins":                SystemVerilog,
+	"binsof":              SystemVerilog,
+	"bit":                 SystemVerilog,
+	"break":               SystemVerilog,
+	"byte":                SystemVerilog,
+	"chandle":             SystemVerilog,
+	"checker":             SystemVerilog,
+	"class":               SystemVerilog,
+	"clocking":            SystemVerilog,
+	"const":               SystemVerilog,
+	"constraint":          SystemVerilog,
+	"context":             SystemVerilog,
+	"continue":            SystemVerilog,
+	"cover":               SystemVerilog,
+	"covergroup":          SystemVerilog,
+	"coverpoint":          SystemVerilog,
+	"cross":               SystemVerilog,
+	"dist":                SystemVerilog,
+	"do":                  SystemVerilog,
+	"endchecker":          SystemVerilog,
+	"endclass":            SystemVerilog,
+	"endclocking":         SystemVerilog,
+	"endgroup":            SystemVerilog,
+	"endinterface":        SystemVerilog,
+	"endpackage":          SystemVerilog,
+	"endprogram":          SystemVerilog,
+	"endproperty":         SystemVerilog,
+	"endsequence":         SystemVerilog,
+	"enum":                SystemVerilog,
+	"eventually":          SystemVerilog,
+	"expect":              SystemVerilog,
+	"export":              SystemVerilog,
+	"extends":             SystemVerilog,
+	"extern":              SystemVerilog,
+	"final":               SystemVerilog,
+	"first_match":         SystemVerilog,
+	"foreach":             SystemVerilog,
+	"forkjoin":            SystemVerilog,
+	"global":              SystemVerilog,
+	"iff":                 SystemVerilog,
+	"ignore_bins":         SystemVerilog,
+	"illegal_bins":        SystemVerilog,
+	"implements":          SystemVerilog,
+	"implies":             SystemVerilog,
+	"import":              SystemVerilog,
+	"inside":              SystemVerilog,
+	"int":                 SystemVerilog,
+	"interconnect":        SystemVerilog,
+	"interface":           SystemVerilog,
+	"intersect":           SystemVerilog,
+	"join_any":            SystemVerilog,
+	"join_none":           SystemVerilog,
+	"let":                 SystemVerilog,
+	"local":               SystemVerilog,
+	"logic":               SystemVerilog,
+	"longint":             SystemVerilog,
+	"matches":             SystemVerilog,
+	"modport":             SystemVerilog,
+	"nettype":             SystemVerilog,
+	"new":                 SystemVerilog,
+	"nexttime":            SystemVerilog,
+	"null":                SystemVerilog,
+	"package":             SystemVerilog,
+	"packed":              SystemVerilog,
+	"priority":            SystemVerilog,
+	"program":             SystemVerilog,
+	"property":            SystemVerilog,
+	"protected":           SystemVerilog,
+	"pure":                SystemVerilog,
+	"rand":                SystemVerilog,
+	"randc":               SystemVerilog,
+	"randcase":            SystemVerilog,
+	"randsequence":        SystemVerilog,
+	"ref":                 SystemVerilog,
+	"reject_on":           SystemVerilog,
+	"restrict":            SystemVerilog,
+	"return":              SystemVerilog,
+	"s_always":            SystemVerilog,
+	"s_eventually":        SystemVerilog,
+	"s_nexttime":          SystemVerilog,
+	"s_until":             SystemVerilog,
+	"s_until_with":        SystemVerilog,
+	"sequence":            SystemVerilog,
+	"shortint":            SystemVerilog,
+	"shortreal":           SystemVerilog,
+	"soft":                SystemVerilog,
+	"solve":               SystemVerilog,
+	"static":              SystemVerilog,
+	"string":              SystemVerilog,
+	"strong":              SystemVerilog,
+	"struct":              SystemVerilog,
+	"super":               SystemVerilog,
+	"sync_accept_on":      SystemVerilog,
+	"sync_reject_on":      SystemVerilog,
+	"tagged":              SystemVerilog,
+	"this":                SystemVerilog,
+	"throughout":          SystemVerilog,
+	"timeprecision":       SystemVerilog,
+	"timeunit":            SystemVerilog,
+	"type":                SystemVerilog,
+	"typedef":             SystemVerilog,
+	"union":               SystemVerilog,
+	"unique":              SystemVerilog,
+	"unique0":             SystemVerilog,
+	"until":               SystemVerilog,
+	"until_with":          SystemVerilog,
+	"untyped":             SystemVerilog,
+	"var":                 SystemVerilog,
+	"virtual":             SystemVerilog,
+	"void":                SystemVerilog,
+	"wait_order":          SystemVerilog,
+	"weak":                SystemVerilog,
+	"wildcard":            SystemVerilog,
+	"with":                SystemVerilog,
+	"within":              SystemVerilog,
+}
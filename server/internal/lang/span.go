@@ -0,0 +1,57 @@
+package lang
+
+// Span is the range of tokens that a node was parsed from
+type Span struct {
+	Start       Token // first token of the node
+	End         Token // last token of the node
+	StartOffset int   // byte offset of the start of the first token
+	EndOffset   int   // byte offset of the end of the last token, exclusive
+}
+
+// Contains returns whether the byte offset is inside the span
+func (s Span) Contains(offset int) bool {
+	return s.StartOffset <= offset && offset < s.EndOffset
+}
+
+// ContainsPosition returns whether the position, a line and a utf-16 character on it, is inside the span
+func (s Span) ContainsPosition(line int, character int) bool {
+	if s == (Span{}) {
+		return false
+	}
+	if line < s.Start.Line() || line > s.End.Line() {
+		return false
+	}
+	if line == s.Start.Line() && character < s.Start.StartCharacter() {
+		return false
+	}
+	return line != s.End.Line() || character <= s.End.EndCharacter()
+}
+
+// bounds returns the first and last tokens that aren't trivia from start
+// up to end, and false if they're all trivia
+func (p *Parser) bounds(tokens []Token, start int, end int) (first int, last int, ok bool) {
+	first = p.skip(tokens, p.skipTokens, start)
+	last = end - 1
+	if last >= len(tokens) {
+		last = len(tokens) - 1
+	}
+	for last > first && p.isTrivia(tokens[last]) {
+		last--
+	}
+	return first, last, first < len(tokens) && first <= last
+}
+
+// span returns the span of the node made of the tokens from start up to end,
+// where start and end can include surrounding trivia
+func (p *Parser) span(tokens []Token, start int, end int) Span {
+	first, last, ok := p.bounds(tokens, start, end)
+	if !ok {
+		return Span{}
+	}
+	return Span{
+		Start:       tokens[first],
+		End:         tokens[last],
+		StartOffset: tokens[first].Offset(),
+		EndOffset:   tokens[last].Offset() + len(tokens[last].Value),
+	}
+}
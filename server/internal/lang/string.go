@@ -0,0 +1,130 @@
+package lang
+
+import (
+	"strconv"
+	"strings"
+)
+
+// the IEEE 1364-2005 string grammar, section 3.6; a backslash escapes the next character
+const stringPattern = `"([^"\\\n]|(\\.))*"`
+
+// FormatSpecifier is a format specifier inside a string, ie %d or %0h
+type FormatSpecifier struct {
+	Start int  // offset of the % into the token's Value, inclusive
+	End   int  // offset of the end of the specifier into the token's Value, exclusive
+	Verb  byte // the lowercase letter that says how to format, ie d for both %d and %D
+}
+
+// the system tasks whose string arguments are formats
+var formatTasks = map[string]bool{
+	"display": true, "displayb": true, "displayh": true, "displayo": true,
+	"write": true, "writeb": true, "writeh": true, "writeo": true,
+	"strobe": true, "strobeb": true, "strobeh": true, "strobeo": true,
+	"monitor": true, "monitorb": true, "monitorh": true, "monitoro": true,
+	"fdisplay": true, "fdisplayb": true, "fdisplayh": true, "fdisplayo": true,
+	"fwrite": true, "fwriteb": true, "fwriteh": true, "fwriteo": true,
+	"fstrobe": true, "fstrobeb": true, "fstrobeh": true, "fstrobeo": true,
+	"fmonitor": true, "fmonitorb": true, "fmonitorh": true, "fmonitoro": true,
+	"swrite": true, "swriteb": true, "swriteh": true, "swriteo": true,
+	"sformat": true, "sformatf": true,
+	"error": true, "warning": true, "info": true, "fatal": true,
+}
+
+// IsFormatTask returns whether the system task with the given name, without
+// its dollar sign, takes format strings, ie display
+func IsFormatTask(name string) bool {
+	return formatTasks[name]
+}
+
+// decodes the escapes of a string literal, dropping its quotes
+func decodeString(literal string) string {
+	literal = literal[1 : len(literal)-1]
+	if strings.IndexByte(literal, '\\') < 0 {
+		return literal
+	}
+	result := strings.Builder{}
+	result.Grow(len(literal))
+	for i := 0; i < len(literal); i++ {
+		if literal[i] != '\\' || i+1 == len(literal) {
+			result.WriteByte(literal[i])
+			continue
+		}
+		i++
+		switch literal[i] {
+		case 'n':
+			result.WriteByte('\n')
+		case 't':
+			result.WriteByte('\t')
+		case 'v':
+			result.WriteByte('\v')
+		case 'f':
+			result.WriteByte('\f')
+		case 'a':
+			result.WriteByte('\a')
+		case '0', '1', '2', '3', '4', '5', '6', '7':
+			// up to 3 octal digits
+			end := i + 1
+			for end < len(literal) && end < i+3 && literal[end] >= '0' && literal[end] <= '7' {
+				end++
+			}
+			value, _ := strconv.ParseUint(literal[i:end], 8, 8)
+			result.WriteByte(byte(value))
+			i = end - 1
+		default:
+			// \\, \", and anything else just stand for the character itself
+			result.WriteByte(literal[i])
+		}
+	}
+	return result.String()
+}
+
+// Returns whether this token is a string literal
+func (t Token) IsString() bool {
+	return t.Type == LITERAL && strings.HasPrefix(t.Value, `"`)
+}
+
+// Returns the value of a string literal with its escapes decoded
+// and without its quotes. Other tokens have no decoded value
+func (t Token) Decoded() string {
+	if !t.IsString() {
+		return ""
+	}
+	return decodeString(t.Value)
+}
+
+// Returns the format specifiers inside a string literal, ie %d and %0h.
+// %% is an escaped percent sign rather than a specifier
+func (t Token) FormatSpecifiers() []FormatSpecifier {
+	result := []FormatSpecifier{}
+	if !t.IsString() {
+		return result
+	}
+	value := t.Value
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' {
+			i++
+			continue
+		}
+		if value[i] != '%' {
+			continue
+		}
+
+		// %[-][0][width][.precision]verb
+		end := i + 1
+		for end < len(value) && strings.IndexByte("-0123456789.", value[end]) >= 0 {
+			end++
+		}
+		if end >= len(value) {
+			break
+		}
+		if value[end] == '%' && end == i+1 {
+			i = end
+			continue
+		}
+		if strings.IndexByte("bBoOdDhHxXcCsStTeEfFgGmMlLvVuUzZ", value[end]) >= 0 {
+			result = append(result, FormatSpecifier{Start: i, End: end + 1, Verb: value[end] | 0x20})
+		}
+		i = end
+	}
+	return result
+}
@@ -0,0 +1,325 @@
+package lang
+
+// SymbolKind is what kind of thing a symbol names
+type SymbolKind int
+
+const (
+	PortSymbol       SymbolKind = iota // a port of a module or interface
+	VariableSymbol                     // a net or variable, ie wire, reg, logic, integer, or genvar
+	ParameterSymbol                    // a parameter or localparam
+	InstanceSymbol                     // an instance of a module or interface
+	TypeSymbol                         // a typedef
+	EnumMemberSymbol                   // a literal of an enum typedef
+	TaskSymbol                         // a task
+	BlockSymbol                        // a named begin block
+)
+
+func (k SymbolKind) String() string {
+	switch k {
+	case PortSymbol:
+		return "port"
+	case VariableSymbol:
+		return "variable"
+	case ParameterSymbol:
+		return "parameter"
+	case InstanceSymbol:
+		return "instance"
+	case TypeSymbol:
+		return "type"
+	case EnumMemberSymbol:
+		return "enum literal"
+	case TaskSymbol:
+		return "task"
+	case BlockSymbol:
+		return "block"
+	}
+	return "unknown"
+}
+
+// Symbol is something that's declared in a scope
+type Symbol struct {
+	Kind       SymbolKind
+	Identifier Token              // where the symbol is declared
+	Type       *TypeNode          // type it's declared with, or the module of an instance, could be nil
+	Ranges     []RangeNode        // unpacked dimensions, ie [0:3] in mem [0:3], or the range of an instance array
+	Direction  *Token             // input, output, or inout for ports, could be nil
+	Interface  *InterfacePortNode // interface of an interface port, could be nil
+	Typedef    *TypedefNode       // the typedef that a type symbol declares, could be nil
+	Value      *ExprNode          // value that a parameter or variable is declared with, could be nil
+}
+
+// Name returns the name of the symbol, see Token.Name
+func (s *Symbol) Name() string {
+	return s.Identifier.Name()
+}
+
+// TypeName returns the name of the user-defined type, interface, or module
+// that the symbol is, or an empty string if it's a built in type
+func (s *Symbol) TypeName() string {
+	if s.Interface != nil {
+		return s.Interface.Interface.Name()
+	}
+	if s.Type != nil && s.Type.Type.Type == IDENTIFIER {
+		return s.Type.Type.Name()
+	}
+	return ""
+}
+
+// Detail describes the symbol, ie "input wire [7:0]" for a port or "instance of fifo"
+func (s *Symbol) Detail() string {
+	switch {
+	case s.Kind == InstanceSymbol && s.Type != nil:
+		return "instance of " + s.Type.Type.Value
+	case s.Interface != nil:
+		return Print(*s.Interface)
+	case s.Type != nil:
+		detail := Print(*s.Type)
+		if s.Direction != nil && s.Type.Direction == nil {
+			detail = s.Direction.Value + " " + detail
+		}
+		return detail
+	case s.Direction != nil:
+		return s.Direction.Value
+	}
+	return s.Kind.String()
+}
+
+// directionOnly returns whether the type is just a direction, ie input in input [7:0] a
+func directionOnly(t *TypeNode) bool {
+	return t != nil && t.Direction != nil && t.Type == *t.Direction
+}
+
+// merges another declaration of a non-ANSI port, since the port list, its direction,
+// and its type can each declare it, in any order
+func (s *Symbol) merge(other *Symbol) {
+	s.Kind = PortSymbol
+	if s.Direction == nil {
+		s.Direction = other.Direction
+	}
+	if s.Type == nil || (directionOnly(s.Type) && other.Type != nil && !directionOnly(other.Type)) {
+		s.Type = other.Type
+	}
+}
+
+// ScopeKind is what kind of construct a scope is
+type ScopeKind int
+
+const (
+	FileScope ScopeKind = iota
+	ModuleScope
+	InterfaceScope
+	PackageScope
+	BlockScope // a begin block, named or not, including the blocks of generate constructs
+	ForScope   // a for loop that declares its loop variable
+	TaskScope
+)
+
+// Scope is the symbols declared directly inside of a file, module, interface,
+// package, block, for loop, or task. Everything declared in a scope is visible
+// throughout it, and inside of the scopes nested in it
+type Scope struct {
+	Kind     ScopeKind
+	Name     *Token // name of the module, interface, package, block, or task, could be nil
+	Parent   *Scope // could be nil for the file
+	Children []*Scope
+	Symbols  []*Symbol        // in the order that they're declared
+	Imports  []ImportItemNode // packages and items imported into the scope
+	Span     Span
+	byName   map[string]*Symbol
+}
+
+func newScope(kind ScopeKind, name *Token, span Span, parent *Scope) *Scope {
+	scope := &Scope{Kind: kind, Name: name, Parent: parent, Span: span, byName: map[string]*Symbol{}}
+	if parent != nil {
+		parent.Children = append(parent.Children, scope)
+	}
+	return scope
+}
+
+// declare adds the symbol to the scope, keeping the first declaration of a name
+func (s *Scope) declare(symbol *Symbol) {
+	if existing, ok := s.byName[symbol.Name()]; ok {
+		if existing.Kind == PortSymbol || symbol.Kind == PortSymbol {
+			existing.merge(symbol)
+		}
+		return
+	}
+	s.byName[symbol.Name()] = symbol
+	s.Symbols = append(s.Symbols, symbol)
+}
+
+// Symbol returns the symbol with the given name that's declared directly in the scope, or nil
+func (s *Scope) Symbol(name string) *Symbol {
+	return s.byName[name]
+}
+
+// Lookup returns the symbol that the name refers to inside of the scope,
+// which is declared either in the scope or in one containing it, or nil
+func (s *Scope) Lookup(name string) *Symbol {
+	for scope := s; scope != nil; scope = scope.Parent {
+		if symbol, ok := scope.byName[name]; ok {
+			return symbol
+		}
+	}
+	return nil
+}
+
+// Visible returns every symbol that can be referred to inside of the scope,
+// without the ones that inner declarations hide
+func (s *Scope) Visible() []*Symbol {
+	result := []*Symbol{}
+	seen := map[string]bool{}
+	for scope := s; scope != nil; scope = scope.Parent {
+		for _, symbol := range scope.Symbols {
+			if !seen[symbol.Name()] {
+				seen[symbol.Name()] = true
+				result = append(result, symbol)
+			}
+		}
+	}
+	return result
+}
+
+// ScopeAt returns the innermost scope that contains the position
+func (s *Scope) ScopeAt(line int, character int) *Scope {
+	for _, child := range s.Children {
+		if child.Span.ContainsPosition(line, character) {
+			return child.ScopeAt(line, character)
+		}
+	}
+	return s
+}
+
+// Module returns the module, interface, or package scope that the scope is inside of, or nil
+func (s *Scope) Module() *Scope {
+	for scope := s; scope != nil; scope = scope.Parent {
+		if scope.Kind == ModuleScope || scope.Kind == InterfaceScope || scope.Kind == PackageScope {
+			return scope
+		}
+	}
+	return nil
+}
+
+// child returns the scope directly inside of this one that was made for the node with the span
+func (s *Scope) child(span Span) *Scope {
+	for _, child := range s.Children {
+		if child.Span == span {
+			return child
+		}
+	}
+	return nil
+}
+
+// NewSymbolTable returns the scope of the file, whose children
+// are the scopes of its modules, interfaces, and packages
+func NewSymbolTable(file FileNode) *Scope {
+	root := newScope(FileScope, nil, file.Span, nil)
+	Walk(declarer{scope: root}, file)
+	return root
+}
+
+// newModuleScope returns the scope of a module on its own
+func newModuleScope(module ModuleNode) *Scope {
+	root := newScope(FileScope, nil, Span{}, nil)
+	Walk(declarer{scope: root}, module)
+	return root.Children[0]
+}
+
+// declarer declares what each node declares in the scope it's in,
+// opening new scopes for the nodes that have their own
+type declarer struct {
+	scope *Scope
+}
+
+func (d declarer) Visit(node Node, path []Node) Visitor {
+	switch node := node.(type) {
+	case ModuleNode:
+		scope := newScope(ModuleScope, &node.Identifier, node.Span, d.scope)
+		scope.declarePorts(node.PortList)
+		return declarer{scope: scope}
+	case InterfaceNode:
+		scope := newScope(InterfaceScope, &node.Identifier, node.Span, d.scope)
+		scope.declarePorts(node.PortList)
+		return declarer{scope: scope}
+	case PackageNode:
+		return declarer{scope: newScope(PackageScope, &node.Identifier, node.Span, d.scope)}
+	case PortListNode:
+		// already declared along with the module or interface
+		return nil
+	case ImportNode:
+		d.scope.Imports = append(d.scope.Imports, node.Items...)
+		return nil
+	case DeclarationNode:
+		kind := VariableSymbol
+		if node.Type.Direction != nil {
+			kind = PortSymbol
+		} else if node.Type.Type.Value == "parameter" || node.Type.Type.Value == "localparam" {
+			kind = ParameterSymbol
+		}
+		for idx := range node.Variables {
+			variable := node.Variables[idx]
+			symbol := &Symbol{Kind: kind, Identifier: variable.Identifier, Type: &node.Type, Ranges: variable.Ranges, Direction: node.Type.Direction}
+			if idx < len(node.Values) {
+				symbol.Value = &node.Values[idx]
+			}
+			d.scope.declare(symbol)
+		}
+		return nil
+	case TypedefNode:
+		d.scope.declare(&Symbol{Kind: TypeSymbol, Identifier: node.Identifier, Type: node.Type, Typedef: &node})
+		if node.Enum != nil {
+			for _, member := range node.Enum.Members {
+				d.scope.declare(&Symbol{Kind: EnumMemberSymbol, Identifier: member.Identifier, Type: &TypeNode{Type: node.Identifier}})
+			}
+		}
+		return nil
+	case ModuleApplicationNode:
+		if node.GateName != nil {
+			symbol := &Symbol{Kind: InstanceSymbol, Identifier: *node.GateName, Type: &TypeNode{Type: node.ModuleName}}
+			if node.Range != nil {
+				symbol.Ranges = []RangeNode{*node.Range}
+			}
+			d.scope.declare(symbol)
+		}
+		return nil
+	case TaskNode:
+		d.scope.declare(&Symbol{Kind: TaskSymbol, Identifier: node.Identifier})
+		return declarer{scope: newScope(TaskScope, &node.Identifier, node.Span, d.scope)}
+	case BeginBlockNode:
+		if node.Label != nil {
+			d.scope.declare(&Symbol{Kind: BlockSymbol, Identifier: *node.Label})
+		}
+		return declarer{scope: newScope(BlockScope, node.Label, node.Span, d.scope)}
+	case ForBlockNode:
+		if node.InitializerType == nil || node.Initializer == nil {
+			return d
+		}
+		scope := newScope(ForScope, nil, node.Span, d.scope)
+		for _, variable := range node.Initializer.Variables {
+			scope.declare(&Symbol{Kind: VariableSymbol, Identifier: variable.Identifier, Type: node.InitializerType})
+		}
+		return declarer{scope: scope}
+	case ExprNode:
+		// nothing inside of an expression declares anything
+		return nil
+	}
+	return d
+}
+
+// declarePorts declares the ports of a module or interface
+func (s *Scope) declarePorts(portList PortListNode) {
+	if len(portList.Declarations) == 0 {
+		// non-ANSI ports get their direction and type from declarations in the body
+		for _, port := range portList.Ports {
+			s.declare(&Symbol{Kind: PortSymbol, Identifier: port})
+		}
+		return
+	}
+	for _, port := range portList.Declarations {
+		symbol := &Symbol{Kind: PortSymbol, Identifier: port.Identifier, Type: port.Type, Interface: port.Interface}
+		if port.Type != nil {
+			symbol.Direction = port.Type.Direction
+		}
+		s.declare(symbol)
+	}
+}
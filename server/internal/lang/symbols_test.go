@@ -0,0 +1,79 @@
+package lang
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func symbolTable(t *testing.T, code string) *Scope {
+	t.Helper()
+	tokens, err := NewVLexer(zap.NewNop()).Lex(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ast, err := NewParser().ParseFile(tokens)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewSymbolTable(ast)
+}
+
+func TestSymbolTablePorts(t *testing.T) {
+	table := symbolTable(t, "module m (a, b);\n  input [7:0] a;\n  wire [7:0] a;\n  output reg b;\n  fifo f (.d(a));\nendmodule\n")
+	module := table.Children[0]
+	if module.Kind != ModuleScope || module.Name.Value != "m" {
+		t.Fatalf("expected the scope of module m, got %+v", module)
+	}
+
+	// the port list, the direction, and the type all declare a, which is still one port
+	expected := []struct {
+		name   string
+		kind   SymbolKind
+		detail string
+	}{
+		{"a", PortSymbol, "input wire [7:0]"},
+		{"b", PortSymbol, "output reg"},
+		{"f", InstanceSymbol, "instance of fifo"},
+	}
+	if len(module.Symbols) != len(expected) {
+		t.Fatalf("expected %d symbols, got %d", len(expected), len(module.Symbols))
+	}
+	for k, symbol := range module.Symbols {
+		if symbol.Name() != expected[k].name || symbol.Kind != expected[k].kind || symbol.Detail() != expected[k].detail {
+			t.Errorf("symbol %d is %s %v %q, expected %s %v %q", k, symbol.Name(), symbol.Kind, symbol.Detail(), expected[k].name, expected[k].kind, expected[k].detail)
+		}
+	}
+}
+
+func TestSymbolTableScopeAt(t *testing.T) {
+	code := "module m;\n  wire x;\n  initial begin : blk\n    reg y;\n    y = x;\n  end\nendmodule\n"
+	table := symbolTable(t, code)
+
+	// inside the block, both the block's and the module's symbols are visible
+	inner := table.ScopeAt(4, 6)
+	if inner.Kind != BlockScope || inner.Name == nil || inner.Name.Value != "blk" {
+		t.Fatalf("expected the scope of blk, got %+v", inner)
+	}
+	if inner.Lookup("y") == nil || inner.Lookup("x") == nil {
+		t.Errorf("x and y should both be visible inside blk")
+	}
+	if len(inner.Visible()) != 3 {
+		t.Errorf("expected y, x, and blk to be visible inside blk, got %d symbols", len(inner.Visible()))
+	}
+
+	// outside of it, only the module's are
+	outer := table.ScopeAt(1, 2)
+	if outer.Kind != ModuleScope {
+		t.Fatalf("expected the scope of m, got %+v", outer)
+	}
+	if outer.Lookup("y") != nil {
+		t.Errorf("y shouldn't be visible outside of blk")
+	}
+	if symbol := outer.Lookup("blk"); symbol == nil || symbol.Kind != BlockSymbol {
+		t.Errorf("blk should be a block in m")
+	}
+	if inner.Module() != outer {
+		t.Errorf("blk should be inside of m")
+	}
+}
@@ -0,0 +1,13 @@
+module always_blocks(input clk, input rst_n, input a, input b, output reg q, output reg r, output reg s);
+    always @(posedge clk or negedge rst_n)
+        if (!rst_n)
+            q <= 0;
+        else
+            q <= a;
+
+    always @(a, b) r = a & b;
+
+    always @(a or b) s = a | b;
+
+    always #5 s = ~s;
+endmodule
@@ -0,0 +1,18 @@
+module assignments(input clk, input [7:0] d, output [7:0] q, output [3:0] hi, output [3:0] lo);
+    reg [7:0] r;
+    reg [7:0] mem [0:3];
+
+    // continuous assignments
+    assign q = r;
+    assign {hi, lo} = d;
+
+    always @(posedge clk) begin
+        // blocking and non-blocking assignments
+        r = d;
+        r <= d + 1;
+        r[0] <= 1'b0;
+        r[7:4] <= d[3:0];
+        mem[2] <= d;
+        mem[1][3:0] <= d[7:4];
+    end
+endmodule
@@ -0,0 +1,20 @@
+// implicit connections, and ports that are left unconnected
+module adder(input logic [7:0] a, input logic [7:0] b, input logic cin, output logic [7:0] sum, output logic cout);
+    assign {cout, sum} = a + b + cin;
+endmodule
+
+module connections(input logic [7:0] a, input logic [7:0] b, output logic [7:0] sum);
+    logic cout;
+
+    // .name and .* connect the signals with the same names
+    adder named(.a, .b, .cin(1'b0), .sum, .cout);
+    adder implicit(.cin(1'b0), .*);
+    adder unknown(.a, .b(b), .cin, .sum(sum), .carry);
+    adder no_carry_in(.*);
+    adder partial(.cin(cout), .sum(), .*);
+
+    // inputs that are left floating
+    adder omitted(.a(a), .sum(sum));
+    adder empty(.a(a), .b(), .cin(), .sum(sum), .cout());
+    adder nothing();
+endmodule
@@ -0,0 +1,19 @@
+module declarations;
+    parameter WIDTH = 8, DEPTH = 4;
+    localparam SIZE = WIDTH * DEPTH;
+
+    wire a, b;
+    wire [WIDTH-1:0] bus = 8'hA5; // initialized
+    reg [7:0] mem [0:DEPTH-1];
+    reg [15:0] sample;
+    integer count;
+    real ratio;
+    time stamp;
+    genvar g;
+    tri t;
+    supply0 gnd;
+    supply1 vdd;
+    wand wa;
+    wor wo;
+    wire [3:0] x = 4'd1, y = 4'd2;
+endmodule
@@ -0,0 +1,42 @@
+`default_nettype none
+module known(input a, output y);
+    assign y = a;
+endmodule
+
+module diagnostics(input a, output y);
+    // unknown variables, modules, and ports
+    assign y = b;
+    assign missing = a;
+    unknown u1(.a(a));
+    known u2(.a(a), .z(y));
+    known u3(.a(floating), .y(y));
+    always @(a) c = `UNDEFINED;
+endmodule
+
+`default_nettype wire
+module implicit(input a);
+    assign implicit_net = a;
+endmodule
+
+// the port list and the port declarations disagree
+module mismatched(a, b, y);
+    input a;
+    input c;
+    output y;
+    assign y = a & b & c;
+endmodule
+
+module redeclared(input a, output y);
+    wire y;
+    output a;
+    assign y = a;
+endmodule
+
+// connections that don't match the ports of known
+module connections(input a, input b, output y, output z);
+    known too_many(a, y, b);
+    known too_few(a);
+    known mixed(.a(a), y);
+    known twice(.a(a), .a(b), .y(y));
+    known positional(b, z);
+endmodule
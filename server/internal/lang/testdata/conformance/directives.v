@@ -0,0 +1,25 @@
+`timescale 1ns / 1ps
+`include "defs.vh"
+// the width of every bus
+`define WIDTH 8
+`define MAX(a, b) ((a) > (b) ? (a) : (b))
+`default_nettype none
+
+`ifdef SIMULATION
+`celldefine
+module directives(input wire [`WIDTH-1:0] a, output wire [`WIDTH-1:0] y);
+    `define INNER 1
+    assign y = a + `INNER;
+    `undef INNER
+endmodule
+`endcelldefine
+`endif
+
+`undef WIDTH
+`default_nettype wire
+
+// macros are unknown before they're defined and after they're undefined
+module order(output wire [7:0] q);
+    assign q = `LATER + `WIDTH;
+endmodule
+`define LATER 8
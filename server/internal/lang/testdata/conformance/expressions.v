@@ -0,0 +1,21 @@
+module expressions(input [7:0] a, input [7:0] b, input [2:0] s, output [15:0] y);
+    wire [7:0] arith = a + b - a * b / 2 % 3;
+    wire [7:0] logic_ops = a & b | a ^ b;
+    wire [7:0] shifts = (a << 2) >> 1;
+    wire cmp = a == b || a != b && a < b;
+    wire cmp2 = a <= b || a >= b || a > b;
+    wire case_eq = a === b || a !== b;
+    wire [7:0] unary = ~a;
+    wire not_a = !a;
+    wire [7:0] neg = -a;
+    wire [7:0] ternary = s[0] ? a : s[1] ? b : 8'd0;
+    wire [15:0] concat = {a, b};
+    wire [15:0] replicate = {2{a}};
+    wire [7:0] nested = (a + b) * (a - b);
+    wire [3:0] part = a[7:4];
+    wire bit_select = a[s];
+    wire [7:0] literals = 8'b1010_x0z1 + 8'o17 + 8'd255 + 8'hfF + 'h3 + 12;
+    wire [7:0] signed_cast = $signed(a) + $unsigned(b);
+    wire [31:0] now = $time;
+    wire [15:0] macro_width = {16{1'b0}};
+endmodule
@@ -0,0 +1,11 @@
+module generate_blocks(input [3:0] a, output [3:0] y);
+    genvar i;
+    generate
+        for (i = 0; i < 4; i = i + 1) begin : bits
+            assign y[i] = ~a[i];
+        end
+        if (1) begin
+            wire unused;
+        end
+    endgenerate
+endmodule
@@ -0,0 +1,21 @@
+module leaf(input a, input b, output y);
+    assign y = a & b;
+endmodule
+
+module instances(input a, input b, output [3:0] y);
+    wire w;
+
+    // named and positional connections
+    leaf named(.a(a), .b(b), .y(y[0]));
+    leaf positional(a, b, y[1]);
+    leaf keyword_gate(.a(a), .b(b), .y(y[2]));
+    leaf array [1:0] (a, b, w);
+    leaf(a, b, y[3]);
+
+    // gate primitives
+    and g1(w, a, b);
+    or g2(w, a, b);
+    not (w, a);
+
+    defparam named.WIDTH = 4;
+endmodule
@@ -0,0 +1,32 @@
+// a handshake
+interface handshake_if(input logic clk);
+    logic valid, ready;
+    logic [7:0] data;
+
+    // the side that sends
+    modport source(output valid, output data, input ready);
+    modport sink(input valid, data, output ready);
+endinterface : handshake_if
+
+module producer(handshake_if.source out, input logic clk);
+    always_ff @(posedge clk) begin
+        out.valid <= 1;
+        out.data <= 8'd1;
+        out.ready <= 1;
+        out.missing <= 0;
+    end
+endmodule
+
+module consumer(handshake_if in);
+    logic taken;
+    assign taken = in.valid && in.ready;
+endmodule
+
+module top(input logic clk);
+    handshake_if bus(clk);
+    producer p(.out(bus), .clk(clk));
+    consumer c(.in(bus));
+endmodule
+
+module bad_ports(unknown_if.port a, handshake_if.nope b);
+endmodule
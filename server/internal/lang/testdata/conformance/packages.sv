@@ -0,0 +1,55 @@
+// configuration shared by the design
+package cfg_pkg;
+    parameter int WIDTH = 8;
+    localparam DEPTH = 4;
+
+    // the states of the machine
+    typedef enum logic [1:0] {
+        IDLE, // waiting
+        RUN = 2'b01,
+        DONE
+    } state_t;
+
+    typedef enum {A, B} plain_t;
+
+    typedef struct packed {
+        logic [WIDTH-1:0] data;
+        state_t state;
+    } entry_t;
+
+    typedef struct {
+        logic valid;
+    } unpacked_t;
+
+    typedef logic [WIDTH-1:0] word_t;
+endpackage
+
+import cfg_pkg::*;
+
+module uses_package import cfg_pkg::WIDTH, cfg_pkg::state_t; (input logic clk, output cfg_pkg::word_t w);
+    import cfg_pkg::entry_t;
+    entry_t entry;
+    cfg_pkg::state_t state;
+    assign w = entry.data;
+
+    always_ff @(posedge clk) begin
+        state <= cfg_pkg::RUN;
+        entry.state <= IDLE;
+        entry.missing <= 0;
+    end
+endmodule
+
+module bad_package;
+    import nope_pkg::*;
+    logic x = cfg_pkg::NOPE;
+    other_pkg::t y;
+endmodule
+
+import missing_pkg::*;
+import cfg_pkg::GONE;
+
+module after_missing;
+endmodule
+
+module also_after_missing;
+endmodule
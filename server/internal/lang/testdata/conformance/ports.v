@@ -0,0 +1,27 @@
+// ansi-style ports, with comments
+module ansi(
+    input clk, // the clock
+    input wire [7:0] data,
+    // the result
+    output reg [7:0] q,
+    inout [1:0] pins,
+    output [3:0] s
+);
+    always @(posedge clk) q <= data;
+endmodule
+
+// non-ansi ports are declared in the body
+module non_ansi(clk, data, q);
+    input clk;
+    input [7:0] data;
+    output [7:0] q;
+    reg [7:0] q;
+
+    always @(posedge clk) q <= data;
+endmodule
+
+module no_ports;
+endmodule
+
+module empty_ports();
+endmodule
@@ -0,0 +1,18 @@
+module procedural(input logic clk, input logic [3:0] a, output logic [3:0] y);
+    logic [3:0] count;
+    logic latch;
+
+    always_comb begin
+        y = a;
+    end
+
+    always_latch
+        if (clk) latch = a[0];
+
+    always_ff @(posedge clk) begin
+        for (int i = 0; i < 4; i++) begin
+            count[i] <= a[i];
+        end
+        y <= '0;
+    end
+endmodule
@@ -0,0 +1,37 @@
+// names are visible throughout the scope they're declared in,
+// and only inside of it
+module scopes (input logic clk, output logic [7:0] q);
+    always_ff @(posedge clk) begin
+        q <= later;
+    end
+    logic [7:0] later;
+
+    always_comb begin : inner
+        logic [7:0] tmp;
+        tmp = q;
+    end
+    assign later = tmp;
+
+    always_comb begin
+        for (int i = 0; i < 8; i++) begin
+            logic bit_i;
+            bit_i = q[i];
+        end
+    end
+    assign later = i + bit_i;
+
+    generate
+        for (k = 0; k < 2; k = k + 1) begin : gen
+            logic w;
+            assign w = clk;
+        end
+    endgenerate
+    assign later = w;
+    genvar k;
+
+    task clear;
+        logic r;
+        r = 0;
+    endtask
+    initial r = 1;
+endmodule
@@ -0,0 +1,36 @@
+module statements(input clk, input [1:0] sel, input [7:0] a, output reg [7:0] y);
+    integer i;
+    reg [7:0] acc;
+
+    always @(posedge clk) begin : body
+        if (sel == 2'b00) begin
+            y <= a;
+        end else if (sel == 2'b01) begin
+            y <= ~a;
+        end else
+            y <= 0;
+
+        case (sel)
+            2'b00: y <= a;
+            2'b01, 2'b10: begin
+                y <= a + 1;
+            end
+            default: y <= 0;
+        endcase
+
+        casez (sel)
+            2'b1?: y <= 1;
+        endcase
+
+        casex (sel)
+            2'bx1: y <= 2;
+        endcase
+
+        acc = 0;
+        for (i = 0; i < 8; i = i + 1)
+            acc = acc + a[i];
+
+        $display("acc = %d", acc);
+        #1 y <= acc;
+    end
+endmodule
@@ -0,0 +1,15 @@
+module tasks;
+    reg [7:0] count;
+
+    // clears the count
+    task reset;
+        count = 0;
+        $display("reset");
+    endtask
+
+    initial begin
+        count = count + 1;
+    end
+
+    initial $display("started");
+endmodule
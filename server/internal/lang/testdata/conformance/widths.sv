@@ -0,0 +1,37 @@
+// widths of systemverilog types
+package types;
+    typedef enum logic [1:0] {IDLE, BUSY, DONE} state_t;
+    typedef struct packed {
+        logic valid;
+        logic [7:0] data;
+    } packet_t;
+endpackage
+
+module sink(input types::packet_t packet, input byte value, output int count);
+    assign count = value;
+endmodule
+
+module typed;
+    import types::*;
+    state_t state;
+    packet_t packet;
+    logic [8:0] flat;
+    logic [7:0] value;
+    int count;
+    shortint half;
+
+    // these fit
+    assign flat = packet;
+    assign value = packet.data;
+    assign state = DONE;
+    assign half = count[15:0];
+
+    // these don't
+    assign state = 3'b100;
+    assign value = packet;
+    assign packet.valid = value;
+    assign half = count;
+
+    sink implicit(.packet, .value, .count(half));
+    sink everything(.*, .count(half));
+endmodule
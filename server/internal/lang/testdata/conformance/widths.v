@@ -0,0 +1,55 @@
+// widths of expressions, and assignments and connections that truncate or extend them
+module narrow(input [WIDTH-1:0] a, input [3:0] b, output [7:0] y, output z);
+    parameter WIDTH = 8;
+    parameter DEPTH = 2;
+    assign y = a & {b, b};
+    assign z = a[0] && b[0];
+endmodule
+
+module widths(input [15:0] wide, input [7:0] data, output [7:0] out, output flag);
+    parameter WIDTH = 8;
+    localparam [3:0] SMALL = 5'd20;
+    wire [WIDTH-1:0] sized;
+    wire [2*WIDTH-1:0] doubled;
+    wire [7:0] initialized = wide;
+    reg [7:0] mem [0:3];
+    reg [3:0] count;
+    integer i;
+
+    // these fit
+    assign out = data + 1;
+    assign sized = wide[15:8];
+    assign doubled = {data, sized};
+    assign doubled = {2{data}};
+    assign doubled = {{2{data[3:0]}}, data};
+    assign flag = data == wide;
+    assign out = flag ? data : 8'h00;
+    assign out = mem[0];
+    assign sized = 255;
+    assign out = data << 2;
+
+    // these don't
+    assign out = wide;
+    assign out = data[3:0];
+    assign doubled = data * 2 + sized;
+    assign sized = 256;
+    assign out = {data, 1'b0};
+    assign out = mem[1][3:0];
+    assign flag = data;
+    assign out = WIDTH > 4 ? wide : data;
+
+    always @(data) begin
+        count = 4'd15;
+        count <= data;
+        for (i = 0; i < 4; i = i + 1)
+            mem[i] = i;
+    end
+
+    narrow fits(.a(data), .b(count), .y(out), .z(flag));
+    narrow positional(wide, data[3:0], sized, flag);
+    narrow outputs(.a(8'd0), .b(4'd0), .y(doubled), .z(doubled[0]));
+    narrow overridden(.a(wide), .b(data), .y(out), .z(flag));
+    defparam overridden.WIDTH = 16;
+    narrow unrelated(.a(wide), .b(count), .y(out), .z(flag));
+    defparam unrelated.DEPTH = 4;
+endmodule
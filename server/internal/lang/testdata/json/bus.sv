@@ -0,0 +1,20 @@
+package types;
+    typedef enum logic [1:0] {
+        IDLE, // waiting
+        BUSY = 2'd1
+    } state_t;
+endpackage
+
+/* a bus between
+   two modules */
+interface bus(input logic clk);
+    logic valid;
+    modport source(output valid);
+endinterface
+
+module top(bus.source b);
+    import types::*;
+    state_t state;
+    sub s(.clk(b.clk), .valid());
+    initial $display("%d", state);
+endmodule
@@ -0,0 +1,11 @@
+`define WIDTH 4
+
+// counts up on every clock
+module counter(
+    input clk, // the clock
+    output reg [`WIDTH-1:0] count
+);
+    always @(posedge clk) begin
+        count <= count + 1;
+    end
+endmodule
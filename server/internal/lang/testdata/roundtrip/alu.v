@@ -0,0 +1,31 @@
+`define OP_ADD 3'b000
+`define MAX(a, b) ((a) > (b) ? (a) : (b))
+
+module alu(a, b, op, result, zero);
+    input [31:0] a, b;
+    input [2:0] op;
+    output reg [31:0] result;
+    output zero;
+
+    wire [31:0] sa = $signed(a);
+    wire [63:0] wide = {{32{a[31]}}, a};
+
+    assign zero = result == 0;
+
+    always @(a or b or op)
+        case (op)
+            `OP_ADD: result = a + b;
+            3'b001: result = a - b;
+            3'b010, 3'b011: result = a & b;
+            3'b100: result = sa < $signed(b) ? 32'd1 : 32'd0;
+            3'b101: result = {a[15:0], b[15:0]};
+            3'b110: result = -a;
+            default: result = {32{1'b0}};
+        endcase
+
+    always @(negedge b)
+        casez (op)
+            3'b1??: $display("high op %d", op);
+            default: $display("low op");
+        endcase
+endmodule
@@ -0,0 +1,42 @@
+package bus_pkg;
+    parameter int ADDR_WIDTH = 32;
+
+    // the kinds of transfer
+    typedef enum logic [1:0] {
+        READ, // a read
+        WRITE,
+        IDLE = 2'b11
+    } op_t;
+
+    typedef struct packed {
+        logic [ADDR_WIDTH-1:0] addr;
+        logic [31:0] data; // write data
+        op_t op;
+    } request_t;
+endpackage
+
+interface bus_if(input logic clk);
+    import bus_pkg::*;
+    request_t req;
+    logic valid, ready;
+
+    modport master(output req, output valid, input ready);
+    modport slave(input req, valid, output ready);
+endinterface : bus_if
+
+module arbiter import bus_pkg::*; (
+    bus_if.slave left,
+    bus_if.slave right,
+    bus_if.master out,
+    input logic clk
+);
+    logic [1:0] grant;
+    bus_pkg::op_t last_op;
+
+    always_ff @(posedge clk) begin
+        for (int i = 0; i < 2; i++) begin
+            grant[i] <= (i == 0 ? left.valid : right.valid) && !grant[1 - i];
+        end
+        last_op <= bus_pkg::IDLE;
+    end
+endmodule
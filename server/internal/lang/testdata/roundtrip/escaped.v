@@ -0,0 +1,5 @@
+module \top.level (input \in[0] , output \out$ );
+    wire \net/1 ;
+    assign \net/1 = \in[0] ;
+    assign \out$ = ~\net/1 ;
+endmodule
@@ -0,0 +1,41 @@
+`timescale 1ns / 1ps
+`default_nettype none
+
+// synchronous fifo with a power of two depth
+module fifo(
+    input wire clk,
+    input wire rst,
+    input wire push, // write enable
+    input wire pop,
+    input wire [7:0] din,
+    output reg [7:0] dout,
+    output wire empty,
+    output wire full
+);
+    parameter DEPTH_LOG = 4;
+    localparam DEPTH = 1 << DEPTH_LOG;
+
+    reg [7:0] mem [0:DEPTH-1];
+    reg [DEPTH_LOG:0] rd_ptr, wr_ptr;
+
+    assign empty = rd_ptr == wr_ptr;
+    assign full = (rd_ptr[DEPTH_LOG-1:0] == wr_ptr[DEPTH_LOG-1:0]) && (rd_ptr[DEPTH_LOG] != wr_ptr[DEPTH_LOG]);
+
+    always @(posedge clk or posedge rst) begin
+        if (rst) begin
+            rd_ptr <= 0;
+            wr_ptr <= 0;
+        end else begin
+            if (push && !full) begin
+                mem[wr_ptr[DEPTH_LOG-1:0]] <= din;
+                wr_ptr <= wr_ptr + 1;
+            end
+            if (pop && !empty) begin
+                dout <= mem[rd_ptr[DEPTH_LOG-1:0]];
+                rd_ptr <= rd_ptr + 1;
+            end
+        end
+    end
+endmodule
+
+`default_nettype wire
@@ -0,0 +1,54 @@
+module ripple(input [3:0] a, input [3:0] b, input cin, output [3:0] sum, output cout);
+    wire [4:0] carry;
+    assign carry[0] = cin;
+
+    genvar i;
+    generate
+        for (i = 0; i < 4; i = i + 1) begin : stage
+            full_adder fa(.a(a[i]), .b(b[i]), .cin(carry[i]), .sum(sum[i]), .cout(carry[i + 1]));
+        end
+    endgenerate
+
+    assign cout = carry[4];
+endmodule
+
+module full_adder(a, b, cin, sum, cout);
+    input a, b, cin;
+    output sum, cout;
+    wire ab, bc, ac;
+
+    xor x1(sum, a, b, cin);
+    and a1(ab, a, b);
+    and a2(bc, b, cin);
+    and a3(ac, a, cin);
+    or o1(cout, ab, bc, ac);
+endmodule
+
+module tb;
+    reg [3:0] a, b;
+    reg cin;
+    wire [3:0] sum;
+    wire cout;
+    integer k;
+
+    ripple dut(a, b, cin, sum, cout);
+    defparam dut.stage.WIDTH = 4;
+
+    task check;
+        if (sum != a + b + cin)
+            $display("mismatch %d + %d", a, b);
+    endtask
+
+    initial begin
+        a = 0;
+        b = 0;
+        cin = 0;
+        for (k = 0; k < 16; k = k + 1) begin
+            #10 a = k;
+            b = 15 - k;
+        end
+        $finish;
+    end
+
+    always #5 cin = ~cin;
+endmodule
@@ -0,0 +1,116 @@
+package lang
+
+import "strings"
+
+// Trivia is the comments around a node, which usually document it
+type Trivia struct {
+	Leading  []Token // comments on the lines right above the node, or before it on its first line
+	Trailing []Token // comment after the node on its last line
+}
+
+// returns whether the token is one that the parser skips
+func (p *Parser) isTrivia(token Token) bool {
+	for _, kind := range p.skipTokens {
+		if token.Type == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// groups comment tokens into whole comments, since block
+// comments are lexed as a token per line
+func groupComments(tokens []Token) [][]Token {
+	groups := [][]Token{}
+	for i := 0; i < len(tokens); i++ {
+		if tokens[i].Type != COMMENT {
+			continue
+		}
+		group := commentAt(tokens, i)
+		groups = append(groups, group)
+		i += len(group) - 1
+	}
+	return groups
+}
+
+// returns the tokens of the whole comment that starts at i
+func commentAt(tokens []Token, i int) []Token {
+	end := i
+	if tokens[i].Value == "/*" {
+		for end < len(tokens)-1 && tokens[end].Value != "*/" {
+			end++
+		}
+	}
+	return tokens[i : end+1]
+}
+
+// comments returns the trivia of the node made of the tokens from start up to end,
+// where start and end can include surrounding whitespace
+func (p *Parser) comments(tokens []Token, start int, end int) (result Trivia) {
+	start, end, ok := p.bounds(tokens, start, end)
+	if !ok {
+		return
+	}
+	result.Leading = p.leadingComments(tokens, start)
+
+	// a trailing comment is on the same line as the end of the node,
+	// possibly after the comma or semicolon that separates it from the next one
+	i := end + 1
+	for i < len(tokens) && tokens[i].Line() == tokens[end].Line() &&
+		(tokens[i].Type == WHITESPACE || tokens[i].Type == COMMA || tokens[i].Type == SEMICOLON) {
+		i++
+	}
+	if i < len(tokens) && tokens[i].Type == COMMENT && tokens[i].Line() == tokens[end].Line() {
+		result.Trailing = commentAt(tokens, i)
+	}
+	return
+}
+
+// leadingComments returns the comments above the node that starts with the token at start,
+// up to a blank line, except for ones that trail whatever came before the node
+func (p *Parser) leadingComments(tokens []Token, start int) (leading []Token) {
+	first := start
+	for first > 0 && p.isTrivia(tokens[first-1]) {
+		first--
+	}
+	previousLine := -1
+	if first > 0 {
+		previousLine = tokens[first-1].Line()
+	}
+	groups := groupComments(tokens[first:start])
+	line := tokens[start].Line()
+	for i := len(groups) - 1; i >= 0; i-- {
+		group := groups[i]
+		if group[0].Line() == previousLine || group[len(group)-1].Line() < line-1 {
+			break
+		}
+		leading = append(append([]Token{}, group...), leading...)
+		line = group[0].Line()
+	}
+	return
+}
+
+// Doc returns the text of the comments, without the comment markers.
+// Leading comments are preferred over trailing ones
+func (t Trivia) Doc() string {
+	comments := t.Leading
+	if len(comments) == 0 {
+		comments = t.Trailing
+	}
+	lines := []string{}
+	for _, comment := range comments {
+		text := comment.Value
+		switch {
+		case text == "/*" || text == "*/":
+			continue
+		case strings.HasPrefix(text, "//"):
+			text = strings.TrimLeft(text, "/")
+		default:
+			// lines of block comments often start with a *
+			text = strings.TrimSpace(text)
+			text = strings.TrimPrefix(text, "*")
+		}
+		lines = append(lines, strings.TrimSpace(text))
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
@@ -1,109 +1,526 @@
 package lang
 
 import (
-	"errors"
-	"regexp"
+	"strings"
+	"unicode/utf8"
 
+	"go.lsp.dev/protocol"
 	"go.uber.org/zap"
 )
 
+// VLexer is a hand-written, single-pass lexer for Verilog and SystemVerilog.
+// It produces the same tokens as a Lexer whose mappings are the grammar
+// of each token, but only looks at each character once
 type VLexer struct {
-	Lexer
+	logger   *zap.Logger
+	language Language
 }
 
+// NewVLexer makes a lexer for Verilog
 func NewVLexer(logger *zap.Logger) *VLexer {
-	vlexer := &VLexer{
-		Lexer: *NewLexer(logger),
-	}
-
-	// add mappings
-	// whitespace
-	vlexer.AddMappingNoCapture(regexp.MustCompile(`^[\t ]+`), "whitespace")
-	vlexer.AddMappingNoCapture(regexp.MustCompile(`^[\r\n]+`), "newline")
-	// comments
-	vlexer.AddMappingNoCapture(regexp.MustCompile(`^\/\/.*`), "comment")
-	vlexer.AddMapping(regexp.MustCompile(`^\/\*(.*?\n?)*?\*\/`), func(code string) ([]Token, error) {
-		re := regexp.MustCompile(`(?P<COMMENT>.*\n?)`)
-		matches := re.FindAllStringSubmatch(code[2:len(code)-2], -1) // remove /* and */
-		tokens := []Token{}
-		tokens = append(tokens, Token{Type: "comment", Value: `/*`}) // add the first token
-		// add all the comments
-		for _, match := range matches {
-			if len(match[1]) > 0 {
-				tokens = append(tokens, Token{Type: "comment", Value: match[re.SubexpIndex("COMMENT")]})
-			}
+	return newVLexer(logger, Verilog)
+}
+
+// NewSVLexer makes a lexer for the SystemVerilog design subset,
+// which is a superset of Verilog
+func NewSVLexer(logger *zap.Logger) *VLexer {
+	return newVLexer(logger, SystemVerilog)
+}
+
+func newVLexer(logger *zap.Logger, language Language) *VLexer {
+	return &VLexer{
+		logger:   logger,
+		language: language,
+	}
+}
+
+// Lex the code, returning a list of tokens found in the code,
+// or an error if something went wrong. Characters that can't start
+// a token become ILLEGAL tokens, and lexing continues after them
+func (vlexer *VLexer) Lex(code string) ([]Token, error) {
+	s := scanner{
+		code:     code,
+		language: vlexer.language,
+		// even dense code like netlists averages about 2 bytes a token with the
+		// whitespace between them, and growing would copy every token found so far,
+		// so guessing a little high is cheaper than guessing low
+		tokens: make([]Token, 0, len(code)/2+1),
+	}
+	for s.pos < len(code) {
+		if !s.scan() {
+			s.scanIllegal()
 		}
-		tokens = append(tokens, Token{Type: "comment", Value: `*/`}) // add the last token
+	}
+	return s.tokens, nil
+}
 
-		return tokens, nil
-	})
-	// keywords
-	vlexer.AddMappingNoCapture(regexp.MustCompile(`^module`), "module")
-	vlexer.AddMappingNoCapture(regexp.MustCompile(`^endmodule`), "endmodule")
-	vlexer.AddMappingNoCapture(regexp.MustCompile(`^begin`), "begin")
-	vlexer.AddMappingNoCapture(regexp.MustCompile(`^end`), "end")
-	vlexer.AddMappingNoCapture(regexp.MustCompile(`^case`), "case")
-	vlexer.AddMappingNoCapture(regexp.MustCompile(`^endcase`), "endcase")
-	vlexer.AddMappingNoCapture(regexp.MustCompile(`^generate`), "generate")
-	vlexer.AddMappingNoCapture(regexp.MustCompile(`^endgenerate`), "endgenerate")
-	vlexer.AddMappingNoCapture(regexp.MustCompile(`^for`), "for")
-	vlexer.AddMappingNoCapture(regexp.MustCompile(`^if`), "if")
-	vlexer.AddMappingNoCapture(regexp.MustCompile(`^else`), "else")
-	vlexer.AddMappingNoCapture(regexp.MustCompile(`^assign`), "assign")
-	vlexer.AddMappingNoCapture(regexp.MustCompile(`^initial`), "initial")
-	vlexer.AddMappingNoCapture(regexp.MustCompile(`^always`), "always")
-	vlexer.AddMappingNoCapture(regexp.MustCompile(`^((negedge)|(posedge))`), "time")
-	vlexer.AddMappingNoCapture(regexp.MustCompile(`^default`), "default")
-	vlexer.AddMappingNoCapture(regexp.MustCompile(`^task`), "task")
-	vlexer.AddMappingNoCapture(regexp.MustCompile(`^endtask`), "endtask")
-	// comparisons/assignments
-	vlexer.AddMappingNoCapture(regexp.MustCompile(`^((\=\=\=)|(\!\=\=)|(\=\=)|(\!\=)|(\<\=)|(>\=)|\>|\<)`), "comparator")
-	vlexer.AddMappingNoCapture(regexp.MustCompile(`^((\&\&)|(\|\|)|[\+\-\*\/\|&]|(\<\<)|(\>\>))`), "operator") // binary operators
-	vlexer.AddMappingNoCapture(regexp.MustCompile(`^\~`), "tilde")                                             // the only unary operator
-	// symbols
-	vlexer.AddMappingNoCapture(regexp.MustCompile(`^\(`), "lparen")
-	vlexer.AddMappingNoCapture(regexp.MustCompile(`^\)`), "rparen")
-	vlexer.AddMappingNoCapture(regexp.MustCompile(`^\[`), "lbracket")
-	vlexer.AddMappingNoCapture(regexp.MustCompile(`^\]`), "rbracket")
-	vlexer.AddMappingNoCapture(regexp.MustCompile(`^\{`), "lcurl")
-	vlexer.AddMappingNoCapture(regexp.MustCompile(`^\}`), "rcurl")
-	vlexer.AddMappingNoCapture(regexp.MustCompile(`^:`), "colon")
-	vlexer.AddMappingNoCapture(regexp.MustCompile(`^\,`), "comma")
-	vlexer.AddMappingNoCapture(regexp.MustCompile(`^\.`), "dot")
-	vlexer.AddMappingNoCapture(regexp.MustCompile(`^\;`), "semicolon")
-	vlexer.AddMappingNoCapture(regexp.MustCompile(`^\?`), "question")
-	vlexer.AddMappingNoCapture(regexp.MustCompile(`^\@`), "at")
-	vlexer.AddMappingNoCapture(regexp.MustCompile(`^\=`), "equal")
-	vlexer.AddMappingNoCapture(regexp.MustCompile(`^\#`), "pound")
-	vlexer.AddMappingNoCapture(regexp.MustCompile(`^\$`), "dollar")
-	// other
-	vlexer.AddMappingNoCapture(regexp.MustCompile("^`include"), "include")
-	vlexer.AddMappingNoCapture(regexp.MustCompile("^`define"), "define")
-	vlexer.AddMappingNoCapture(regexp.MustCompile("^`timescale"), "timescale")
-	// functions that return values (count them as their own type)
-	vlexer.AddMappingNoCapture(regexp.MustCompile(`^((\$time)|(\$realtime))`), "funcliteral")
-	vlexer.AddMappingNoCapture(regexp.MustCompile(`^((\$signed)|(\$unsigned))`), "signed")
-	// variable-related
-	vlexer.AddMappingNoCapture(regexp.MustCompile(`^((reg)|(wire)|(genvar)|(parameter)|(integer))`), "type")
-	vlexer.AddMappingNoCapture(regexp.MustCompile(`^((input)|(output)|(inout))`), "direction")
-	vlexer.AddMappingNoCapture(regexp.MustCompile(`^defparam`), "defparam")
-	vlexer.AddMapping(regexp.MustCompile("^`?[A-Za-z][a-zA-Z0-9_]*"), func(code string) ([]Token, error) {
-		re := regexp.MustCompile("^(?P<IDENTIFIER>`?[A-Za-z][a-zA-Z0-9_]*)")
-		matches := re.FindStringSubmatch(code)
-		if len(matches) == 0 {
-			vlexer.logger.Sugar().Error("failed to parse identifier on ", code)
-			return []Token{}, errors.New("failed to parse identifier")
-		}
-		return []Token{{Type: "identifier", Value: matches[re.SubexpIndex("IDENTIFIER")]}}, nil
-	})
-	vlexer.AddMapping(regexp.MustCompile(`^(([0-9]*\'[hbd][0-9xzXZA-Fa-f]+)|([0-9]+)|(\"[^\n\"]*\"))`), func(code string) ([]Token, error) {
-		re := regexp.MustCompile(`^(?P<LITERAL>(([0-9]*\'[hbd][0-9xzXZA-Fa-f]+)|([0-9]+)|(\"[^\n\"]*\")))`)
-		matches := re.FindStringSubmatch(code)
-		if len(matches) == 0 {
-			return []Token{}, errors.New("failed to parse literal" + code)
+// lexRange lexes the code from start up to end, where start is the beginning
+// of a token at the given line and character. Tokens are scanned with the rest
+// of the code after them, so they're the same as the ones Lex would find.
+// Also returns the line and character of end, and false if a token runs past it
+func (vlexer *VLexer) lexRange(code string, start int, end int, line int, character int) (tokens []Token, endLine int, endCharacter int, ok bool) {
+	s := scanner{
+		code:      code,
+		pos:       start,
+		line:      line,
+		character: character,
+		language:  vlexer.language,
+		tokens:    make([]Token, 0, (end-start)/2+1),
+	}
+	for s.pos < end {
+		if !s.scan() {
+			s.scanIllegal()
 		}
-		return []Token{{Type: "literal", Value: matches[re.SubexpIndex("LITERAL")]}}, nil
+	}
+	return s.tokens, s.line, s.character, s.pos == end
+}
+
+// scanner holds the state of a single call to Lex
+type scanner struct {
+	code      string
+	pos       int
+	line      int
+	character int // in UTF-16 code units, see Token.StartCharacter
+	language  Language
+	tokens    []Token
+}
+
+// emit adds a token made of the next length characters
+func (s *scanner) emit(kind TokenKind, length int) {
+	value := s.code[s.pos : s.pos+length]
+	width := UTF16Len(value)
+	s.tokens = append(s.tokens, Token{
+		Type:           kind,
+		Value:          value,
+		offset:         int32(s.pos),
+		line:           int32(s.line),
+		startCharacter: int32(s.character),
+		endCharacter:   int32(s.character + width),
 	})
+	s.pos += length
+	s.character += width
+	if newline := strings.LastIndexByte(value, '\n'); newline >= 0 {
+		s.line += strings.Count(value, "\n")
+		s.character = UTF16Len(value[newline+1:])
+	}
+}
+
+// peek returns the character offset characters ahead, or 0 past the end
+func (s *scanner) peek(offset int) byte {
+	if s.pos+offset < len(s.code) {
+		return s.code[s.pos+offset]
+	}
+	return 0
+}
+
+// span returns how many characters starting offset characters ahead are in the set
+func (s *scanner) span(offset int, in func(byte) bool) int {
+	i := s.pos + offset
+	for i < len(s.code) && in(s.code[i]) {
+		i++
+	}
+	return i - s.pos - offset
+}
+
+// scan emits the token(s) at the current position, returning
+// false if no token starts there
+func (s *scanner) scan() bool {
+	c := s.code[s.pos]
+	switch {
+	case c == ' ' || c == '\t':
+		s.emit(WHITESPACE, s.span(0, isBlank))
+	case c == '\r' || c == '\n':
+		s.emit(NEWLINE, s.span(0, func(c byte) bool { return c == '\r' || c == '\n' }))
+	case c == '/':
+		s.scanSlash()
+	case isIdentifierStart(c):
+		length := s.span(1, isIdentifierChar) + 1
+		s.emit(KeywordKind(s.code[s.pos:s.pos+length], s.language), length)
+	case isDigit(c):
+		s.emit(LITERAL, s.numberLength())
+	case c == '\'':
+		if length := s.numberLength(); length > 0 {
+			s.emit(LITERAL, length)
+		} else if s.language == SystemVerilog && s.peek(1) != 0 && strings.IndexByte("01xXzZ", s.peek(1)) >= 0 {
+			// unbased unsized fills, ie '0 and '1
+			s.emit(LITERAL, 2)
+		} else {
+			return false
+		}
+	case c == '"':
+		return s.scanString()
+	case c == '`':
+		return s.scanDirective()
+	case c == '$':
+		s.scanDollar()
+	case c == '\\':
+		// escaped identifiers are any printable characters up until whitespace, ie \bus[3]
+		length := s.span(1, func(c byte) bool { return c >= '!' && c <= '~' })
+		if length == 0 {
+			return false
+		}
+		s.emit(IDENTIFIER, length+1)
+	default:
+		return s.scanOperator()
+	}
+	return true
+}
+
+// scanSlash scans comments and the division operator
+func (s *scanner) scanSlash() {
+	switch s.peek(1) {
+	case '/':
+		length := strings.IndexByte(s.code[s.pos:], '\n')
+		if length < 0 {
+			length = len(s.code) - s.pos
+		}
+		s.emit(COMMENT, length)
+	case '*':
+		// block comments are split into /*, each of their lines, and */
+		end := strings.Index(s.code[s.pos+2:], "*/")
+		if end < 0 {
+			// an unterminated comment is an error, but still comments out the rest of the file
+			s.emit(ILLEGAL, 2)
+			s.emitCommentLines(len(s.code) - s.pos)
+			return
+		}
+		s.emit(COMMENT, 2)
+		s.emitCommentLines(end)
+		s.emit(COMMENT, 2)
+	default:
+		s.emit(OPERATOR, 1)
+	}
+}
+
+// emitCommentLines emits the next length characters as a comment token per line
+func (s *scanner) emitCommentLines(length int) {
+	for length > 0 {
+		line := strings.IndexByte(s.code[s.pos:s.pos+length], '\n') + 1
+		if line == 0 {
+			line = length
+		}
+		s.emit(COMMENT, line)
+		length -= line
+	}
+}
+
+// scanIllegal emits an error token for characters that can't start a token
+func (s *scanner) scanIllegal() {
+	c := s.code[s.pos]
+	switch {
+	case c >= utf8.RuneSelf:
+		// a run of non-ASCII characters is a single error
+		s.emit(ILLEGAL, s.span(0, func(c byte) bool { return c >= utf8.RuneSelf }))
+	case c == '"':
+		// unterminated strings run until the end of their line
+		length := strings.IndexAny(s.code[s.pos:], "\r\n")
+		if length < 0 {
+			length = len(s.code) - s.pos
+		}
+		s.emit(ILLEGAL, length)
+	default:
+		s.emit(ILLEGAL, 1)
+	}
+}
+
+// LexDiagnostics returns an error diagnostic for each ILLEGAL token
+func LexDiagnostics(tokens []Token) []protocol.Diagnostic {
+	diagnostics := []protocol.Diagnostic{}
+	for _, token := range tokens {
+		if token.Type != ILLEGAL {
+			continue
+		}
+		var message string
+		switch {
+		case token.Value == "/*":
+			message = "Unterminated block comment"
+		case token.Value[0] == '"':
+			message = "Unterminated string: " + token.Value
+		case token.Value == `\`:
+			message = "Stray backslash, escaped identifiers need at least one character"
+		case token.Value[0] >= utf8.RuneSelf:
+			message = "Non-ASCII characters are only allowed in strings and comments: " + token.Value
+		default:
+			message = "Unexpected character: " + token.Value
+		}
+		diagnostics = append(diagnostics, protocol.Diagnostic{
+			Range: protocol.Range{
+				Start: protocol.Position{Line: uint32(token.line), Character: uint32(token.startCharacter)},
+				End:   protocol.Position{Line: uint32(token.line), Character: uint32(token.endCharacter)},
+			},
+			Severity: protocol.DiagnosticSeverityError,
+			Message:  message,
+		})
+	}
+	return diagnostics
+}
+
+// scanOperator scans operators and punctuation
+func (s *scanner) scanOperator() bool {
+	c, next := s.code[s.pos], s.peek(1)
+	switch c {
+	case '+', '-':
+		if s.language == SystemVerilog && next == c {
+			s.emit(INCREMENT, 2)
+		} else {
+			s.emit(OPERATOR, 1)
+		}
+	case '&', '|':
+		if next == c {
+			s.emit(OPERATOR, 2)
+		} else {
+			s.emit(OPERATOR, 1)
+		}
+	case '*', '^', '%':
+		s.emit(OPERATOR, 1)
+	case '<', '>':
+		if next == c {
+			s.emit(OPERATOR, 2)
+		} else if next == '=' {
+			s.emit(COMPARATOR, 2)
+		} else {
+			s.emit(COMPARATOR, 1)
+		}
+	case '=':
+		if next == '=' && s.peek(2) == '=' {
+			s.emit(COMPARATOR, 3)
+		} else if next == '=' {
+			s.emit(COMPARATOR, 2)
+		} else {
+			s.emit(EQUAL, 1)
+		}
+	case '!':
+		if next != '=' {
+			// logical negation
+			s.emit(TILDE, 1)
+		} else if s.peek(2) == '=' {
+			s.emit(COMPARATOR, 3)
+		} else {
+			s.emit(COMPARATOR, 2)
+		}
+	case ':':
+		if s.language == SystemVerilog && next == ':' {
+			s.emit(SCOPE, 2)
+		} else {
+			s.emit(COLON, 1)
+		}
+	default:
+		kind, ok := punctuation[c]
+		if !ok {
+			return false
+		}
+		s.emit(kind, 1)
+	}
+	return true
+}
+
+// the punctuation that is always a single character
+var punctuation = map[byte]TokenKind{
+	'~': TILDE, // the unary operators are ~ and !
+	'(': LPAREN,
+	')': RPAREN,
+	'[': LBRACKET,
+	']': RBRACKET,
+	'{': LCURL,
+	'}': RCURL,
+	',': COMMA,
+	'.': DOT,
+	';': SEMICOLON,
+	'?': QUESTION,
+	'@': AT,
+	'#': POUND,
+}
+
+// the kinds of the directives that have their own kind
+var directiveKinds = map[string]TokenKind{
+	"include":         INCLUDE,
+	"define":          DEFINE,
+	"timescale":       TIMESCALE,
+	"undef":           UNDEF,
+	"default_nettype": DEFAULT_NETTYPE,
+	// the rest of the directives, which only span the rest of their line
+	"resetall":            DIRECTIVE,
+	"celldefine":          DIRECTIVE,
+	"endcelldefine":       DIRECTIVE,
+	"line":                DIRECTIVE,
+	"pragma":              DIRECTIVE,
+	"ifdef":               DIRECTIVE,
+	"ifndef":              DIRECTIVE,
+	"elsif":               DIRECTIVE,
+	"else":                DIRECTIVE,
+	"endif":               DIRECTIVE,
+	"unconnected_drive":   DIRECTIVE,
+	"nounconnected_drive": DIRECTIVE,
+	"begin_keywords":      DIRECTIVE,
+	"end_keywords":        DIRECTIVE,
+}
+
+// scanDirective scans directives and macro usages, ie `define and `WIDTH
+func (s *scanner) scanDirective() bool {
+	if !isIdentifierStart(s.peek(1)) {
+		return false
+	}
+	length := s.span(2, isIdentifierChar) + 2
+	if kind, ok := directiveKinds[s.code[s.pos+1:s.pos+length]]; ok {
+		s.emit(kind, length)
+	} else {
+		s.emit(IDENTIFIER, length)
+	}
+	return true
+}
+
+// scanDollar scans the system functions that have their own kind,
+// or just the dollar sign of the rest of the system tasks and functions
+func (s *scanner) scanDollar() {
+	rest := s.code[s.pos:]
+	switch {
+	case strings.HasPrefix(rest, "$time"):
+		s.emit(FUNCLITERAL, len("$time"))
+	case strings.HasPrefix(rest, "$realtime"):
+		s.emit(FUNCLITERAL, len("$realtime"))
+	case strings.HasPrefix(rest, "$signed"):
+		s.emit(SIGNED, len("$signed"))
+	case strings.HasPrefix(rest, "$unsigned"):
+		s.emit(SIGNED, len("$unsigned"))
+	default:
+		s.emit(DOLLAR, 1)
+	}
+}
+
+// scanString scans a string literal, see string.go
+func (s *scanner) scanString() bool {
+	for i := s.pos + 1; i < len(s.code); i++ {
+		switch s.code[i] {
+		case '"':
+			s.emit(LITERAL, i+1-s.pos)
+			return true
+		case '\\':
+			// a backslash escapes the next character, unless that's a newline
+			if i+1 >= len(s.code) || s.code[i+1] == '\n' {
+				return false
+			}
+			i++
+		case '\n':
+			return false
+		}
+	}
+	return false
+}
+
+// numberLength returns the length of the number at the current position,
+// or 0 if there isn't one. Like the number grammar in number.go, based
+// numbers are tried first, then reals, then unsigned numbers
+func (s *scanner) numberLength() int {
+	// an optional size followed by a base
+	size := 0
+	if c := s.peek(0); isDigit(c) && c != '0' {
+		size = s.span(1, isDecimalDigit) + 1
+		size += s.span(size, isBlank)
+	}
+	if length := s.basedLength(size); length > 0 {
+		return size + length
+	}
+	if !isDigit(s.peek(0)) {
+		return 0
+	}
+
+	// reals, which need a fraction, an exponent, or both
+	integer := s.span(1, isDecimalDigit) + 1
+	fraction := s.fractionLength(integer)
+	if exponent := s.exponentLength(integer + fraction); exponent > 0 {
+		return integer + fraction + exponent
+	}
+	if fraction > 0 {
+		return integer + fraction
+	}
+	return integer
+}
+
+// basedLength returns the length of the base and digits of a based number,
+// ie 'hFF or 'sd12, that starts offset characters ahead, or 0 if there isn't one
+func (s *scanner) basedLength(offset int) int {
+	if s.peek(offset) != '\'' {
+		return 0
+	}
+	i := offset + 1
+	if c := s.peek(i); c == 's' || c == 'S' {
+		i++
+	}
+	var digit func(byte) bool
+	base := s.peek(i)
+	switch base {
+	case 'd', 'D':
+		digit = isDecimalDigit
+	case 'b', 'B':
+		digit = func(c byte) bool { return c == '0' || c == '1' || c == '_' || isUnknownDigit(c) }
+	case 'o', 'O':
+		digit = func(c byte) bool { return c >= '0' && c <= '7' || c == '_' || isUnknownDigit(c) }
+	case 'h', 'H':
+		digit = func(c byte) bool {
+			return isDecimalDigit(c) || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F' || isUnknownDigit(c)
+		}
+	default:
+		return 0
+	}
+	i++
+	i += s.span(i, isBlank)
+
+	// decimals are either all digits or a single x or z digit
+	first := s.peek(i)
+	if (base == 'd' || base == 'D') && isUnknownDigit(first) {
+		digit = func(c byte) bool { return c == '_' }
+	} else if first == '_' || !digit(first) {
+		return 0
+	}
+	return i + 1 + s.span(i+1, digit) - offset
+}
+
+// fractionLength returns the length of the fraction of a real, ie .25,
+// that starts offset characters ahead, or 0 if there isn't one
+func (s *scanner) fractionLength(offset int) int {
+	if s.peek(offset) != '.' || !isDigit(s.peek(offset+1)) {
+		return 0
+	}
+	return s.span(offset+2, isDecimalDigit) + 2
+}
+
+// exponentLength returns the length of the exponent of a real, ie e-3,
+// that starts offset characters ahead, or 0 if there isn't one
+func (s *scanner) exponentLength(offset int) int {
+	if c := s.peek(offset); c != 'e' && c != 'E' {
+		return 0
+	}
+	i := offset + 1
+	if c := s.peek(i); c == '+' || c == '-' {
+		i++
+	}
+	if !isDigit(s.peek(i)) {
+		return 0
+	}
+	return i + 1 + s.span(i+1, isDecimalDigit) - offset
+}
+
+func isBlank(c byte) bool {
+	return c == ' ' || c == '\t'
+}
+
+func isIdentifierStart(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_'
+}
+
+func isIdentifierChar(c byte) bool {
+	return isIdentifierStart(c) || c >= '0' && c <= '9' || c == '$'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// isDecimalDigit also counts underscores, which can separate digits
+func isDecimalDigit(c byte) bool {
+	return c >= '0' && c <= '9' || c == '_'
+}
 
-	return vlexer
+func isUnknownDigit(c byte) bool {
+	return c == 'x' || c == 'X' || c == 'z' || c == 'Z' || c == '?'
 }
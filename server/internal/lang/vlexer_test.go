@@ -0,0 +1,290 @@
+package lang
+
+import (
+	"fmt"
+	"regexp"
+	"runtime"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+// newRegexVLexer makes the regex-based lexer that VLexer replaced,
+// which is the reference for what tokens VLexer should produce
+func newRegexVLexer(language Language) *Lexer {
+	lexer := NewLexer(zap.NewNop())
+
+	// whitespace
+	lexer.AddMappingNoCapture(regexp.MustCompile(`^[\t ]+`), WHITESPACE)
+	lexer.AddMappingNoCapture(regexp.MustCompile(`^[\r\n]+`), NEWLINE)
+	// comments
+	lexer.AddMappingNoCapture(regexp.MustCompile(`^\/\/.*`), COMMENT)
+	lexer.AddMapping(regexp.MustCompile(`^\/\*(.*?\n?)*?\*\/`), func(code string) ([]Token, error) {
+		re := regexp.MustCompile(`(?P<COMMENT>.*\n?)`)
+		matches := re.FindAllStringSubmatch(code[2:len(code)-2], -1) // remove /* and */
+		tokens := []Token{{Type: COMMENT, Value: `/*`}}
+		for _, match := range matches {
+			if len(match[1]) > 0 {
+				tokens = append(tokens, Token{Type: COMMENT, Value: match[re.SubexpIndex("COMMENT")]})
+			}
+		}
+		return append(tokens, Token{Type: COMMENT, Value: `*/`}), nil
+	})
+	// comparisons/assignments
+	if language == SystemVerilog {
+		lexer.AddMappingNoCapture(regexp.MustCompile(`^((\+\+)|(\-\-))`), INCREMENT)
+	}
+	lexer.AddMappingNoCapture(regexp.MustCompile(`^((\=\=\=)|(\!\=\=)|(\=\=)|(\!\=)|(\<\=)|(>\=)|\>|\<)`), COMPARATOR)
+	lexer.AddMappingNoCapture(regexp.MustCompile(`^((\&\&)|(\|\|)|[\+\-\*\/\|&^%]|(\<\<)|(\>\>))`), OPERATOR)
+	lexer.AddMappingNoCapture(regexp.MustCompile(`^[~!]`), TILDE)
+	// symbols
+	lexer.AddMappingNoCapture(regexp.MustCompile(`^\(`), LPAREN)
+	lexer.AddMappingNoCapture(regexp.MustCompile(`^\)`), RPAREN)
+	lexer.AddMappingNoCapture(regexp.MustCompile(`^\[`), LBRACKET)
+	lexer.AddMappingNoCapture(regexp.MustCompile(`^\]`), RBRACKET)
+	lexer.AddMappingNoCapture(regexp.MustCompile(`^\{`), LCURL)
+	lexer.AddMappingNoCapture(regexp.MustCompile(`^\}`), RCURL)
+	if language == SystemVerilog {
+		lexer.AddMappingNoCapture(regexp.MustCompile(`^::`), SCOPE)
+	}
+	lexer.AddMappingNoCapture(regexp.MustCompile(`^:`), COLON)
+	lexer.AddMappingNoCapture(regexp.MustCompile(`^\,`), COMMA)
+	lexer.AddMappingNoCapture(regexp.MustCompile(`^\.`), DOT)
+	lexer.AddMappingNoCapture(regexp.MustCompile(`^\;`), SEMICOLON)
+	lexer.AddMappingNoCapture(regexp.MustCompile(`^\?`), QUESTION)
+	lexer.AddMappingNoCapture(regexp.MustCompile(`^\@`), AT)
+	lexer.AddMappingNoCapture(regexp.MustCompile(`^\=`), EQUAL)
+	lexer.AddMappingNoCapture(regexp.MustCompile(`^\#`), POUND)
+	lexer.AddMappingNoCapture(regexp.MustCompile(`^\$`), DOLLAR)
+	// directives
+	lexer.AddMappingNoCapture(regexp.MustCompile("^`include"), INCLUDE)
+	lexer.AddMappingNoCapture(regexp.MustCompile("^`define"), DEFINE)
+	lexer.AddMappingNoCapture(regexp.MustCompile("^`timescale"), TIMESCALE)
+	lexer.AddMappingNoCapture(regexp.MustCompile("^`undef"), UNDEF)
+	lexer.AddMappingNoCapture(regexp.MustCompile("^`default_nettype"), DEFAULT_NETTYPE)
+	lexer.AddMappingNoCapture(regexp.MustCompile("^`((resetall)|(celldefine)|(endcelldefine)|(line)|(pragma)|(ifdef)|(ifndef)|(elsif)|(else)|(endif)|(unconnected_drive)|(nounconnected_drive)|(begin_keywords)|(end_keywords))"), DIRECTIVE)
+	// system functions
+	lexer.AddMappingNoCapture(regexp.MustCompile(`^((\$time)|(\$realtime))`), FUNCLITERAL)
+	lexer.AddMappingNoCapture(regexp.MustCompile(`^((\$signed)|(\$unsigned))`), SIGNED)
+	// identifiers and keywords
+	lexer.AddMapping(regexp.MustCompile("^`?[A-Za-z_][a-zA-Z0-9_$]*"), func(code string) ([]Token, error) {
+		return []Token{{Type: KeywordKind(code, language), Value: code}}, nil
+	})
+	lexer.AddMappingNoCapture(regexp.MustCompile(`^\\[!-~]+`), IDENTIFIER)
+	// literals
+	lexer.AddMappingNoCapture(regexp.MustCompile(`^(`+numberPattern+`)`), LITERAL)
+	lexer.AddMapping(regexp.MustCompile(`^`+stringPattern), func(code string) ([]Token, error) {
+		return []Token{{Type: LITERAL, Value: code}}, nil
+	})
+	if language == SystemVerilog {
+		lexer.AddMappingNoCapture(regexp.MustCompile(`^\'[01xXzZ]`), LITERAL)
+	}
+
+	return lexer
+}
+
+// generateNetlist makes a flat, generated-looking netlist of about the given number of lines
+func generateNetlist(lines int) string {
+	code := strings.Builder{}
+	code.WriteString("`timescale 1ns / 1ps\n// generated netlist\nmodule netlist (input clk, input rst_n, input [31:0] din, output [31:0] dout);\n")
+	for i := 0; i*4 < lines; i++ {
+		fmt.Fprintf(&code, "  wire [7:0] n%d; /* net %d */\n", i, i)
+		fmt.Fprintf(&code, "  DFFRX1 \\r%d_reg[0] (.D(n%d[0] & ~din[%d]), .CK(clk), .RN(rst_n), .Q(n%d[1]));\n", i, i, i%32, i)
+		fmt.Fprintf(&code, "  assign n%d[7:2] = {n%d[1], 5'b1_0x1z} + 6'sh3F >> 2;\n", i, i)
+		fmt.Fprintf(&code, "  always @(posedge clk) if (n%d === 8'hFF) $display(\"n%d=%%d at %%t\", n%d, $time);\n", i, i, i)
+	}
+	code.WriteString("endmodule\n")
+	return code.String()
+}
+
+var lexerTests = []string{
+	"module top(input a, output reg [3:0] b);\n\tassign b = a ? 4'b10_1x : 4'd12;\nendmodule\n",
+	"// comment\r\n/* block\n * comment\n*/ x /**/ y",
+	"a === b !== c == d != e <= f >= g > h < i << j >> k >>> l && m || n & o | p ~ q",
+	"a+++b---c; x = y ** z / w; a++; b--",
+	"pkg::item; a ? b : c; @(posedge clk or negedge rst_n) #10",
+	"`include \"defs.vh\"\n`define WIDTH 8\n`ifdef X `elsif Y `else `endif `endcelldefine `WIDTH `defines `elsewhere",
+	"$time $timeformat $realtime $signed(a) $unsigned(b) $display $",
+	"\\bus[3] \\cpu.reg  \\ x",
+	"1 12 0 007 1_000 1.5 1e5 1.5e-3 1_000.25E+2 1. 1.e5 1e 1.5e+",
+	"8'hFF 8 'h FF 'b1 'sd12 16'SHdead_beef 4'dx 4'dz_ 4'd? 8'o7_7 8'h 'o9 'd_1 3'b? 12'",
+	"'0 '1 'x 'Z '2",
+	"\"hello\" \"esc \\\" \\n \\101\" \"%d %0h %%\"",
+	"wire w; logic l; always_ff always_comb typedef enum struct packed import package",
+	"x = !a ^ b % c;",
+	// single characters that can't start a token
+	"a ` b ' c \\ d",
+}
+
+func TestLexMatchesRegexLexer(t *testing.T) {
+	inputs := append(lexerTests, generateNetlist(200))
+	for _, language := range []Language{Verilog, SystemVerilog} {
+		reference := newRegexVLexer(language)
+		lexer := newVLexer(zap.NewNop(), language)
+		for _, input := range inputs {
+			want, err := reference.Lex(input)
+			if err != nil {
+				t.Fatalf("regex lexer failed on %q: %v", input, err)
+			}
+			got, err := lexer.Lex(input)
+			if err != nil {
+				t.Fatalf("lexer failed on %q: %v", input, err)
+			}
+			if len(got) != len(want) {
+				t.Errorf("%v: got %d tokens, want %d for %q", language, len(got), len(want), input)
+			}
+			for i := 0; i < len(got) && i < len(want); i++ {
+				if got[i] != want[i] {
+					t.Errorf("%v: token %d of %q is %+v, want %+v", language, i, input, got[i], want[i])
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestLexErrors(t *testing.T) {
+	tests := []struct {
+		input   string
+		illegal []string
+		last    Token // lexing should carry on to the end
+	}{
+		{"wire \\ a;", []string{`\`}, Token{Type: SEMICOLON, Value: ";"}},
+		{"wire caf\u00e9\u00e8 = 1;", []string{"\u00e9\u00e8"}, Token{Type: SEMICOLON, Value: ";"}},
+		{"assign a = b;\n/* never\nends ;", []string{"/*"}, Token{Type: COMMENT, Value: "ends ;"}},
+		{"$display(\"oops);\r\nx", []string{"\"oops);"}, Token{Type: IDENTIFIER, Value: "x"}},
+		{"a ` b", []string{"`"}, Token{Type: IDENTIFIER, Value: "b"}},
+	}
+	for _, test := range tests {
+		tokens, err := NewVLexer(zap.NewNop()).Lex(test.input)
+		if err != nil {
+			t.Fatalf("lexer failed on %q: %v", test.input, err)
+		}
+		illegal := []string{}
+		for _, token := range tokens {
+			if token.Type == ILLEGAL {
+				illegal = append(illegal, token.Value)
+			}
+		}
+		if fmt.Sprint(illegal) != fmt.Sprint(test.illegal) {
+			t.Errorf("error tokens of %q are %q, want %q", test.input, illegal, test.illegal)
+		}
+		last := tokens[len(tokens)-1]
+		if last.Type != test.last.Type || last.Value != test.last.Value {
+			t.Errorf("last token of %q is %v %q, want %v %q", test.input, last.Type, last.Value, test.last.Type, test.last.Value)
+		}
+		if diagnostics := LexDiagnostics(tokens); len(diagnostics) != len(test.illegal) {
+			t.Errorf("got %d diagnostics for %q, want %d", len(diagnostics), test.input, len(test.illegal))
+		}
+	}
+}
+
+func TestLexPositions(t *testing.T) {
+	code := "// h\u00e9llo \U0001F600\nwire /* \u2713 */ a;"
+	tokens, err := NewVLexer(zap.NewNop()).Lex(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, token := range tokens {
+		if code[token.Offset():token.Offset()+len(token.Value)] != token.Value {
+			t.Errorf("offset %d of %q is wrong", token.Offset(), token.Value)
+		}
+	}
+
+	// the comment is 11 UTF-16 code units long, since the emoji is a surrogate pair
+	comment := tokens[0]
+	if comment.StartCharacter() != 0 || comment.EndCharacter() != 11 {
+		t.Errorf("comment spans characters %d to %d, want 0 to 11", comment.StartCharacter(), comment.EndCharacter())
+	}
+	// and a is 2 bytes further along its line than its character, because of the check mark
+	a := tokens[len(tokens)-2]
+	if a.Value != "a" || a.Line() != 1 || a.StartCharacter() != 13 || a.EndCharacter() != 14 {
+		t.Errorf("a is %q at %d:%d-%d, want 1:13-14", a.Value, a.Line(), a.StartCharacter(), a.EndCharacter())
+	}
+	line := code[strings.IndexByte(code, '\n')+1:]
+	if offset := ByteOffset(line, a.StartCharacter()); line[offset:offset+1] != "a" {
+		t.Errorf("character %d is at byte %d of %q", a.StartCharacter(), offset, line)
+	}
+}
+
+func TestLexAllocations(t *testing.T) {
+	code := generateNetlist(20000)
+	lexer := NewVLexer(zap.NewNop())
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	before := stats.TotalAlloc
+	if _, err := lexer.Lex(code); err != nil {
+		t.Fatal(err)
+	}
+	runtime.ReadMemStats(&stats)
+	// the token slice is about all that lexing allocates, and it shouldn't have to grow
+	if perByte := float64(stats.TotalAlloc-before) / float64(len(code)); perByte > 24 {
+		t.Errorf("lexing allocated %.1f bytes per byte of code, expected at most 24", perByte)
+	}
+}
+
+func BenchmarkLex(b *testing.B) {
+	code := generateNetlist(20000)
+	lexer := NewVLexer(zap.NewNop())
+	b.SetBytes(int64(len(code)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := lexer.Lex(code); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkLexSystemVerilog(b *testing.B) {
+	code := generateNetlist(20000)
+	lexer := NewSVLexer(zap.NewNop())
+	b.SetBytes(int64(len(code)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := lexer.Lex(code); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// the lexer VLexer replaced, for comparison
+func BenchmarkRegexLex(b *testing.B) {
+	code := generateNetlist(20000)
+	lexer := newRegexVLexer(Verilog)
+	b.SetBytes(int64(len(code)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := lexer.Lex(code); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func FuzzLex(f *testing.F) {
+	for _, input := range lexerTests {
+		f.Add(input)
+	}
+	f.Fuzz(func(t *testing.T, code string) {
+		for _, language := range []Language{Verilog, SystemVerilog} {
+			tokens, err := newVLexer(zap.NewNop(), language).Lex(code)
+			if err != nil {
+				t.Fatalf("%v: lexer failed: %v", language, err)
+			}
+			// every byte of the code is in exactly one token
+			offset := 0
+			for _, token := range tokens {
+				if token.Offset() != offset || code[offset:offset+len(token.Value)] != token.Value {
+					t.Fatalf("%v: token %+v isn't at offset %d", language, token, offset)
+				}
+				offset += len(token.Value)
+			}
+			if offset != len(code) {
+				t.Fatalf("%v: tokens end at offset %d of %d", language, offset, len(code))
+			}
+		}
+	})
+}
@@ -0,0 +1,305 @@
+package lang
+
+// Node is any of the ast nodes that ParseFile produces, ie FileNode,
+// ModuleNode, or ExprNode. Nodes are always values, never pointers
+type Node interface{}
+
+// A Visitor's Visit method is called for each node that Walk comes across,
+// along with the path of nodes that contain it, outermost first.
+// If it returns nil, the node's children are skipped, otherwise
+// they are visited with the returned visitor
+type Visitor interface {
+	Visit(node Node, path []Node) Visitor
+}
+
+// Walk traverses the node and everything inside of it in source order.
+// The path is only valid for the duration of the call to Visit,
+// so copy it to keep it around
+func Walk(v Visitor, node Node) {
+	walk(v, node, make([]Node, 0, 16))
+}
+
+func walk(v Visitor, node Node, path []Node) {
+	if v = v.Visit(node, path); v == nil {
+		return
+	}
+	path = append(path, node)
+	for _, child := range children(node) {
+		walk(v, child, path)
+	}
+}
+
+type inspector func(node Node, path []Node) bool
+
+func (f inspector) Visit(node Node, path []Node) Visitor {
+	if f(node, path) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses the node like Walk, calling f on each node.
+// If f returns false, the node's children are skipped
+func Inspect(node Node, f func(node Node, path []Node) bool) {
+	Walk(inspector(f), node)
+}
+
+// children returns the nodes directly inside of the node, in source order
+func children(node Node) []Node {
+	result := []Node{}
+	switch node := node.(type) {
+	case FileNode:
+		for _, statement := range node.Statements {
+			result = append(result, statement)
+		}
+	case TopLevelStatement:
+		if node.Directive != nil {
+			result = append(result, *node.Directive)
+		} else if node.Module != nil {
+			result = append(result, *node.Module)
+		} else if node.Interface != nil {
+			result = append(result, *node.Interface)
+		} else if node.Package != nil {
+			result = append(result, *node.Package)
+		} else if node.Import != nil {
+			result = append(result, *node.Import)
+		}
+	case ModuleNode:
+		for _, importNode := range node.Imports {
+			result = append(result, importNode)
+		}
+		result = append(result, node.PortList)
+		for _, statement := range node.Interior {
+			result = append(result, statement)
+		}
+	case InterfaceNode:
+		result = append(result, node.PortList)
+		for _, statement := range node.Interior {
+			result = append(result, statement)
+		}
+	case PackageNode:
+		for _, statement := range node.Interior {
+			result = append(result, statement)
+		}
+	case PortListNode:
+		for _, port := range node.Declarations {
+			result = append(result, port)
+		}
+	case PortNode:
+		if node.Type != nil {
+			result = append(result, *node.Type)
+		}
+		if node.Interface != nil {
+			result = append(result, *node.Interface)
+		}
+	case ImportNode:
+		for _, item := range node.Items {
+			result = append(result, item)
+		}
+	case InteriorNode:
+		if node.DeclarationNode != nil {
+			result = append(result, *node.DeclarationNode)
+		} else if node.AssignmentNode != nil {
+			result = append(result, *node.AssignmentNode)
+		} else if node.ModuleApplicationNode != nil {
+			result = append(result, *node.ModuleApplicationNode)
+		} else if node.GenerateNode != nil {
+			result = append(result, *node.GenerateNode)
+		} else if node.AlwaysNode != nil {
+			result = append(result, *node.AlwaysNode)
+		} else if node.DefParamNode != nil {
+			result = append(result, *node.DefParamNode)
+		} else if node.InitialNode != nil {
+			result = append(result, *node.InitialNode)
+		} else if node.DirectiveNode != nil {
+			result = append(result, *node.DirectiveNode)
+		} else if node.TaskNode != nil {
+			result = append(result, *node.TaskNode)
+		} else if node.ModportNode != nil {
+			result = append(result, *node.ModportNode)
+		} else if node.ImportNode != nil {
+			result = append(result, *node.ImportNode)
+		} else if node.TypedefNode != nil {
+			result = append(result, *node.TypedefNode)
+		}
+	case TypedefNode:
+		if node.Type != nil {
+			result = append(result, *node.Type)
+		} else if node.Enum != nil {
+			result = append(result, *node.Enum)
+		} else if node.Struct != nil {
+			result = append(result, *node.Struct)
+		}
+	case EnumNode:
+		if node.Type != nil {
+			result = append(result, *node.Type)
+		}
+		for _, member := range node.Members {
+			result = append(result, member)
+		}
+	case EnumMemberNode:
+		if node.Value != nil {
+			result = append(result, *node.Value)
+		}
+	case StructNode:
+		for _, member := range node.Members {
+			result = append(result, member)
+		}
+	case ModportNode:
+		for _, port := range node.Ports {
+			result = append(result, port)
+		}
+	case DirectiveNode:
+		if node.DefineNode != nil {
+			result = append(result, *node.DefineNode)
+		} else if node.UndefNode != nil {
+			result = append(result, *node.UndefNode)
+		} else if node.DefaultNettypeNode != nil {
+			result = append(result, *node.DefaultNettypeNode)
+		}
+	case AssignmentNode:
+		for _, variable := range node.Variables {
+			result = append(result, variable)
+		}
+		if node.Increment == nil {
+			result = append(result, node.Value)
+		}
+	case AssignmentVariableNode:
+		for _, selector := range node.Selectors {
+			result = append(result, selector)
+		}
+	case SelectorNode:
+		if node.IndexNode != nil {
+			result = append(result, *node.IndexNode)
+		} else if node.RangeNode != nil {
+			result = append(result, *node.RangeNode)
+		}
+	case IndexNode:
+		result = append(result, node.Index)
+	case RangeNode:
+		result = append(result, node.From, node.To)
+	case ValueNode:
+		for _, selector := range node.Selectors {
+			result = append(result, selector)
+		}
+	case SizedValueNode:
+		for _, value := range node.Values {
+			result = append(result, value)
+		}
+	case ExprNode:
+		if node.Nested != nil {
+			result = append(result, *node.Nested)
+		} else {
+			result = append(result, node.Value)
+		}
+		if node.Right != nil {
+			result = append(result, *node.Right)
+		}
+		if node.ExprTrue != nil {
+			result = append(result, *node.ExprTrue)
+		}
+		if node.ExprFalse != nil {
+			result = append(result, *node.ExprFalse)
+		}
+	case DeclarationNode:
+		result = append(result, node.Type)
+		for i, variable := range node.Variables {
+			result = append(result, variable)
+			// either every variable has a value or none of them do
+			if i < len(node.Values) {
+				result = append(result, node.Values[i])
+			}
+		}
+	case VariableNode:
+		for _, r := range node.Ranges {
+			result = append(result, r)
+		}
+	case TypeNode:
+		for _, r := range node.Ranges {
+			result = append(result, r)
+		}
+	case ModuleApplicationNode:
+		if node.Range != nil {
+			result = append(result, *node.Range)
+		}
+		for _, argument := range node.Arguments {
+			result = append(result, argument)
+		}
+	case ArgumentNode:
+		result = append(result, node.Value)
+	case GenerateNode:
+		for _, statement := range node.Statements {
+			result = append(result, statement)
+		}
+	case BeginBlockNode:
+		for _, statement := range node.Statements {
+			result = append(result, statement)
+		}
+	case ForBlockNode:
+		if node.InitializerType != nil {
+			result = append(result, *node.InitializerType)
+		}
+		if node.Initializer != nil {
+			result = append(result, *node.Initializer)
+		}
+		if node.Condition != nil {
+			result = append(result, *node.Condition)
+		}
+		if node.Incrementor != nil {
+			result = append(result, *node.Incrementor)
+		}
+		result = append(result, node.Body)
+	case IfBlockNode:
+		result = append(result, node.Expr, node.Body)
+		if node.Else != nil {
+			result = append(result, *node.Else)
+		}
+	case AlwaysNode:
+		for _, time := range node.Times {
+			result = append(result, time)
+		}
+		result = append(result, node.Statement)
+	case AlwaysStatement:
+		if node.DelayNode != nil {
+			result = append(result, *node.DelayNode)
+		} else if node.BeginBlock != nil {
+			result = append(result, *node.BeginBlock)
+		} else if node.ForBlock != nil {
+			result = append(result, *node.ForBlock)
+		} else if node.IfBlock != nil {
+			result = append(result, *node.IfBlock)
+		} else if node.InteriorNode != nil {
+			result = append(result, *node.InteriorNode)
+		} else if node.FunctionNode != nil {
+			result = append(result, *node.FunctionNode)
+		} else if node.CaseNode != nil {
+			result = append(result, *node.CaseNode)
+		}
+	case FunctionNode:
+		for _, expression := range node.Expressions {
+			result = append(result, expression)
+		}
+	case DefParamNode:
+		result = append(result, node.Value)
+	case InitialNode:
+		result = append(result, node.Statement)
+	case CaseBlock:
+		result = append(result, node.Expr)
+		for _, c := range node.Cases {
+			result = append(result, c)
+		}
+		if node.Default != nil {
+			result = append(result, *node.Default)
+		}
+	case CaseNode:
+		for _, condition := range node.Conditions {
+			result = append(result, condition)
+		}
+		result = append(result, node.Statement)
+	case TaskNode:
+		for _, statement := range node.Statements {
+			result = append(result, statement)
+		}
+	}
+	return result
+}
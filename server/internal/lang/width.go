@@ -0,0 +1,713 @@
+package lang
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"strings"
+
+	"go.lsp.dev/protocol"
+)
+
+// Widths follow the sizing rules of IEEE 1364-2005 section 5.4. The operands of arithmetic
+// and bitwise operators are as wide as the widest of them, comparisons and logical operators
+// are a bit, shifts are as wide as what's shifted, and the operands of concatenations,
+// comparisons, and shift amounts are sized on their own. An assignment or a port connection
+// then extends or truncates the expression to the width of what it drives, which is what
+// the warnings point out. Like linters do, expressions made of unsized numbers, ie 0 or
+// a parameter set to 8, fit whatever their value fits in, so that a + 1 is as wide as a
+
+// width is the number of bits of an expression
+type width struct {
+	bits    int
+	unsized bool // whether it's made of unsized numbers only, so bits is the fewest that its value needs
+}
+
+// the widths of the built in types without their ranges. A port
+// whose type is just its direction is a wire, so it's a bit too
+var typeWidths = map[string]int{
+	"wire": 1, "tri": 1, "tri0": 1, "tri1": 1, "wand": 1, "triand": 1, "wor": 1, "trior": 1,
+	"trireg": 1, "uwire": 1, "supply0": 1, "supply1": 1, "reg": 1, "logic": 1, "bit": 1,
+	"input": 1, "output": 1, "inout": 1, "parameter": 1, "localparam": 1,
+	"byte": 8, "shortint": 16, "int": 32, "integer": 32, "longint": 64, "time": 64,
+}
+
+// the precedence of the binary operators, from the loosest to the tightest
+var precedence = map[string]int{
+	"||": 1,
+	"&&": 2,
+	"|":  3,
+	"^":  4,
+	"&":  5,
+	"==": 6, "!=": 6, "===": 6, "!==": 6,
+	"<": 7, "<=": 7, ">": 7, ">=": 7,
+	"<<": 8, ">>": 8,
+	"+": 9, "-": 9,
+	"*": 10, "/": 10, "%": 10,
+}
+
+// the operators whose result is a bit
+var comparisons = map[string]bool{
+	"==": true, "!=": true, "===": true, "!==": true, "<": true, "<=": true, ">": true, ">=": true, "&&": true, "||": true,
+}
+
+// operation is a binary operation of an expression, or just an operand if there's no operator
+type operation struct {
+	operand     ExprNode
+	operator    *Token
+	left, right *operation
+}
+
+// flatten applies operator precedence to the chain of operations that the parser nests
+// to the right, ie a + b * c, returning the chain along with the node that has its ternary,
+// which the parser puts on the chain's last operand, or nil if there isn't one
+func flatten(node ExprNode) (*operation, *ExprNode) {
+	operands := []ExprNode{}
+	operators := []Token{}
+	last := node
+	for {
+		operand := last
+		operand.Combinator, operand.Right, operand.ExprTrue, operand.ExprFalse = nil, nil, nil, nil
+		operands = append(operands, operand)
+		if last.Combinator == nil || last.Right == nil {
+			break
+		}
+		operators = append(operators, *last.Combinator)
+		last = *last.Right
+	}
+	var ternary *ExprNode
+	if last.ExprTrue != nil && last.ExprFalse != nil {
+		ternary = &last
+	}
+
+	// precedence climbing, where operators of the same precedence are left associative
+	pos := 0
+	var climb func(minimum int) *operation
+	climb = func(minimum int) *operation {
+		left := &operation{operand: operands[pos]}
+		for pos < len(operators) && precedence[operators[pos].Value] >= minimum {
+			operator := operators[pos]
+			pos++
+			right := climb(precedence[operator.Value] + 1)
+			left = &operation{operator: &operator, left: left, right: right}
+		}
+		return left
+	}
+	return climb(0), ternary
+}
+
+// bitsFor returns the fewest bits that hold the value, as two's complement if it's negative
+func bitsFor(value int64) int {
+	if value < 0 {
+		return bits.Len64(uint64(^value)) + 1
+	}
+	if value == 0 {
+		return 1
+	}
+	return bits.Len64(uint64(value))
+}
+
+// lookup returns the symbol that the name refers to inside of the scope along with the scope it's declared in
+func lookup(scope *Scope, name string) (*Symbol, *Scope) {
+	for ; scope != nil; scope = scope.Parent {
+		if symbol := scope.Symbol(name); symbol != nil {
+			return symbol, scope
+		}
+	}
+	return nil, nil
+}
+
+// constant evaluates an expression made of numbers and parameters, and returns false if it can't
+func (i *Interpreter) constant(scope *Scope, node ExprNode) (int64, bool) {
+	chain, ternary := flatten(node)
+	value, ok := i.constantOperation(scope, chain)
+	if !ok || ternary == nil {
+		return value, ok
+	}
+	if value != 0 {
+		return i.constant(scope, *ternary.ExprTrue)
+	}
+	return i.constant(scope, *ternary.ExprFalse)
+}
+
+func (i *Interpreter) constantOperation(scope *Scope, op *operation) (int64, bool) {
+	if op.operator == nil {
+		if op.operand.Nested != nil {
+			return i.constant(scope, *op.operand.Nested)
+		}
+		sized := op.operand.Value
+		if sized.Size != nil || len(sized.Elements) > 0 || len(sized.Values) != 1 {
+			return 0, false
+		}
+		return i.constantValue(scope, sized.Values[0])
+	}
+	left, ok := i.constantOperation(scope, op.left)
+	if !ok {
+		return 0, false
+	}
+	right, ok := i.constantOperation(scope, op.right)
+	if !ok {
+		return 0, false
+	}
+	truth := func(b bool) int64 {
+		if b {
+			return 1
+		}
+		return 0
+	}
+	switch op.operator.Value {
+	case "+":
+		return left + right, true
+	case "-":
+		return left - right, true
+	case "*":
+		return left * right, true
+	case "/", "%":
+		if right == 0 {
+			return 0, false
+		}
+		if op.operator.Value == "/" {
+			return left / right, true
+		}
+		return left % right, true
+	case "<<", ">>":
+		if right < 0 || right > 62 {
+			return 0, false
+		}
+		if op.operator.Value == "<<" {
+			return left << right, true
+		}
+		return left >> right, true
+	case "&":
+		return left & right, true
+	case "|":
+		return left | right, true
+	case "^":
+		return left ^ right, true
+	case "<":
+		return truth(left < right), true
+	case "<=":
+		return truth(left <= right), true
+	case ">":
+		return truth(left > right), true
+	case ">=":
+		return truth(left >= right), true
+	case "==", "===":
+		return truth(left == right), true
+	case "!=", "!==":
+		return truth(left != right), true
+	case "&&":
+		return truth(left != 0 && right != 0), true
+	case "||":
+		return truth(left != 0 || right != 0), true
+	}
+	return 0, false
+}
+
+func (i *Interpreter) constantValue(scope *Scope, node ValueNode) (value int64, ok bool) {
+	if len(node.Value) != 1 || len(node.Selectors) > 0 || node.Scope != nil {
+		return 0, false
+	}
+	token := node.Value[0]
+	switch token.Type {
+	case LITERAL:
+		number, err := token.Number()
+		if err != nil || number.Fill {
+			return 0, false
+		}
+		unsigned, ok := number.Uint64()
+		if !ok || unsigned > math.MaxInt64 {
+			return 0, false
+		}
+		value = int64(unsigned)
+	case IDENTIFIER:
+		symbol, declared := lookup(scope, token.Name())
+		if symbol == nil || symbol.Kind != ParameterSymbol || symbol.Value == nil || i.resolving[symbol] {
+			return 0, false
+		}
+		i.resolving[symbol] = true
+		value, ok = i.constant(declared, *symbol.Value)
+		delete(i.resolving, symbol)
+		if !ok {
+			return 0, false
+		}
+	default:
+		return 0, false
+	}
+	if node.Unary != nil {
+		switch node.Unary.Value {
+		case "-":
+			value = -value
+		case "!":
+			if value == 0 {
+				value = 1
+			} else {
+				value = 0
+			}
+		default:
+			// the complement depends on the width
+			return 0, false
+		}
+	}
+	return value, true
+}
+
+// rangeWidth returns the number of bits that a range like [7:0] spans
+func (i *Interpreter) rangeWidth(scope *Scope, node RangeNode) (int, bool) {
+	from, ok := i.constant(scope, node.From)
+	if !ok {
+		return 0, false
+	}
+	to, ok := i.constant(scope, node.To)
+	if !ok {
+		return 0, false
+	}
+	if from < to {
+		from, to = to, from
+	}
+	return int(from-to) + 1, true
+}
+
+// typeDimensions returns the widths of the packed dimensions of a type, outermost first,
+// followed by the width of its elements, ie 4, 8, and 1 for logic [3:0][7:0]
+func (i *Interpreter) typeDimensions(scope *Scope, typeNode TypeNode, depth int) ([]int, bool) {
+	var element []int
+	if typeNode.Type.Type == IDENTIFIER {
+		typedef, ok := i.resolveType(scope, typeNode)
+		if !ok || depth > 16 {
+			return nil, false
+		}
+		switch {
+		case typedef.Type != nil:
+			element, ok = i.typeDimensions(scope, *typedef.Type, depth+1)
+		case typedef.Enum != nil && typedef.Enum.Type != nil:
+			element, ok = i.typeDimensions(scope, *typedef.Enum.Type, depth+1)
+		case typedef.Enum != nil:
+			element = []int{32}
+		case typedef.Struct != nil && typedef.Struct.Packed:
+			total := 0
+			for _, member := range typedef.Struct.Members {
+				dimensions, ok := i.typeDimensions(scope, member.Type, depth+1)
+				if !ok {
+					return nil, false
+				}
+				for _, variable := range member.Variables {
+					if len(variable.Ranges) > 0 {
+						return nil, false
+					}
+					total += product(dimensions)
+				}
+			}
+			element = []int{total}
+		default:
+			ok = false
+		}
+		if !ok {
+			return nil, false
+		}
+	} else if bits, ok := typeWidths[typeNode.Type.Value]; ok {
+		element = []int{bits}
+	} else {
+		return nil, false
+	}
+
+	dimensions := []int{}
+	for _, r := range typeNode.Ranges {
+		bits, ok := i.rangeWidth(scope, r)
+		if !ok {
+			return nil, false
+		}
+		dimensions = append(dimensions, bits)
+	}
+	return append(dimensions, element...), true
+}
+
+func product(dimensions []int) int {
+	result := 1
+	for _, dimension := range dimensions {
+		result *= dimension
+	}
+	return result
+}
+
+// variableWidth returns the width of a variable, with the members of structs and the
+// selectors after it, ie the width of mem[3][7:4] is 4 when mem is a reg [7:0] mem [0:15]
+func (i *Interpreter) variableWidth(scope *Scope, identifier Token, members []Token, selectors []SelectorNode) (width, bool) {
+	symbol, declared := lookup(scope, identifier.Name())
+	if symbol == nil || symbol.Type == nil || symbol.Interface != nil || i.resolving[symbol] {
+		return width{}, false
+	}
+	switch symbol.Kind {
+	case PortSymbol, VariableSymbol, ParameterSymbol, EnumMemberSymbol:
+	default:
+		return width{}, false
+	}
+
+	// parameters without a range are as wide as their value
+	isParameter := symbol.Type.Type.Value == "parameter" || symbol.Type.Type.Value == "localparam"
+	if isParameter && len(symbol.Type.Ranges) == 0 {
+		if symbol.Value == nil || len(members) > 0 || len(selectors) > 0 {
+			return width{}, false
+		}
+		i.resolving[symbol] = true
+		defer delete(i.resolving, symbol)
+		return i.expressionWidth(declared, *symbol.Value)
+	}
+
+	typeNode := *symbol.Type
+	// the elements of unpacked arrays are selected first
+	if len(selectors) < len(symbol.Ranges) || (len(members) > 0 && len(symbol.Ranges) > 0) {
+		return width{}, false
+	}
+	for _, selector := range selectors[:len(symbol.Ranges)] {
+		if selector.IndexNode == nil {
+			return width{}, false
+		}
+	}
+	selectors = selectors[len(symbol.Ranges):]
+
+	// then the members of structs
+	for _, member := range members {
+		typedef, ok := i.resolveType(declared, typeNode)
+		if !ok || typedef.Struct == nil {
+			return width{}, false
+		}
+		declaration := typedef.Struct.Member(member.Name())
+		if declaration == nil {
+			return width{}, false
+		}
+		typeNode = declaration.Type
+	}
+
+	dimensions, ok := i.typeDimensions(declared, typeNode, 0)
+	if !ok {
+		return width{}, false
+	}
+	for _, selector := range selectors {
+		if selector.IndexNode != nil {
+			if len(dimensions) > 1 {
+				dimensions = dimensions[1:]
+			} else {
+				dimensions = []int{1}
+			}
+			continue
+		}
+		bits, ok := i.rangeWidth(scope, *selector.RangeNode)
+		if !ok {
+			return width{}, false
+		}
+		if len(dimensions) > 1 {
+			dimensions = append([]int{bits}, dimensions[1:]...)
+		} else {
+			dimensions = []int{bits}
+		}
+	}
+	return width{bits: product(dimensions)}, true
+}
+
+// literalWidth returns the width of a number or string
+func literalWidth(token Token) (width, bool) {
+	if strings.HasPrefix(token.Value, `"`) {
+		characters := len(token.Decoded())
+		if characters == 0 {
+			characters = 1
+		}
+		return width{bits: 8 * characters}, true
+	}
+	number, err := token.Number()
+	if err != nil || number.Real {
+		return width{}, false
+	}
+	if number.Size > 0 {
+		return width{bits: number.Size}, true
+	}
+	if value, ok := number.Uint64(); ok && value <= math.MaxInt64 {
+		return width{bits: bitsFor(int64(value)), unsized: true}, true
+	}
+	// '1, 'x, and the like fill whatever they're assigned to
+	return width{bits: 1, unsized: true}, true
+}
+
+func (i *Interpreter) valueWidth(scope *Scope, node ValueNode) (width, bool) {
+	if len(node.Value) == 0 || node.Scope != nil {
+		return width{}, false
+	}
+	if node.Unary != nil && node.Unary.Value == "!" {
+		return width{bits: 1}, true
+	}
+	token := node.Value[0]
+	switch {
+	case token.Type == LITERAL && len(node.Selectors) == 0:
+		return literalWidth(token)
+	case token.Type == IDENTIFIER:
+		return i.variableWidth(scope, token, node.Value[1:], node.Selectors)
+	}
+	return width{}, false
+}
+
+// sizedWidth returns the width of a value, or of a concatenation or replication of values
+func (i *Interpreter) sizedWidth(scope *Scope, node SizedValueNode) (width, bool) {
+	if node.Size == nil && len(node.Elements) == 0 && len(node.Values) == 1 {
+		return i.valueWidth(scope, node.Values[0])
+	}
+
+	// the values of concatenations are sized on their own, so unsized numbers are 32 bits
+	widths := []width{}
+	if len(node.Elements) > 0 {
+		for _, element := range node.Elements {
+			w, ok := i.sizedWidth(scope, element)
+			if !ok {
+				return width{}, false
+			}
+			widths = append(widths, w)
+		}
+	} else {
+		for _, value := range node.Values {
+			w, ok := i.valueWidth(scope, value)
+			if !ok {
+				return width{}, false
+			}
+			widths = append(widths, w)
+		}
+	}
+	total := 0
+	for _, w := range widths {
+		if w.unsized && w.bits < 32 {
+			w.bits = 32
+		}
+		total += w.bits
+	}
+	if node.Size != nil {
+		count, ok := i.constantValue(scope, ValueNode{Value: []Token{*node.Size}})
+		if !ok || count < 0 {
+			return width{}, false
+		}
+		total *= int(count)
+	}
+	return width{bits: total}, true
+}
+
+// expressionWidth returns the self-determined width of an expression, and false if it isn't known,
+// which is the case for anything made of something whose width isn't known
+func (i *Interpreter) expressionWidth(scope *Scope, node ExprNode) (width, bool) {
+	chain, ternary := flatten(node)
+	if ternary == nil {
+		return i.operationWidth(scope, chain)
+	}
+	whenTrue, ok := i.expressionWidth(scope, *ternary.ExprTrue)
+	if !ok {
+		return width{}, false
+	}
+	whenFalse, ok := i.expressionWidth(scope, *ternary.ExprFalse)
+	if !ok {
+		return width{}, false
+	}
+	return widest(whenTrue, whenFalse), true
+}
+
+// widest returns the width of the operands of an operator that sizes them to the widest of them
+func widest(a width, b width) width {
+	result := width{bits: a.bits, unsized: a.unsized && b.unsized}
+	if b.bits > result.bits {
+		result.bits = b.bits
+	}
+	return result
+}
+
+func (i *Interpreter) operationWidth(scope *Scope, op *operation) (result width, ok bool) {
+	switch {
+	case op.operator == nil && op.operand.Nested != nil:
+		result, ok = i.expressionWidth(scope, *op.operand.Nested)
+	case op.operator == nil:
+		result, ok = i.sizedWidth(scope, op.operand.Value)
+	case comparisons[op.operator.Value]:
+		return width{bits: 1}, true
+	case op.operator.Value == "<<" || op.operator.Value == ">>":
+		// the shift amount doesn't matter
+		result, ok = i.operationWidth(scope, op.left)
+	default:
+		var left, right width
+		left, ok = i.operationWidth(scope, op.left)
+		if ok {
+			right, ok = i.operationWidth(scope, op.right)
+		}
+		result = widest(left, right)
+	}
+	if !ok {
+		return width{}, false
+	}
+	// the value of unsized numbers decides how many bits they need
+	if result.unsized {
+		if value, isConstant := i.constantOperation(scope, op); isConstant {
+			result.bits = bitsFor(value)
+		}
+	}
+	return result, true
+}
+
+// mismatch describes how a value with one width changes when it's assigned to something with another,
+// or returns an empty string if it fits. Unsized numbers are extended without warnings
+func mismatch(from width, to int) string {
+	bits := "bits"
+	if from.bits == 1 {
+		bits = "bit"
+	}
+	if from.bits > to {
+		return fmt.Sprintf("truncates %d %s to %d", from.bits, bits, to)
+	}
+	if from.bits < to && !from.unsized {
+		return fmt.Sprintf("extends %d %s to %d", from.bits, bits, to)
+	}
+	return ""
+}
+
+// diagnoseAssignmentWidth warns when the value of an assignment is wider or narrower than what it's assigned to
+func (i *Interpreter) diagnoseAssignmentWidth(node AssignmentNode, scope *Scope) {
+	if node.Increment != nil || len(node.Variables) == 0 {
+		return
+	}
+	to := 0
+	names := []string{}
+	for _, variable := range node.Variables {
+		w, ok := i.variableWidth(scope, variable.Identifier, variable.Members, variable.Selectors)
+		if !ok {
+			return
+		}
+		to += w.bits
+		names = append(names, Print(variable))
+	}
+	target := names[0]
+	if len(names) > 1 {
+		target = "{" + strings.Join(names, ", ") + "}"
+	}
+	if from, ok := i.expressionWidth(scope, node.Value); ok {
+		if change := mismatch(from, to); change != "" {
+			i.addSpanDiagnostic(node.Value.Span, protocol.DiagnosticSeverityWarning, "Assignment to "+target+" "+change)
+		}
+	}
+}
+
+// diagnoseDeclarationWidths warns when the values that variables are declared with don't fit them
+func (i *Interpreter) diagnoseDeclarationWidths(node DeclarationNode, scope *Scope) {
+	for k, value := range node.Values {
+		variable := node.Variables[k]
+		if len(variable.Ranges) > 0 {
+			continue
+		}
+		to, ok := i.variableWidth(scope, variable.Identifier, nil, nil)
+		if !ok {
+			continue
+		}
+		if from, ok := i.expressionWidth(scope, value); ok {
+			if change := mismatch(from, to.bits); change != "" {
+				i.addSpanDiagnostic(value.Span, protocol.DiagnosticSeverityWarning, "Assignment to "+variable.Identifier.Value+" "+change)
+			}
+		}
+	}
+}
+
+// dependsOn returns whether the node refers to any of the parameters,
+// either directly or through the values of other parameters
+func (i *Interpreter) dependsOn(scope *Scope, node Node, parameters map[string]bool) bool {
+	found := false
+	Inspect(node, func(node Node, path []Node) bool {
+		value, ok := node.(ValueNode)
+		if found || !ok || len(value.Value) == 0 || value.Scope != nil {
+			return !found
+		}
+		symbol, declared := lookup(scope, value.Value[0].Name())
+		if symbol == nil || symbol.Kind != ParameterSymbol {
+			return true
+		}
+		if parameters[symbol.Name()] {
+			found = true
+		} else if symbol.Value != nil && !i.resolving[symbol] {
+			i.resolving[symbol] = true
+			found = i.dependsOn(declared, *symbol.Value, parameters)
+			delete(i.resolving, symbol)
+		}
+		return !found
+	})
+	return found
+}
+
+// overriddenWidth returns whether the width of the port depends on parameters that defparams override
+func (i *Interpreter) overriddenWidth(ports *Scope, port *Symbol, parameters map[string]bool) bool {
+	if len(parameters) == 0 {
+		return false
+	}
+	for _, r := range port.Ranges {
+		if i.dependsOn(ports, r, parameters) {
+			return true
+		}
+	}
+	return port.Type != nil && i.dependsOn(ports, *port.Type, parameters)
+}
+
+// diagnoseConnectionWidths warns when what's connected to a port is wider or narrower than the port.
+// Arrays of instances are left alone, and so are ports whose ranges depend on parameters that
+// defparams override, since the widths of those ports aren't the ones the module declares
+func (i *Interpreter) diagnoseConnectionWidths(node ModuleApplicationNode, scope *Scope) {
+	module := i.moduleMap[node.ModuleName.Name()]
+	if node.Range != nil {
+		return
+	}
+	var overrides map[string]bool
+	if node.GateName != nil {
+		overrides = i.overridden[node.GateName.Name()]
+	}
+	ports := i.moduleScope(module)
+	for k, argument := range node.Arguments {
+		if named(argument) != named(node.Arguments[0]) {
+			// mixed connections were already reported
+			return
+		}
+		var port Token
+		switch {
+		case argument.Wildcard != nil:
+			continue
+		case argument.Label != nil:
+			port = *argument.Label
+		case k < len(module.PortList.Ports):
+			port = module.PortList.Ports[k]
+		default:
+			continue
+		}
+		symbol := ports.Symbol(port.Name())
+		if symbol == nil || symbol.Direction == nil || i.overriddenWidth(ports, symbol, overrides) {
+			continue
+		}
+		portWidth, ok := i.variableWidth(ports, symbol.Identifier, nil, nil)
+		if !ok {
+			continue
+		}
+
+		var connected width
+		span := argument.Value.Span
+		if argument.Implicit {
+			connected, ok = i.variableWidth(scope, port, nil, nil)
+			span = argument.Span
+		} else if span != (Span{}) {
+			connected, ok = i.expressionWidth(scope, argument.Value)
+		} else {
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		// outputs drive what's connected to them, while inputs and inouts are driven by it
+		direction := symbol.Direction.Value
+		change := mismatch(connected, portWidth.bits)
+		if direction == "output" {
+			change = ""
+			if !connected.unsized {
+				change = mismatch(portWidth, connected.bits)
+			}
+		}
+		if change != "" {
+			i.addSpanDiagnostic(span, protocol.DiagnosticSeverityWarning, "Connection to "+direction+" "+port.Value+" of module "+module.Identifier.Value+" "+change)
+			i.relateTo(module)
+		}
+	}
+}
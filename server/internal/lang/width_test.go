@@ -0,0 +1,95 @@
+package lang
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestExpressionWidth(t *testing.T) {
+	scope := symbolTable(t, "module m;\n  parameter N = 4;\n  wire [7:0] a;\n  wire [N-1:0] b;\n  reg [7:0] mem [0:3];\n  integer i;\nendmodule\n").Children[0]
+	interpreter := NewInterpreter(zap.NewNop(), map[string][]ModuleNode{}, map[string][]InterfaceNode{}, map[string][]PackageNode{}, map[string][]DefineNode{})
+
+	tests := []struct {
+		expression string
+		bits       int
+		unsized    bool
+		known      bool
+	}{
+		{"a", 8, false, true},
+		{"b", 4, false, true},
+		{"a + b", 8, false, true},
+		{"a + 1", 8, false, true},
+		{"a == b", 1, false, true},
+		{"!a", 1, false, true},
+		{"b << 4", 4, false, true},
+		{"{a, b}", 12, false, true},
+		{"{N{b}}", 16, false, true},
+		{"{2{a}, b}", 20, false, true},
+		{"{a, 1}", 40, false, true},
+		{"a[3:0]", 4, false, true},
+		{"a[0]", 1, false, true},
+		{"mem[0]", 8, false, true},
+		{"mem[0][N-1:0]", 4, false, true},
+		{"i", 32, false, true},
+		{"b ? a : b", 8, false, true},
+		{"4'd3", 4, false, true},
+		{"255", 8, true, true},
+		{"N * 2 - 1", 3, true, true},
+		{"1 << N", 5, true, true},
+		{"-1", 1, true, true},
+		{"\"AB\"", 16, false, true},
+		{"mem", 0, false, false},
+		{"unknown", 0, false, false},
+	}
+	for _, test := range tests {
+		tokens, err := NewVLexer(zap.NewNop()).Lex(test.expression)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expression, _, err := NewParser().parseExpression(tokens, 0)
+		if err != nil {
+			t.Fatalf("couldn't parse %s: %s", test.expression, err)
+		}
+		result, known := interpreter.expressionWidth(scope, expression)
+		if known != test.known || result.bits != test.bits || result.unsized != test.unsized {
+			t.Errorf("width of %s is %+v, %t, expected %d bits, unsized %t, known %t", test.expression, result, known, test.bits, test.unsized, test.known)
+		}
+	}
+}
+
+func TestConstant(t *testing.T) {
+	scope := symbolTable(t, "module m;\n  parameter N = 4;\n  localparam W = 2 * N - 1;\n  parameter P = P + 1;\nendmodule\n").Children[0]
+	interpreter := NewInterpreter(zap.NewNop(), map[string][]ModuleNode{}, map[string][]InterfaceNode{}, map[string][]PackageNode{}, map[string][]DefineNode{})
+
+	tests := []struct {
+		expression string
+		value      int64
+		ok         bool
+	}{
+		{"1 + 2 * 3", 7, true},
+		{"(1 + 2) * 3", 9, true},
+		{"10 - 4 - 3", 3, true},
+		{"W", 7, true},
+		{"N > 2 ? N : 2", 4, true},
+		{"1 << N | 1", 17, true},
+		{"8'hFF", 255, true},
+		{"N / 0", 0, false},
+		{"P", 0, false},
+		{"4'bx", 0, false},
+	}
+	for _, test := range tests {
+		tokens, err := NewVLexer(zap.NewNop()).Lex(test.expression)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expression, _, err := NewParser().parseExpression(tokens, 0)
+		if err != nil {
+			t.Fatalf("couldn't parse %s: %s", test.expression, err)
+		}
+		value, ok := interpreter.constant(scope, expression)
+		if ok != test.ok || value != test.value {
+			t.Errorf("value of %s is %d, %t, expected %d, %t", test.expression, value, ok, test.value, test.ok)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package vlsp
 import (
 	"context"
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/chrehall68/vls/internal/lang"
@@ -10,19 +11,131 @@ import (
 	"go.lsp.dev/protocol"
 )
 
+// formatModuleApplication returns a snippet that instantiates the module,
+// with tab stops for the instance name and each port's connection
 func (h Handler) formatModuleApplication(module lang.ModuleNode) string {
-	params := make([]string, len(module.PortList.Ports))
-	i := 2
-	for _, param := range module.PortList.Ports {
-		params[i-2] = fmt.Sprintf(".%s($%d)", param.Value, i)
-		i++
+	gateName := lang.Token{Type: lang.IDENTIFIER, Value: "${1:name}"}
+	application := lang.ModuleApplicationNode{ModuleName: module.Identifier, GateName: &gateName}
+	for i, port := range module.PortList.Ports {
+		label := port
+		stop := lang.Token{Type: lang.IDENTIFIER, Value: fmt.Sprintf("$%d", i+2)}
+		application.Arguments = append(application.Arguments, lang.ArgumentNode{
+			Label: &label,
+			Value: lang.ExprNode{Value: lang.SizedValueNode{Values: []lang.ValueNode{{Value: []lang.Token{stop}}}}},
+		})
+	}
+	return lang.Print(application)
+}
+
+// symbolCompletionKinds is the kind of completion item for each kind of symbol
+var symbolCompletionKinds = map[lang.SymbolKind]protocol.CompletionItemKind{
+	lang.PortSymbol:       protocol.CompletionItemKindVariable,
+	lang.VariableSymbol:   protocol.CompletionItemKindVariable,
+	lang.ParameterSymbol:  protocol.CompletionItemKindConstant,
+	lang.InstanceSymbol:   protocol.CompletionItemKindModule,
+	lang.TypeSymbol:       protocol.CompletionItemKindTypeParameter,
+	lang.EnumMemberSymbol: protocol.CompletionItemKindEnumMember,
+	lang.TaskSymbol:       protocol.CompletionItemKindFunction,
+	lang.BlockSymbol:      protocol.CompletionItemKindModule,
+}
+
+var memberAccessRegex = regexp.MustCompile(`([A-Za-z_][A-Za-z0-9_]*)(\.|::)[A-Za-z0-9_]*$`)
+
+// memberQualifier returns what's before the dot or :: that the cursor is after, if anything,
+// ie req for req.va or cfg_pkg for cfg_pkg::
+func (h Handler) memberQualifier(fname string, line int, character int) (string, bool) {
+	file, ok := h.state.files[fname]
+	if !ok {
+		return "", false
+	}
+	lines := strings.Split(file.GetContents(), "\n")
+	if line >= len(lines) {
+		return "", false
+	}
+	matches := memberAccessRegex.FindStringSubmatch(lines[line][:lang.ByteOffset(lines[line], character)])
+	if len(matches) == 0 {
+		return "", false
+	}
+	return matches[1], true
+}
+
+// documentation returns the documentation of a completion item from the comments
+// of what it completes, or nil if there aren't any
+func documentation(comments lang.Trivia) interface{} {
+	if doc := comments.Doc(); doc != "" {
+		return doc
 	}
-	return fmt.Sprintf("%s ${1:name}(%s);", module.Identifier.Value, strings.Join(params, ", "))
+	return nil
+}
+
+// packageCompletionItems returns completions for everything that a package makes available
+func (h Handler) packageCompletionItems(pkg lang.PackageNode) []protocol.CompletionItem {
+	completionItems := []protocol.CompletionItem{}
+	typedefs := map[string]bool{}
+	for _, typedef := range pkg.Typedefs() {
+		typedefs[typedef.Identifier.Name()] = true
+		kind := protocol.CompletionItemKindTypeParameter
+		if typedef.Enum != nil {
+			kind = protocol.CompletionItemKindEnum
+			for _, member := range typedef.Enum.Members {
+				typedefs[member.Identifier.Name()] = true
+				completionItems = append(completionItems, protocol.CompletionItem{
+					Label:         member.Identifier.Value,
+					Kind:          protocol.CompletionItemKindEnumMember,
+					Detail:        "enum literal of " + typedef.Identifier.Value,
+					Documentation: documentation(member.Comments),
+					InsertText:    member.Identifier.Value,
+				})
+			}
+		} else if typedef.Struct != nil {
+			kind = protocol.CompletionItemKindStruct
+		}
+		completionItems = append(completionItems, protocol.CompletionItem{
+			Label:         typedef.Identifier.Value,
+			Kind:          kind,
+			Detail:        "typedef",
+			Documentation: documentation(typedef.Comments),
+			InsertText:    typedef.Identifier.Value,
+		})
+	}
+	for name := range pkg.Symbols() {
+		if !typedefs[name] {
+			completionItems = append(completionItems, protocol.CompletionItem{
+				Label:      name,
+				Detail:     "variable of " + pkg.Identifier.Value,
+				InsertText: lang.Spelling(name),
+			})
+		}
+	}
+	return completionItems
 }
 
 func (h Handler) Completion(ctx context.Context, params *protocol.CompletionParams) (result *protocol.CompletionList, err error) {
 	h.state.log.Sugar().Infof("Completion called")
 	var completionItems []protocol.CompletionItem
+	fname := URIToPath(string(params.TextDocument.URI))
+	line, character := int(params.Position.Line), int(params.Position.Character)
+
+	// member completions, ie cfg_pkg::, req., or m.
+	if qualifier, ok := h.memberQualifier(fname, line, character); ok {
+		if pkg, ok := h.findPackage(qualifier); ok {
+			return &protocol.CompletionList{Items: h.packageCompletionItems(pkg), IsIncomplete: true}, nil
+		}
+		details, err := h.getLocationDetails(fname, line, character-1)
+		if err == nil {
+			if typeName, ok := h.qualifierType(h.scopeAt(fname, line, character), details.currentModule, qualifier); ok {
+				for name := range h.state.variableDefinitions[typeName] {
+					completionItems = append(completionItems, protocol.CompletionItem{
+						Label:      name,
+						Kind:       protocol.CompletionItemKindField,
+						Detail:     "member of " + typeName,
+						InsertText: lang.Spelling(name),
+					})
+				}
+				return &protocol.CompletionList{Items: completionItems, IsIncomplete: true}, nil
+			}
+		}
+	}
 
 	// global-level completions
 	for word, emoji := range mappers.EmojiMapper {
@@ -35,9 +148,10 @@ func (h Handler) Completion(ctx context.Context, params *protocol.CompletionPara
 	for _, defines := range h.state.defines {
 		for _, define := range defines {
 			completionItems = append(completionItems, protocol.CompletionItem{
-				Label:      "`" + define.Identifier.Value,
-				Detail:     "define",
-				InsertText: "`" + define.Identifier.Value,
+				Label:         "`" + define.Identifier.Value,
+				Detail:        "define",
+				Documentation: documentation(define.Comments),
+				InsertText:    "`" + define.Identifier.Value,
 			})
 		}
 	}
@@ -46,18 +160,30 @@ func (h Handler) Completion(ctx context.Context, params *protocol.CompletionPara
 			completionItems = append(completionItems, protocol.CompletionItem{
 				Label:            module.Identifier.Value,
 				Detail:           "module",
+				Documentation:    documentation(module.Comments),
 				InsertText:       h.formatModuleApplication(module),
 				InsertTextFormat: protocol.InsertTextFormatSnippet,
 			})
 		}
 	}
-	for _, keyword := range lang.Keywords {
+	language := lang.Verilog
+	if IsSystemVerilogFile(fname) {
+		language = lang.SystemVerilog
+	}
+	for _, keyword := range lang.ReservedWords(language) {
 		completionItems = append(completionItems, protocol.CompletionItem{
 			Label:      keyword,
 			Detail:     "keyword",
 			InsertText: keyword,
 		})
 	}
+	for _, directive := range lang.Directives {
+		completionItems = append(completionItems, protocol.CompletionItem{
+			Label:      directive,
+			Detail:     "directive",
+			InsertText: directive,
+		})
+	}
 	for snippetName, snippet := range lang.Snippets {
 		completionItems = append(completionItems, protocol.CompletionItem{
 			Label:            snippetName,
@@ -66,18 +192,46 @@ func (h Handler) Completion(ctx context.Context, params *protocol.CompletionPara
 			InsertTextFormat: protocol.InsertTextFormatSnippet,
 		})
 	}
+	if language == lang.SystemVerilog {
+		for snippetName, snippet := range lang.SVSnippets {
+			completionItems = append(completionItems, protocol.CompletionItem{
+				Label:            snippetName,
+				Detail:           "snippet",
+				InsertText:       snippet,
+				InsertTextFormat: protocol.InsertTextFormatSnippet,
+			})
+		}
+	}
 
 	// local-level completions
-	details, err := h.getLocationDetails(URIToPath(string(params.TextDocument.URI)), int(params.Position.Line), int(params.Position.Character))
-	if err == nil {
+	details, err := h.getLocationDetails(fname, line, character)
+	if scope := h.scopeAt(fname, line, character); scope != nil {
+		// everything that's visible from the cursor
+		for _, symbol := range scope.Visible() {
+			completionItems = append(completionItems, protocol.CompletionItem{
+				Label:      symbol.Identifier.Value,
+				Kind:       symbolCompletionKinds[symbol.Kind],
+				Detail:     symbol.Detail(),
+				InsertText: symbol.Identifier.Value,
+			})
+		}
+	} else if err == nil {
 		for name := range h.state.variableDefinitions[details.currentModule] {
 			completionItems = append(completionItems, protocol.CompletionItem{
 				Label:      name,
 				Detail:     "variable",
-				InsertText: name,
+				InsertText: lang.Spelling(name),
 			})
 		}
 	}
+	if err == nil {
+		// and whatever the module imports
+		for _, pkgName := range h.state.imports[details.currentModule] {
+			if pkg, ok := h.findPackage(pkgName); ok {
+				completionItems = append(completionItems, h.packageCompletionItems(pkg)...)
+			}
+		}
+	}
 	//h.state.log.Sugar().Infof("completionItems: %v", completionItems)
 
 	return &protocol.CompletionList{Items: completionItems, IsIncomplete: true}, nil
@@ -12,31 +12,32 @@ import (
 
 type LocationDetails struct {
 	token         lang.Token
+	qualifier     *lang.Token // identifier before the dot or ::, if the token is accessed with one
 	currentModule string
 }
 
 func (h Handler) getLocationDetails(fname string, line int, character int) (*LocationDetails, error) {
-	f := h.state.files[fname].GetContents()
+	f := h.file(fname).GetContents()
 	reader := bufio.NewReader(strings.NewReader(f))
-	lexer := lang.NewVLexer(h.state.log)
-	parser := lang.NewParser()
+	lexer := NewLexerFor(fname, h.state.log)
+	parser := NewParserFor(fname)
 	lineString := ""
 	curModule := ""
 	for l := 0; l <= line; l++ {
 		lineString, _ = reader.ReadString('\n')
 
-		// keep track of which module we're inside
-		if strings.Contains(lineString, "module") {
+		// keep track of which module or interface we're inside
+		if strings.Contains(lineString, "module") || strings.Contains(lineString, "interface") || strings.Contains(lineString, "package") {
 			tokens, err := lexer.Lex(lineString)
 			h.state.log.Sugar().Info("lineTokens: ", tokens)
 			if err == nil {
 				for i := range tokens {
-					if tokens[i].Type == "module" {
+					if tokens[i].Type == lang.MODULE || tokens[i].Type == lang.INTERFACE || tokens[i].Type == lang.PACKAGE {
 						// new module?
-						pos, err := parser.CheckToken("", []string{"identifier"}, i+1, tokens)
+						pos, err := parser.CheckToken("", []lang.TokenKind{lang.IDENTIFIER}, i+1, tokens)
 						if err == nil {
 							// pos contains module name
-							curModule = tokens[pos].Value
+							curModule = tokens[pos].Name()
 						}
 					}
 				}
@@ -44,19 +45,40 @@ func (h Handler) getLocationDetails(fname string, line int, character int) (*Loc
 		}
 	}
 	tokens, _ := lexer.Lex(lineString)
-	tokenStart := 0
 
-	for _, token := range tokens {
-		tokenEnd := tokenStart + len(token.Value)
-		if tokenStart <= int(character) && int(character) < tokenEnd {
+	for i, token := range tokens {
+		if token.StartCharacter() <= character && character < token.EndCharacter() {
 			// this is the result
-			return &LocationDetails{token: token, currentModule: curModule}, nil
+			details := &LocationDetails{token: token, currentModule: curModule}
+			if i >= 2 && (tokens[i-1].Type == lang.DOT || tokens[i-1].Type == lang.SCOPE) && tokens[i-2].Type == lang.IDENTIFIER {
+				details.qualifier = &tokens[i-2]
+			}
+			return details, nil
 		}
-		tokenStart = tokenEnd
 	}
 	return nil, fmt.Errorf("no token at that position")
 }
 
+// scopeAt returns the innermost scope around the position in the last
+// successful parse of the file, or nil if it never parsed
+func (h Handler) scopeAt(fname string, line int, character int) *lang.Scope {
+	parse, ok := h.state.parses[fname]
+	if !ok {
+		return nil
+	}
+	return parse.table.ScopeAt(line, character)
+}
+
+// qualifierType returns the name of the module, interface, or type
+// whose members can be accessed through the qualifier, ie fifo for f in f.data
+func (h Handler) qualifierType(scope *lang.Scope, currentModule string, qualifier string) (string, bool) {
+	if symbol := scope.Lookup(qualifier); symbol != nil && symbol.TypeName() != "" {
+		return symbol.TypeName(), true
+	}
+	typeName, ok := h.state.instanceTypes[currentModule][qualifier]
+	return typeName, ok
+}
+
 func (h Handler) jumpTo(fname string, line int, character int) ([]protocol.Location, error) {
 	details, err := h.getLocationDetails(fname, line, character)
 
@@ -65,10 +87,31 @@ func (h Handler) jumpTo(fname string, line int, character int) ([]protocol.Locat
 	}
 
 	// process this token
+	scope := h.scopeAt(fname, line, character)
 	result := []protocol.Location{}
-	if details.token.Type == "identifier" {
+	if details.token.Type == lang.IDENTIFIER && details.qualifier != nil {
+		// either a modport, ie axi_if.master, a member, ie m.awvalid or req.valid,
+		// or something inside a package, ie cfg_pkg::IDLE
+		location, ok := h.state.symbolMap[details.qualifier.Name()+"."+details.token.Name()]
+		if _, isPackage := h.findPackage(details.qualifier.Name()); isPackage {
+			location, ok := h.state.variableDefinitions[details.qualifier.Name()][details.token.Name()]
+			if ok {
+				result = append(result, location)
+			}
+		} else if ok {
+			result = append(result, location)
+		} else if typeName, ok := h.qualifierType(scope, details.currentModule, details.qualifier.Name()); ok {
+			location, ok := h.state.variableDefinitions[typeName][details.token.Name()]
+			if ok {
+				result = append(result, location)
+			}
+		}
+	} else if symbol := scope.Lookup(details.token.Name()); details.token.Type == lang.IDENTIFIER && symbol != nil {
+		// it's declared in the scope around it, or one containing that
+		result = append(result, tokenLocation(fname, symbol.Identifier))
+	} else if details.token.Type == lang.IDENTIFIER {
 		// see if it's a module or definition
-		location, ok := h.state.symbolMap[details.token.Value]
+		location, ok := h.state.symbolMap[details.token.Name()]
 		if ok {
 			result = append(result, location)
 		} else {
@@ -76,11 +119,21 @@ func (h Handler) jumpTo(fname string, line int, character int) ([]protocol.Locat
 			moduleMap, ok := h.state.variableDefinitions[details.currentModule]
 			if ok {
 				// look for variable definition
-				location, ok := moduleMap[details.token.Value]
+				location, ok := moduleMap[details.token.Name()]
 				if ok {
 					result = append(result, location)
 				}
 			}
+			if len(result) == 0 {
+				// or something imported from a package
+				for _, pkg := range h.state.imports[details.currentModule] {
+					location, ok := h.state.variableDefinitions[pkg][details.token.Name()]
+					if ok {
+						result = append(result, location)
+						break
+					}
+				}
+			}
 		}
 	}
 
@@ -0,0 +1,48 @@
+package vlsp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.lsp.dev/protocol"
+	"go.uber.org/zap"
+)
+
+func TestDefinitionOfMacro(t *testing.T) {
+	code := "`define WIDTH 8\n`define DEPTH 4\nmodule a;\n  wire [`WIDTH-1:0] x;\nendmodule\n`undef DEPTH\nmodule b;\n  wire [`DEPTH-1:0] y;\nendmodule\n"
+	fname := filepath.Join(t.TempDir(), "macros.v")
+	if err := os.WriteFile(fname, []byte(code), 0644); err != nil {
+		t.Fatal(err)
+	}
+	h, _, err := NewHandler(context.Background(), nil, nil, zap.NewNop(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.state.symbolMap = map[string]protocol.Location{}
+	h.GetSymbolsForFile(fname, true)
+
+	tests := []struct {
+		line      uint32
+		character uint32
+		found     bool
+	}{
+		{3, 9, true},  // `WIDTH
+		{7, 9, false}, // `DEPTH, which was undefined
+	}
+	for _, test := range tests {
+		locations, err := h.Definition(context.Background(), &protocol.DefinitionParams{
+			TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+				TextDocument: protocol.TextDocumentIdentifier{URI: protocol.DocumentURI(PathToURI(fname))},
+				Position:     protocol.Position{Line: test.line, Character: test.character},
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if found := len(locations) > 0; found != test.found {
+			t.Errorf("definition at %d:%d is %v, expected found %t", test.line, test.character, locations, test.found)
+		}
+	}
+}
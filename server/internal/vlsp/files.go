@@ -4,6 +4,9 @@ import (
 	"os"
 	"runtime"
 	"strings"
+
+	"github.com/chrehall68/vls/internal/lang"
+	"go.uber.org/zap"
 )
 
 // File represents a file, either on disk or in memory
@@ -43,6 +46,17 @@ func (f *File) Save() {
 	f.contents = ""
 }
 
+// file returns the file with the given name (w/o the file://),
+// which is read from disk if the client hasn't opened it
+func (h Handler) file(fname string) *File {
+	f, ok := h.state.files[fname]
+	if !ok {
+		f = NewFile(fname)
+		h.state.files[fname] = f
+	}
+	return f
+}
+
 func URIToPath(uri string) string {
 	os := runtime.GOOS
 	uri = strings.TrimPrefix(uri, "file://")
@@ -63,3 +77,29 @@ func PathToURI(path string) string {
 	}
 	return "file://" + path
 }
+
+// IsHDLFile returns whether the file is a Verilog or SystemVerilog source
+func IsHDLFile(path string) bool {
+	return strings.HasSuffix(path, ".v") || IsSystemVerilogFile(path)
+}
+
+// IsSystemVerilogFile returns whether the file is a SystemVerilog source
+func IsSystemVerilogFile(path string) bool {
+	return strings.HasSuffix(path, ".sv") || strings.HasSuffix(path, ".svh")
+}
+
+// NewLexerFor makes a lexer for the language of the given file
+func NewLexerFor(path string, logger *zap.Logger) *lang.VLexer {
+	if IsSystemVerilogFile(path) {
+		return lang.NewSVLexer(logger)
+	}
+	return lang.NewVLexer(logger)
+}
+
+// NewParserFor makes a parser for the language of the given file
+func NewParserFor(path string) *lang.Parser {
+	if IsSystemVerilogFile(path) {
+		return lang.NewSVParser()
+	}
+	return lang.NewParser()
+}
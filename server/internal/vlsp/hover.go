@@ -0,0 +1,57 @@
+package vlsp
+
+import (
+	"context"
+	"strings"
+
+	"github.com/chrehall68/vls/internal/lang"
+	"go.lsp.dev/protocol"
+)
+
+// declaration returns how the symbol would be declared, ie input wire [7:0] data
+func declaration(symbol *lang.Symbol) string {
+	result := strings.Builder{}
+	switch symbol.Kind {
+	case lang.InstanceSymbol:
+		result.WriteString(symbol.Type.Type.Value + " ")
+	case lang.TaskSymbol:
+		result.WriteString("task ")
+	case lang.BlockSymbol:
+		result.WriteString("begin : ")
+	case lang.TypeSymbol:
+		result.WriteString("typedef ")
+	case lang.EnumMemberSymbol:
+		// the literal's type is the enum
+	default:
+		result.WriteString(symbol.Detail() + " ")
+	}
+	result.WriteString(symbol.Identifier.Value)
+	for _, r := range symbol.Ranges {
+		result.WriteString(" " + lang.Print(r))
+	}
+	if symbol.Kind == lang.EnumMemberSymbol {
+		result.WriteString(" of " + symbol.Type.Type.Value)
+	}
+	return result.String()
+}
+
+func (h Handler) Hover(ctx context.Context, params *protocol.HoverParams) (*protocol.Hover, error) {
+	fname := URIToPath(string(params.TextDocument.URI))
+	line, character := int(params.Position.Line), int(params.Position.Character)
+	details, err := h.getLocationDetails(fname, line, character)
+	if err != nil || details.token.Type != lang.IDENTIFIER || details.qualifier != nil {
+		return nil, nil
+	}
+
+	// only what's declared in the scope around the token, or one containing that
+	symbol := h.scopeAt(fname, line, character).Lookup(details.token.Name())
+	if symbol == nil {
+		return nil, nil
+	}
+	return &protocol.Hover{
+		Contents: protocol.MarkupContent{
+			Kind:  protocol.Markdown,
+			Value: "```verilog\n" + declaration(symbol) + "\n```",
+		},
+	}, nil
+}
@@ -37,6 +37,8 @@ func GetSemanticTokensOptions() SemanticTokensOptions {
 				protocol.SemanticTokenParameter, // 6
 				protocol.SemanticTokenFunction,  // 7
 				protocol.SemanticTokenMacro,     // 8
+				protocol.SemanticTokenString,    // 9
+				protocol.SemanticTokenRegexp,    // 10, used for format specifiers like %d
 			},
 			TokenModifiers: []protocol.SemanticTokenModifiers{},
 		},
@@ -45,51 +47,36 @@ func GetSemanticTokensOptions() SemanticTokensOptions {
 	}
 }
 
+// legendIndex returns the index into the legend that tokens of the given kind
+// are highlighted as, and false if they shouldn't be highlighted
+func legendIndex(kind lang.TokenKind) (uint32, bool) {
+	switch {
+	case kind == lang.TYPE || kind == lang.DIRECTION || kind == lang.DEFPARAM:
+		return 0, true
+	case kind == lang.COMMENT:
+		return 1, true
+	case kind == lang.LITERAL:
+		return 2, true
+	case kind.IsKeyword() || kind.IsDirective():
+		return 3, true
+	case kind == lang.IDENTIFIER:
+		return 4, true
+	case kind == lang.EXISTING_MODULE:
+		return 5, true
+	case kind == lang.PORT:
+		return 6, true
+	case kind == lang.FUNCLITERAL || kind == lang.SIGNED || kind == lang.DOLLAR || kind == lang.POUND:
+		return 7, true
+	}
+	// 8 is reserved for defined identifiers
+	return 0, false
+}
+
 func (h Handler) Encode(tokens []lang.Token) []uint32 {
 	result := []uint32{}
 	prevLine := 0
 	prevCharacter := 0
 
-	// the bool doesn't matter
-	// if it's in ignoreTokens, then ignore it
-	// maps token type to int
-	// the tokens to ignore are not in here
-	tokenTypeToInt := map[string]uint32{
-		"comment":         1,
-		"type":            0,
-		"direction":       0,
-		"defparam":        0,
-		"literal":         2,
-		"module":          3,
-		"endmodule":       3,
-		"begin":           3,
-		"end":             3,
-		"case":            3,
-		"endcase":         3,
-		"generate":        3,
-		"endgenerate":     3,
-		"for":             3,
-		"if":              3,
-		"else":            3,
-		"assign":          3,
-		"initial":         3,
-		"always":          3,
-		"time":            3,
-		"default":         3,
-		"include":         3,
-		"timescale":       3,
-		"define":          3,
-		"task":            3,
-		"endtask":         3,
-		"identifier":      4,
-		"existing_module": 5,
-		"port":            6,
-		"funcliteral":     7,
-		"signed":          7,
-		"dollar":          7,
-		"pound":           7,
-		// 8 is reserved for defined identifiers
-	}
 	// flattened defines
 	flattenedDefines := map[string]bool{}
 	for _, defines := range h.state.defines {
@@ -98,27 +85,54 @@ func (h Handler) Encode(tokens []lang.Token) []uint32 {
 		}
 	}
 
-	addToken := func(token lang.Token) {
+	addRange := func(line int, character int, length int, val uint32) {
 		// [deltaLine, deltaStart, length, tokenType, tokenModifiers]
-		val, ok := tokenTypeToInt[token.Type]
-		if ok {
-			// if it's a new line, don't worry about character
-			if token.Line() != prevLine {
-				prevCharacter = 0
+		// if it's a new line, don't worry about character
+		if line != prevLine {
+			prevCharacter = 0
+		}
+
+		// add into result
+		result = append(result, uint32(line-prevLine), uint32(character-prevCharacter), uint32(length), val, 0)
+
+		// update line and character
+		prevLine = line
+		prevCharacter = character
+	}
+	addToken := func(token lang.Token) {
+		if token.Type == lang.FORMAT_STRING {
+			// highlight the format specifiers separately from the rest of the string
+			// specifiers are byte offsets into the value, but ranges are in UTF-16 code units
+			addPiece := func(start int, end int, val uint32) {
+				character := token.StartCharacter() + lang.UTF16Len(token.Value[:start])
+				addRange(token.Line(), character, lang.UTF16Len(token.Value[start:end]), val)
 			}
+			start := 0
+			for _, specifier := range token.FormatSpecifiers() {
+				if specifier.Start > start {
+					addPiece(start, specifier.Start, 9)
+				}
+				addPiece(specifier.Start, specifier.End, 10)
+				start = specifier.End
+			}
+			addPiece(start, len(token.Value), 9)
+			return
+		}
 
+		val, ok := legendIndex(token.Type)
+		if ok {
 			// special case for defined identifiers
 			_, ok := flattenedDefines[token.Value]
-			if token.Type == "identifier" && ok {
+			if token.Type == lang.IDENTIFIER && ok {
 				val = 8
 			}
 
-			// add into result
-			result = append(result, uint32(token.Line()-prevLine), uint32(token.StartCharacter()-prevCharacter), uint32(len(token.Value)), val, 0)
+			// and for strings
+			if token.IsString() {
+				val = 9
+			}
 
-			// update line and character
-			prevLine = token.Line()
-			prevCharacter = token.StartCharacter()
+			addRange(token.Line(), token.StartCharacter(), token.EndCharacter()-token.StartCharacter(), val)
 		}
 	}
 
@@ -134,67 +148,94 @@ func (h Handler) SemanticTokensFull(ctx context.Context, params *protocol.Semant
 
 	// get contents
 	f := URIToPath(string(params.TextDocument.URI))
-	contents := h.state.files[f].GetContents()
-
-	// extract tokens
-	lexer := lang.NewVLexer(h.state.log)
-	tokens, _ := lexer.Lex(contents)
-
-	// extract ast if possible
-	ast, err := lang.NewParser().ParseFile(tokens)
+	contents := h.file(f).GetContents()
+
+	// extract tokens and the ast if possible, reusing the last parse if it's up to date.
+	// The tokens are relabeled below, so they're copied
+	var tokens []lang.Token
+	var ast lang.FileNode
+	var err error
+	if parse, ok := h.state.parses[f]; ok && parse.code == contents {
+		tokens, ast = append([]lang.Token{}, parse.tokens...), parse.ast
+	} else {
+		tokens, _ = NewLexerFor(f, h.state.log).Lex(contents)
+		ast, err = NewParserFor(f).ParseFile(tokens)
+	}
 	if err == nil {
 		h.state.log.Sugar().Info("Getting statements for file: ", f)
-		interiorNodes := lang.GetInteriorStatements(ast)
-		tokensIdx := 0
+		labelTokens(tokens, ast)
+	}
 
-		for _, interiorNode := range interiorNodes {
-			if interiorNode.ModuleApplicationNode != nil {
-				// get to the current module
-				for tokens[tokensIdx] != interiorNode.ModuleApplicationNode.ModuleName && tokensIdx < len(tokens) {
-					tokensIdx++
-				}
+	// encode
+	result := &protocol.SemanticTokens{
+		Data: h.Encode(tokens),
+	}
+	h.state.log.Sugar().Info("SemanticTokensFull result: ", result)
 
-				// then label it as a module name
-				if tokensIdx < len(tokens) {
-					tokens[tokensIdx].Type = "existing_module"
-				}
+	return result, nil
+}
+
+// labelTokens relabels the tokens of module names, ports, functions, and format strings
+// in the AST, which are found in order. Tokens that can't be found are left alone
+func labelTokens(tokens []lang.Token, ast lang.FileNode) {
+	interiorNodes := lang.GetInteriorStatements(ast)
+	tokensIdx := 0
+
+	for _, interiorNode := range interiorNodes {
+		if interiorNode.ModuleApplicationNode != nil {
+			// get to the current module
+			for tokensIdx < len(tokens) && tokens[tokensIdx] != interiorNode.ModuleApplicationNode.ModuleName {
+				tokensIdx++
+			}
 
-				for _, argument := range interiorNode.ModuleApplicationNode.Arguments {
-					if argument.Label != nil {
-						// get to this label and label it as a port
-						for tokens[tokensIdx] != *argument.Label && tokensIdx < len(tokens) {
-							tokensIdx++
-						}
+			// then label it as a module name
+			if tokensIdx < len(tokens) {
+				tokens[tokensIdx].Type = lang.EXISTING_MODULE
+			}
 
-						if tokensIdx < len(tokens) {
-							tokens[tokensIdx].Type = "port"
-						}
+			for _, argument := range interiorNode.ModuleApplicationNode.Arguments {
+				if argument.Label != nil {
+					// get to this label and label it as a port
+					for tokensIdx < len(tokens) && tokens[tokensIdx] != *argument.Label {
+						tokensIdx++
+					}
+
+					if tokensIdx < len(tokens) {
+						tokens[tokensIdx].Type = lang.PORT
 					}
 				}
 			}
 		}
+	}
 
-		// do similar thing for functions
-		tokensIdx = 0
-		functionNodes := lang.GetFunctionNodes(ast)
+	// do similar thing for functions
+	tokensIdx = 0
+	functionNodes := lang.GetFunctionNodes(ast)
 
-		for _, functionNode := range functionNodes {
-			// get to the function name
-			for tokens[tokensIdx] != functionNode.Function && tokensIdx < len(tokens) {
-				tokensIdx++
-			}
+	for _, functionNode := range functionNodes {
+		// get to the function name
+		for tokensIdx < len(tokens) && tokens[tokensIdx] != functionNode.Function {
+			tokensIdx++
+		}
 
-			if tokensIdx < len(tokens) {
-				tokens[tokensIdx].Type = "funcliteral"
-			}
+		if tokensIdx < len(tokens) {
+			tokens[tokensIdx].Type = lang.FUNCLITERAL
 		}
-	}
 
-	// encode
-	result := &protocol.SemanticTokens{
-		Data: h.Encode(tokens),
+		// the strings passed to tasks like $display are formats
+		if lang.IsFormatTask(functionNode.Function.Name()) {
+			for _, expression := range functionNode.Expressions {
+				values := expression.Value.Values
+				if expression.Right != nil || len(values) != 1 || len(values[0].Value) != 1 || !values[0].Value[0].IsString() {
+					continue
+				}
+				for tokensIdx < len(tokens) && tokens[tokensIdx] != values[0].Value[0] {
+					tokensIdx++
+				}
+				if tokensIdx < len(tokens) {
+					tokens[tokensIdx].Type = lang.FORMAT_STRING
+				}
+			}
+		}
 	}
-	h.state.log.Sugar().Info("SemanticTokensFull result: ", result)
-
-	return result, nil
 }
@@ -0,0 +1,40 @@
+package vlsp
+
+import (
+	"testing"
+
+	"github.com/chrehall68/vls/internal/lang"
+	"go.uber.org/zap"
+)
+
+func TestLabelTokens(t *testing.T) {
+	code := "module top;\n  wire a, b;\n  sub s(.x(a), .y(b));\n  initial $display(\"%d\", a);\nendmodule\n"
+	tokens, err := lang.NewVLexer(zap.NewNop()).Lex(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ast, err := lang.NewParser().ParseFile(tokens)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	labeled := append([]lang.Token{}, tokens...)
+	labelTokens(labeled, ast)
+	expected := map[string]lang.TokenKind{
+		"sub":      lang.EXISTING_MODULE,
+		"x":        lang.PORT,
+		"y":        lang.PORT,
+		"$display": lang.FUNCLITERAL,
+		"\"%d\"":   lang.FORMAT_STRING,
+	}
+	for _, token := range labeled {
+		if kind, ok := expected[token.Value]; ok && token.Type != kind {
+			t.Errorf("%s is labeled %s, expected %s", token.Value, token.Type, kind)
+		}
+	}
+
+	// tokens that the AST has but that were cut off are left alone instead of being searched past the end
+	for end := 0; end < len(tokens); end++ {
+		labelTokens(append([]lang.Token{}, tokens[:end]...), ast)
+	}
+}
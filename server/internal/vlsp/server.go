@@ -12,10 +12,16 @@ import (
 type ServerState struct {
 	workspace           string
 	modules             map[string][]lang.ModuleNode              // list of all modules, grouped by file (w/o the file://)
+	interfaces          map[string][]lang.InterfaceNode           // list of all interfaces, grouped by file (w/o the file://)
+	packages            map[string][]lang.PackageNode             // list of all packages, grouped by file (w/o the file://)
 	defines             map[string][]lang.DefineNode              // list of all defines, grouped by file (w/o the file://)
 	symbolMap           map[string]protocol.Location              // map of symbol names to their location (path w/ the file://)
 	files               map[string]*File                          // map of file names (w/o the file://) to corresponding File objects
-	variableDefinitions map[string](map[string]protocol.Location) // map of module name : (variable name: declaration)
+	parses              map[string]parsedFile                     // map of file names (w/o the file://) to their last successful parse, which edits are reparsed from
+	variableDefinitions map[string](map[string]protocol.Location) // map of module or interface name : (variable name: declaration)
+	instanceTypes       map[string](map[string]string)            // map of module name : (instance, interface port, or struct variable name : module, interface, or type name)
+	imports             map[string][]string                       // map of module or package name : names of the packages it imports
+	settings            Settings                                  // what the client configured
 	log                 *zap.Logger
 	stream              *jsonrpc2.Stream
 	client              protocol.Client
@@ -41,26 +47,40 @@ func NewHandler(ctx context.Context, server protocol.Server, client protocol.Cli
 		state: &ServerState{
 			workspace:           "",
 			modules:             map[string][]lang.ModuleNode{},
+			interfaces:          map[string][]lang.InterfaceNode{},
+			packages:            map[string][]lang.PackageNode{},
 			defines:             map[string][]lang.DefineNode{},
 			variableDefinitions: map[string](map[string]protocol.Location){},
+			instanceTypes:       map[string](map[string]string){},
+			imports:             map[string][]string{},
+			settings:            defaultSettings(),
 			log:                 logger,
 			stream:              stream,
 			client:              client,
-			files:               map[string]*File{}}}, ctx, nil
+			files:               map[string]*File{},
+			parses:              map[string]parsedFile{}}}, ctx, nil
 }
 
 func (h Handler) Initialize(ctx context.Context, params *protocol.InitializeParams) (*protocol.InitializeResult, error) {
 	h.state.log.Sugar().Infof("Initialize called")
+	if err := decodeSettings(params.InitializationOptions, &h.state.settings); err != nil {
+		h.state.log.Sugar().Errorf("error reading initialization options: %s", err)
+	}
 
-	workspace := params.WorkspaceFolders[0].URI
+	// clients that don't support workspace folders only send the root
+	workspace := string(params.RootURI)
+	if len(params.WorkspaceFolders) > 0 {
+		workspace = params.WorkspaceFolders[0].URI
+	}
 	h.state.log.Sugar().Infof("workspace: %v", workspace)
 	if workspace != "" {
 		h.state.log.Sugar().Info("setting up workspace since it wasn't empty")
 		h.state.workspace = URIToPath(workspace)
 		go func() {
+			defer recoverPanic(h.state.log, "parsing workspace")
 			h.GetSymbols()
 			h.state.log.Sugar().Info("finished parsing workspace, have symbols:")
-			h.state.log.Sugar().Info(h.state.modules, h.state.defines)
+			h.state.log.Sugar().Info(h.state.modules, h.state.interfaces, h.state.packages, h.state.defines)
 		}()
 		h.state.log.Sugar().Info("finished parsing workspace")
 	}
@@ -68,6 +88,7 @@ func (h Handler) Initialize(ctx context.Context, params *protocol.InitializePara
 	return &protocol.InitializeResult{
 		Capabilities: protocol.ServerCapabilities{
 			CompletionProvider:     &protocol.CompletionOptions{},
+			HoverProvider:          true,
 			DefinitionProvider:     true,
 			DeclarationProvider:    true,
 			ImplementationProvider: true,
@@ -0,0 +1,46 @@
+package vlsp
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/chrehall68/vls/internal/lang"
+	"go.lsp.dev/protocol"
+)
+
+// Settings are what the client can configure, which it sends as the
+// initialization options, and under vls whenever the configuration changes
+type Settings struct {
+	Warnings lang.Warnings `json:"warnings"`
+}
+
+func defaultSettings() Settings {
+	return Settings{Warnings: lang.DefaultWarnings}
+}
+
+// decodeSettings sets whatever settings the client sent, keeping the rest
+func decodeSettings(sent interface{}, settings interface{}) error {
+	encoded, err := json.Marshal(sent)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(encoded, settings)
+}
+
+func (h Handler) DidChangeConfiguration(ctx context.Context, params *protocol.DidChangeConfigurationParams) (err error) {
+	h.state.log.Sugar().Infof("DidChangeConfiguration called")
+	settings := struct {
+		VLS *Settings `json:"vls"`
+	}{VLS: &h.state.settings}
+	if err := decodeSettings(params.Settings, &settings); err != nil {
+		h.state.log.Sugar().Errorf("error reading settings: %s", err)
+	}
+
+	// the diagnostics depend on the settings
+	files := []string{}
+	for fname := range h.state.files {
+		files = append(files, fname)
+	}
+	h.publishDiagnostics(files)
+	return nil
+}
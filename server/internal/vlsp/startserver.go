@@ -2,7 +2,9 @@ package vlsp
 
 import (
 	"context"
+	"fmt"
 	"io"
+	"runtime/debug"
 
 	"go.lsp.dev/jsonrpc2"
 	"go.lsp.dev/protocol"
@@ -32,12 +34,40 @@ func StartServer(logger *zap.Logger, reader io.ReadCloser, writer io.WriteCloser
 	}
 	logger.Sugar().Infof("initialized handler")
 
-	conn.Go(ctx, protocol.CancelHandler(protocol.ServerHandler(
+	conn.Go(ctx, protocol.CancelHandler(recoverHandler(logger, protocol.ServerHandler(
 		handler, jsonrpc2.MethodNotFoundHandler),
-	))
+	)))
 	<-conn.Done()
 }
 
+// recoverHandler replies with an error when handling a request panics,
+// so one file that trips up the server can't take the whole server down
+func recoverHandler(logger *zap.Logger, handler jsonrpc2.Handler) jsonrpc2.Handler {
+	return func(ctx context.Context, reply jsonrpc2.Replier, req jsonrpc2.Request) (err error) {
+		replied := false
+		defer func() {
+			if r := recover(); r != nil {
+				logger.Sugar().Errorf("panic while handling %s: %v\n%s", req.Method(), r, debug.Stack())
+				if !replied {
+					err = reply(ctx, nil, fmt.Errorf("%s: internal error: %v", req.Method(), r))
+				}
+			}
+		}()
+		return handler(ctx, func(ctx context.Context, result interface{}, err error) error {
+			replied = true
+			return reply(ctx, result, err)
+		}, req)
+	}
+}
+
+// recoverPanic logs a panic in work done outside of a request, ie in a goroutine.
+// It has to be deferred
+func recoverPanic(logger *zap.Logger, doing string) {
+	if r := recover(); r != nil {
+		logger.Sugar().Errorf("panic while %s: %v\n%s", doing, r, debug.Stack())
+	}
+}
+
 type readWriteCloser struct {
 	reader io.ReadCloser
 	writer io.WriteCloser
@@ -3,7 +3,6 @@ package vlsp
 import (
 	"context"
 	"os"
-	"strings"
 
 	"github.com/chrehall68/vls/internal/lang"
 	"go.lsp.dev/protocol"
@@ -25,19 +24,133 @@ func (h *Handler) getFileFullPaths(dir string) []string {
 	return paths
 }
 
+// tokenLocation returns the location of a token inside the given file
+func tokenLocation(fname string, tok lang.Token) protocol.Location {
+	return protocol.Location{
+		URI: protocol.DocumentURI(PathToURI(fname)),
+		Range: protocol.Range{
+			Start: protocol.Position{Line: uint32(tok.Line()), Character: uint32(tok.StartCharacter())},
+			End:   protocol.Position{Line: uint32(tok.Line()), Character: uint32(tok.EndCharacter())}},
+	}
+}
+
+// storeDefinitions stores the ports, variables, instances, and types declared
+// directly inside a module, interface, or package, which are the symbols of its scope
+func (h Handler) storeDefinitions(fname string, name string, scope *lang.Scope, imports []lang.ImportNode) {
+	// clear the existing definitions
+	h.state.variableDefinitions[name] = map[string]protocol.Location{}
+	h.state.instanceTypes[name] = map[string]string{}
+	h.state.imports[name] = []string{}
+	for _, importNode := range imports {
+		for _, item := range importNode.Items {
+			h.state.imports[name] = append(h.state.imports[name], item.Package.Name())
+		}
+	}
+	for _, item := range scope.Imports {
+		h.state.imports[name] = append(h.state.imports[name], item.Package.Name())
+	}
+
+	for _, symbol := range scope.Symbols {
+		h.state.variableDefinitions[name][symbol.Name()] = tokenLocation(fname, symbol.Identifier)
+		if symbol.Typedef != nil {
+			h.storeTypedef(fname, *symbol.Typedef)
+		} else if typeName := symbol.TypeName(); typeName != "" && symbol.Kind != lang.EnumMemberSymbol {
+			// instances, interface ports, and variables of user-defined types can have members
+			h.state.instanceTypes[name][symbol.Name()] = typeName
+		}
+	}
+}
+
+// storeTypedef stores the members of a struct typedef as definitions of the type itself
+func (h Handler) storeTypedef(fname string, typedef lang.TypedefNode) {
+	if typedef.Struct != nil {
+		h.state.variableDefinitions[typedef.Identifier.Name()] = map[string]protocol.Location{}
+		for _, member := range typedef.Struct.Members {
+			for _, v := range member.Variables {
+				h.state.variableDefinitions[typedef.Identifier.Name()][v.Identifier.Name()] = tokenLocation(fname, v.Identifier)
+			}
+		}
+	}
+}
+
+// newInterpreter returns an interpreter for the file that knows about everything in the workspace
+func (h Handler) newInterpreter(fname string) *lang.Interpreter {
+	interpreter := lang.NewInterpreter(h.state.log, h.state.modules, h.state.interfaces, h.state.packages, h.state.defines)
+	interpreter.File = fname
+	interpreter.FileURI = func(fname string) protocol.DocumentURI {
+		return protocol.DocumentURI(PathToURI(fname))
+	}
+	interpreter.Warnings = h.state.settings.Warnings
+	return interpreter
+}
+
+// findPackage returns the package with the given name, if there is one
+func (h Handler) findPackage(name string) (lang.PackageNode, bool) {
+	for _, pkgs := range h.state.packages {
+		for _, pkg := range pkgs {
+			if pkg.Identifier.Name() == name {
+				return pkg, true
+			}
+		}
+	}
+	return lang.PackageNode{}, false
+}
+
+// parsedFile is the code of a file that parsed, along with its tokens, ast, and symbol table
+type parsedFile struct {
+	code   string
+	tokens []lang.Token
+	ast    lang.FileNode
+	table  *lang.Scope
+}
+
+// identifiers returns the identifiers of the modules, interfaces, and packages
+// of a file by their nodes, along with the file level imports
+func identifiers(ast lang.FileNode) (map[interface{}]lang.Token, []*lang.ImportNode) {
+	result := map[interface{}]lang.Token{}
+	imports := []*lang.ImportNode{}
+	for _, statement := range ast.Statements {
+		if statement.Module != nil {
+			result[statement.Module] = statement.Module.Identifier
+		} else if statement.Interface != nil {
+			result[statement.Interface] = statement.Interface.Identifier
+		} else if statement.Package != nil {
+			result[statement.Package] = statement.Package.Identifier
+		} else if statement.Import != nil {
+			imports = append(imports, statement.Import)
+		}
+	}
+	return result, imports
+}
+
 func (h Handler) GetSymbolsForFile(fname string, firstTime bool) {
-	vlexer := lang.NewVLexer(h.state.log)
-	parser := lang.NewParser()
+	vlexer := NewLexerFor(fname, h.state.log)
+	parser := NewParserFor(fname)
+	contents := h.file(fname).GetContents()
 
-	// lex
-	tokens, err := vlexer.Lex(h.state.files[fname].GetContents())
-	if err != nil {
+	// lex and parse, only going over what changed since the last time the file parsed
+	var tokens []lang.Token
+	var results lang.FileNode
+	var err error
+	previousIdentifiers, previousImports := map[interface{}]lang.Token{}, []*lang.ImportNode{}
+	if previous, ok := h.state.parses[fname]; ok {
+		// the statements that are reused get moved, so keep track of where they were
+		previousIdentifiers, previousImports = identifiers(previous.ast)
+		tokens, results, err = lang.Reparse(vlexer, parser, contents, lang.Diff(previous.code, contents), previous.tokens, previous.ast)
+	} else {
+		tokens, err = vlexer.Lex(contents)
+		if err == nil {
+			results, err = parser.ParseFile(tokens)
+		}
+	}
+	if tokens == nil {
 		h.state.log.Sugar().Errorf("error lexing file %s: %s", fname, err)
 		return
 	}
 
-	// parse
-	results, err := parser.ParseFile(tokens)
+	// characters that couldn't be lexed are always errors
+	lexDiagnostics := lang.LexDiagnostics(tokens)
+
 	if err != nil {
 		h.state.log.Sugar().Errorf("error parsing file %s: %s", fname, err)
 		// let's not clear anything for now...
@@ -57,71 +170,117 @@ func (h Handler) GetSymbolsForFile(fname string, firstTime bool) {
 			}
 			obj := protocol.PublishDiagnosticsParams{
 				URI:         protocol.DocumentURI(PathToURI(fname)),
-				Diagnostics: []protocol.Diagnostic{diag},
+				Diagnostics: append(lexDiagnostics, diag),
 			}
 			h.state.client.PublishDiagnostics(context.Background(), &obj)
 		} else {
 			// somehow we got an error out of bounds
-			// might happen if someone puts an emoji in the file
 			obj := protocol.PublishDiagnosticsParams{
 				URI:         protocol.DocumentURI(PathToURI(fname)),
-				Diagnostics: []protocol.Diagnostic{},
+				Diagnostics: lexDiagnostics,
 			}
 			h.state.client.PublishDiagnostics(context.Background(), &obj)
 		}
 	} else {
+		table := lang.NewSymbolTable(results)
+		h.state.parses[fname] = parsedFile{code: contents, tokens: tokens, ast: results, table: table}
+		scopeOf := func(identifier lang.Token) *lang.Scope {
+			return table.ScopeAt(identifier.Line(), identifier.StartCharacter())
+		}
+
+		// statements that were reused and are where they were still have up to date definitions,
+		// unless the file level imports that they depend on changed
+		currentIdentifiers, currentImports := identifiers(results)
+		importsChanged := len(currentImports) != len(previousImports)
+		for i := 0; i < len(currentImports) && !importsChanged; i++ {
+			importsChanged = currentImports[i] != previousImports[i]
+		}
+		unchanged := func(node interface{}) bool {
+			previous, ok := previousIdentifiers[node]
+			current := currentIdentifiers[node]
+			return ok && !importsChanged && previous.Line() == current.Line() && previous.StartCharacter() == current.StartCharacter()
+		}
+
 		// reset maps for this file
 		h.state.defines[fname] = []lang.DefineNode{}
 		h.state.modules[fname] = []lang.ModuleNode{}
+		h.state.interfaces[fname] = []lang.InterfaceNode{}
+		h.state.packages[fname] = []lang.PackageNode{}
 
 		// store all modules that way we can easily go to definition
+		fileImports := []lang.ImportNode{}
 		for _, statement := range results.Statements {
 			if statement.Module != nil {
 				h.state.modules[fname] = append(h.state.modules[fname], *statement.Module)
+				if !unchanged(statement.Module) {
+					h.storeDefinitions(fname, statement.Module.Identifier.Name(), scopeOf(statement.Module.Identifier), fileImports)
+				}
+			} else if statement.Interface != nil {
+				iface := *statement.Interface
+				h.state.interfaces[fname] = append(h.state.interfaces[fname], iface)
+				if !unchanged(statement.Interface) {
+					h.storeDefinitions(fname, iface.Identifier.Name(), scopeOf(iface.Identifier), fileImports)
+				}
 
-				// clear the existing variable definitions
-				moduleName := statement.Module.Identifier.Value
-				h.state.variableDefinitions[moduleName] = map[string]protocol.Location{}
-				// and also store all variable definitions inside the module
-				for _, statement := range lang.GetInteriorStatementsFromModule(*statement.Module) {
-					if statement.DeclarationNode != nil {
-						for _, v := range statement.DeclarationNode.Variables {
-							h.state.variableDefinitions[moduleName][v.Identifier.Value] = protocol.Location{
-								URI: protocol.DocumentURI(PathToURI(fname)),
-								Range: protocol.Range{
-									Start: protocol.Position{Line: uint32(v.Identifier.Line()), Character: uint32(v.Identifier.StartCharacter())},
-									End:   protocol.Position{Line: uint32(v.Identifier.Line()), Character: uint32(v.Identifier.EndCharacter())}},
-							}
-						}
+				// modports are stored as interface.modport
+				for _, interiorStatement := range iface.Interior {
+					if interiorStatement.ModportNode != nil {
+						modport := interiorStatement.ModportNode.Identifier
+						h.state.symbolMap[iface.Identifier.Name()+"."+modport.Name()] = tokenLocation(fname, modport)
 					}
 				}
+			} else if statement.Package != nil {
+				pkg := *statement.Package
+				h.state.packages[fname] = append(h.state.packages[fname], pkg)
+				if !unchanged(statement.Package) {
+					h.storeDefinitions(fname, pkg.Identifier.Name(), scopeOf(pkg.Identifier), fileImports)
+				}
+			} else if statement.Import != nil {
+				// file-level imports apply to everything after them
+				fileImports = append(fileImports, *statement.Import)
 			} else if statement.Directive != nil && statement.Directive.DefineNode != nil {
-				h.state.defines[fname] = append(h.state.defines[fname], *statement.Directive.DefineNode)
+				define := *statement.Directive.DefineNode
+				h.state.defines[fname] = append(h.state.defines[fname], define)
+
+				// explicitly add the backticks for defines
+				h.state.symbolMap["`"+define.Identifier.Name()] = tokenLocation(fname, define.Identifier)
+			} else if statement.Directive != nil && statement.Directive.UndefNode != nil {
+				// undefined macros are no longer visible to the rest of the workspace
+				name := statement.Directive.UndefNode.Identifier.Name()
+				remaining := []lang.DefineNode{}
+				for _, define := range h.state.defines[fname] {
+					if define.Identifier.Name() != name {
+						remaining = append(remaining, define)
+					}
+				}
+				h.state.defines[fname] = remaining
+
+				// and going to its definition no longer jumps here, but to another file that defines it if one does
+				delete(h.state.symbolMap, "`"+name)
+				for otherFile, defines := range h.state.defines {
+					for _, define := range defines {
+						if define.Identifier.Name() == name {
+							h.state.symbolMap["`"+name] = tokenLocation(otherFile, define.Identifier)
+						}
+					}
+				}
 			}
 		}
-		// store all known global symbols (modules and defines)
+		// store all known global symbols (modules, interfaces, and packages)
 		for _, module := range h.state.modules[fname] {
-			h.state.symbolMap[module.Identifier.Value] = protocol.Location{
-				URI: protocol.DocumentURI(PathToURI(fname)),
-				Range: protocol.Range{
-					Start: protocol.Position{Line: uint32(module.Identifier.Line()), Character: uint32(module.Identifier.StartCharacter())},
-					End:   protocol.Position{Line: uint32(module.Identifier.Line()), Character: uint32(module.Identifier.EndCharacter())}},
-			}
+			h.state.symbolMap[module.Identifier.Name()] = tokenLocation(fname, module.Identifier)
 		}
-		for _, define := range h.state.defines[fname] {
-			// explicitly add the backticks for defines
-			h.state.symbolMap["`"+define.Identifier.Value] = protocol.Location{
-				URI: protocol.DocumentURI(PathToURI(fname)),
-				Range: protocol.Range{
-					Start: protocol.Position{Line: uint32(define.Identifier.Line()), Character: uint32(define.Identifier.StartCharacter())},
-					End:   protocol.Position{Line: uint32(define.Identifier.Line()), Character: uint32(define.Identifier.EndCharacter())}},
-			}
+		for _, iface := range h.state.interfaces[fname] {
+			h.state.symbolMap[iface.Identifier.Name()] = tokenLocation(fname, iface.Identifier)
+		}
+		for _, pkg := range h.state.packages[fname] {
+			h.state.symbolMap[pkg.Identifier.Name()] = tokenLocation(fname, pkg.Identifier)
 		}
 
 		// get diagnostics
 		if !firstTime {
-			interpreter := lang.NewInterpreter(h.state.log, h.state.modules, h.state.defines)
-			diagnostics := interpreter.Interpret(results)
+			interpreter := h.newInterpreter(fname)
+			diagnostics := append(lexDiagnostics, interpreter.InterpretWithTable(results, table)...)
 			obj := protocol.PublishDiagnosticsParams{
 				URI:         protocol.DocumentURI(PathToURI(fname)),
 				Diagnostics: diagnostics,
@@ -135,13 +294,16 @@ func (h Handler) GetSymbols() {
 	// first, reset state
 	h.state.defines = map[string][]lang.DefineNode{}
 	h.state.modules = map[string][]lang.ModuleNode{}
+	h.state.interfaces = map[string][]lang.InterfaceNode{}
+	h.state.packages = map[string][]lang.PackageNode{}
 	h.state.symbolMap = map[string]protocol.Location{}
+	h.state.parses = map[string]parsedFile{}
 
 	// then, get the files to parse
 	files := h.getFileFullPaths(h.state.workspace)
 
 	for _, file := range files {
-		if strings.HasSuffix(file, ".v") {
+		if IsHDLFile(file) {
 			// create the file object
 			h.state.files[file] = NewFile(file)
 
@@ -157,21 +319,26 @@ func (h Handler) GetSymbols() {
 		}
 	}
 
-	// then publish actual diagnostics
-	vlexer := lang.NewVLexer(h.state.log)
-	parser := lang.NewParser()
+	// then publish actual diagnostics, reusing the parses from before
+	h.publishDiagnostics(files)
+}
+
+// publishDiagnostics publishes the diagnostics of the files, reusing their last parses
+func (h Handler) publishDiagnostics(files []string) {
 	for _, file := range files {
-		if strings.HasSuffix(file, ".v") {
-			tokens, err := vlexer.Lex(h.state.files[file].GetContents())
-			if err != nil {
-				continue
-			}
-			results, err := parser.ParseFile(tokens)
-			if err != nil {
-				continue
+		if IsHDLFile(file) {
+			var diagnostics []protocol.Diagnostic
+			if parse, ok := h.state.parses[file]; ok {
+				diagnostics = lang.LexDiagnostics(parse.tokens)
+				interpreter := h.newInterpreter(file)
+				diagnostics = append(diagnostics, interpreter.InterpretWithTable(parse.ast, parse.table)...)
+			} else {
+				tokens, err := NewLexerFor(file, h.state.log).Lex(h.file(file).GetContents())
+				if err != nil {
+					continue
+				}
+				diagnostics = lang.LexDiagnostics(tokens)
 			}
-			interpreter := lang.NewInterpreter(h.state.log, h.state.modules, h.state.defines)
-			diagnostics := interpreter.Interpret(results)
 			obj := protocol.PublishDiagnosticsParams{
 				URI:         protocol.DocumentURI(PathToURI(file)),
 				Diagnostics: diagnostics,
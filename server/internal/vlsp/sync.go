@@ -2,7 +2,6 @@ package vlsp
 
 import (
 	"context"
-	"strings"
 
 	"go.lsp.dev/protocol"
 )
@@ -11,14 +10,12 @@ func (h Handler) DidChange(ctx context.Context, params *protocol.DidChangeTextDo
 	file := URIToPath(string(params.TextDocument.URI))
 	h.state.log.Sugar().Info("File that did change: ", file)
 
-	if strings.HasSuffix(file, ".v") {
+	if IsHDLFile(file) {
 		// update file
-		fnode, ok := h.state.files[file]
-		if !ok {
-			h.state.files[file] = NewFile(file)
-			fnode = h.state.files[file]
+		if len(params.ContentChanges) == 0 {
+			return
 		}
-		fnode.SetContents(params.ContentChanges[len(params.ContentChanges)-1].Text)
+		h.file(file).SetContents(params.ContentChanges[len(params.ContentChanges)-1].Text)
 
 		// update symbols
 		h.GetSymbolsForFile(file, false)
@@ -29,14 +26,9 @@ func (h Handler) DidOpen(ctx context.Context, params *protocol.DidOpenTextDocume
 	file := URIToPath(string(params.TextDocument.URI))
 	h.state.log.Sugar().Info("File that did open: ", file)
 
-	if strings.HasSuffix(file, ".v") {
+	if IsHDLFile(file) {
 		// update file
-		fnode, ok := h.state.files[file]
-		if !ok {
-			h.state.files[file] = NewFile(file)
-			fnode = h.state.files[file]
-		}
-		fnode.SetContents(params.TextDocument.Text)
+		h.file(file).SetContents(params.TextDocument.Text)
 
 		// update symbols
 		h.GetSymbolsForFile(file, false)
@@ -53,9 +45,9 @@ func (h Handler) DidSave(ctx context.Context, params *protocol.DidSaveTextDocume
 	file := URIToPath(string(params.TextDocument.URI))
 	h.state.log.Sugar().Info("File that did save: ", file)
 
-	if strings.HasSuffix(file, ".v") {
+	if IsHDLFile(file) {
 		// update file
-		h.state.files[file].Save()
+		h.file(file).Save()
 
 		// update symbols
 		h.GetSymbolsForFile(file, false)